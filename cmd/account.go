@@ -0,0 +1,374 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// accountCmd represents the account command
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Manage bank accounts and cards",
+	Long: `Manage the bank accounts and cards transactions are booked on.
+
+Imports are bound to an account with --account, or automatically to the
+account whose number the statement states, so that households with several
+accounts and credit cards at the same bank can tell their transactions apart.
+Accounts are referred to by ID or name.`,
+}
+
+// accountListCmd represents the account list subcommand
+var accountListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List accounts with their balances",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		accounts, err := store.ListAccounts(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		switch format {
+		case outputFormatJSON:
+			return printJSON(accounts)
+		case outputFormatTable:
+		default:
+			return fmt.Errorf("unsupported format: %s", format)
+		}
+
+		if len(accounts) == 0 {
+			fmt.Println("No accounts. Add one with: budgetassist account add <name>")
+			return nil
+		}
+		table := newTable()
+		table.SetHeader([]string{"ID", "Name", "Bank", "Number", "Type", "Transactions", "Balance"})
+		for _, account := range accounts {
+			summary, err := summarizeAccount(cmd.Context(), store, &account)
+			if err != nil {
+				return err
+			}
+			table.Append([]string{
+				fmt.Sprintf("%d", account.ID),
+				account.Name,
+				account.Bank,
+				account.Number,
+				account.Type,
+				fmt.Sprintf("%d", summary.transactions),
+				fmt.Sprintf("%s %s", summary.balance.StringFixed(2), account.Currency),
+			})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+// accountAddCmd represents the account add subcommand
+var accountAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add an account",
+	Example: `  budgetassist account add "SEB Lönekonto" --bank seb --number "5490 99 000 01"
+  budgetassist account add "Eurocard" --type credit_card --number "5355 **** **** 1234"
+  budgetassist account add "Sparkonto" --type savings --opening-balance 15000 --opening-date 2026-01-01`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		account := &db.Account{
+			Name:     strings.TrimSpace(args[0]),
+			Type:     db.AccountTypeChecking,
+			Currency: viper.GetString("import.default_currency"),
+		}
+		if account.Name == "" {
+			return fmt.Errorf("account name cannot be empty")
+		}
+		if account.Currency == "" {
+			account.Currency = db.CurrencySEK
+		}
+		if err := applyAccountFlags(cmd, account); err != nil {
+			return err
+		}
+
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		if _, err := store.GetAccountByName(cmd.Context(), account.Name); err == nil {
+			return fmt.Errorf("account %q already exists", account.Name)
+		} else if !errors.Is(err, db.ErrNotFound) {
+			return err
+		}
+		if err := store.CreateAccount(cmd.Context(), account); err != nil {
+			return err
+		}
+		fmt.Printf("Added account %q (ID: %d)\n", account.Name, account.ID)
+		return nil
+	},
+}
+
+// accountShowCmd represents the account show subcommand
+var accountShowCmd = &cobra.Command{
+	Use:   "show <account>",
+	Short: "Show an account and its balance",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		account, err := resolveAccount(cmd.Context(), store, args[0])
+		if err != nil {
+			return err
+		}
+		summary, err := summarizeAccount(cmd.Context(), store, account)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Account %d: %s\n", account.ID, account.Name)
+		fmt.Printf("Bank:            %s\n", valueOrDash(account.Bank))
+		fmt.Printf("Number:          %s\n", valueOrDash(account.Number))
+		fmt.Printf("Type:            %s\n", account.Type)
+		fmt.Printf("Currency:        %s\n", account.Currency)
+		opening := account.OpeningBalance.StringFixed(2)
+		if !account.OpeningDate.IsZero() {
+			opening += " on " + account.OpeningDate.Format("2006-01-02")
+		}
+		fmt.Printf("Opening balance: %s\n", opening)
+		fmt.Printf("Transactions:    %d\n", summary.transactions)
+		if summary.transactions > 0 {
+			fmt.Printf("Period:          %s to %s\n", summary.first.Format("2006-01-02"), summary.last.Format("2006-01-02"))
+		}
+		fmt.Printf("Balance:         %s %s\n", summary.balance.StringFixed(2), account.Currency)
+		return nil
+	},
+}
+
+// accountUpdateCmd represents the account update subcommand
+var accountUpdateCmd = &cobra.Command{
+	Use:   "update <account>",
+	Short: "Update an account",
+	Long:  `Update the settings of an account. Only the given flags are changed.`,
+	Example: `  budgetassist account update "SEB Lönekonto" --name "SEB Hushåll"
+  budgetassist account update 2 --opening-balance 1200.50 --opening-date 2026-01-01`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		account, err := resolveAccount(cmd.Context(), store, args[0])
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("name") {
+			name, _ := cmd.Flags().GetString("name")
+			name = strings.TrimSpace(name)
+			if name == "" {
+				return fmt.Errorf("account name cannot be empty")
+			}
+			if existing, err := store.GetAccountByName(cmd.Context(), name); err == nil && existing.ID != account.ID {
+				return fmt.Errorf("account %q already exists", name)
+			}
+			account.Name = name
+		}
+		if err := applyAccountFlags(cmd, account); err != nil {
+			return err
+		}
+
+		if err := store.UpdateAccount(cmd.Context(), account); err != nil {
+			return err
+		}
+		fmt.Printf("Updated account %q (ID: %d)\n", account.Name, account.ID)
+		return nil
+	},
+}
+
+// accountDeleteCmd represents the account delete subcommand
+var accountDeleteCmd = &cobra.Command{
+	Use:   "delete <account>",
+	Short: "Delete an account",
+	Long:  `Delete an account. Its transactions are kept, without an account.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		account, err := resolveAccount(cmd.Context(), store, args[0])
+		if err != nil {
+			return err
+		}
+
+		// Ask for confirmation unless --force is used
+		force, _ := cmd.Flags().GetBool("force")
+		if !force {
+			fmt.Printf("Are you sure you want to delete account %q (ID: %d)? [y/N] ", account.Name, account.ID)
+			var response string
+			_, err := fmt.Scanln(&response)
+			if err != nil {
+				return fmt.Errorf("failed to read response: %w", err)
+			}
+			if response != "y" && response != "Y" {
+				fmt.Println("Operation cancelled")
+				return nil
+			}
+		}
+
+		detached, err := store.DeleteAccount(cmd.Context(), account.ID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Deleted account %q (ID: %d), %d transactions no longer have an account\n", account.Name, account.ID, detached)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(accountCmd)
+	accountCmd.AddCommand(accountListCmd)
+	accountCmd.AddCommand(accountAddCmd)
+	accountCmd.AddCommand(accountShowCmd)
+	accountCmd.AddCommand(accountUpdateCmd)
+	accountCmd.AddCommand(accountDeleteCmd)
+
+	accountListCmd.Flags().String("format", outputFormatTable, "Output format (table, json)")
+	for _, cmd := range []*cobra.Command{accountAddCmd, accountUpdateCmd} {
+		cmd.Flags().String("bank", "", "Bank parser id, e.g. seb")
+		cmd.Flags().String("number", "", "Account number, IBAN or card number as the bank states it")
+		cmd.Flags().String("type", db.AccountTypeChecking, "Account type ("+strings.Join(db.AccountTypes, ", ")+")")
+		cmd.Flags().String("currency", "", "Currency code (default from config)")
+		cmd.Flags().String("opening-balance", "0", "Balance before the transactions on or after --opening-date")
+		cmd.Flags().String("opening-date", "", "Date the opening balance applies to (YYYY-MM-DD)")
+	}
+	accountUpdateCmd.Flags().String("name", "", "New account name")
+	accountDeleteCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+}
+
+// applyAccountFlags copies the account flags that were set onto the account
+func applyAccountFlags(cmd *cobra.Command, account *db.Account) error {
+	flags := cmd.Flags()
+	if flags.Changed("bank") {
+		account.Bank, _ = flags.GetString("bank")
+	}
+	if flags.Changed("number") {
+		account.Number, _ = flags.GetString("number")
+	}
+	if flags.Changed("type") {
+		accountType, _ := flags.GetString("type")
+		account.Type = strings.ToLower(accountType)
+	}
+	if flags.Changed("currency") {
+		currency, _ := flags.GetString("currency")
+		account.Currency = strings.ToUpper(currency)
+	}
+	if flags.Changed("opening-balance") {
+		value, _ := flags.GetString("opening-balance")
+		balance, err := decimal.NewFromString(value)
+		if err != nil {
+			return fmt.Errorf("invalid --opening-balance %q: %w", value, err)
+		}
+		account.OpeningBalance = balance
+	}
+	if flags.Changed("opening-date") {
+		value, _ := flags.GetString("opening-date")
+		account.OpeningDate = time.Time{}
+		if value != "" {
+			date, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				return fmt.Errorf("invalid --opening-date %q: expected YYYY-MM-DD", value)
+			}
+			account.OpeningDate = date
+		}
+	}
+
+	valid := false
+	for _, accountType := range db.AccountTypes {
+		valid = valid || account.Type == accountType
+	}
+	if !valid {
+		return fmt.Errorf("invalid account type %q: must be one of %s", account.Type, strings.Join(db.AccountTypes, ", "))
+	}
+	return nil
+}
+
+// resolveAccount finds an account by ID or name
+func resolveAccount(ctx context.Context, store db.Store, ref string) (*db.Account, error) {
+	if id, err := parseID(ref); err == nil {
+		account, err := store.GetAccountByID(ctx, id)
+		if err == nil {
+			return account, nil
+		}
+		if !errors.Is(err, db.ErrNotFound) {
+			return nil, err
+		}
+	}
+	account, err := store.GetAccountByName(ctx, ref)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, fmt.Errorf("account %q not found, see 'budgetassist account list'", ref)
+	}
+	return account, err
+}
+
+// accountSummary holds the transaction count, period and balance of an account
+type accountSummary struct {
+	transactions int
+	first, last  time.Time
+	balance      decimal.Decimal
+}
+
+// summarizeAccount adds up the transactions of an account since its opening date
+func summarizeAccount(ctx context.Context, store db.Store, account *db.Account) (accountSummary, error) {
+	transactions, err := store.ListTransactions(ctx, &db.TransactionFilter{AccountIDs: []uint{account.ID}})
+	if err != nil {
+		return accountSummary{}, err
+	}
+
+	summary := accountSummary{transactions: len(transactions), balance: account.OpeningBalance}
+	for _, tx := range transactions {
+		date := tx.Date
+		if date.IsZero() {
+			date = tx.TransactionDate
+		}
+		if summary.first.IsZero() || date.Before(summary.first) {
+			summary.first = date
+		}
+		if date.After(summary.last) {
+			summary.last = date
+		}
+		// Duplicates repeat transactions counted already and the opening balance includes earlier ones
+		if tx.DuplicateOfID != nil || (!account.OpeningDate.IsZero() && date.Before(account.OpeningDate)) {
+			continue
+		}
+		summary.balance = summary.balance.Add(tx.Amount)
+	}
+	return summary, nil
+}
+
+// valueOrDash returns the value, or a dash when it is empty
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
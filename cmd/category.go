@@ -18,6 +18,7 @@ import (
 const (
 	outputFormatJSON  = "json"
 	outputFormatTable = "table"
+	outputFormatCSV   = "csv"
 )
 
 // Status symbols
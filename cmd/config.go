@@ -104,10 +104,15 @@ var configInitCmd = &cobra.Command{
 		viper.SetDefault("database.import_default_categories", true)
 		viper.SetDefault("database.import_default_prompts", true)
 		viper.SetDefault("import.default_currency", "SEK")
+		viper.SetDefault("currency.base", "SEK")
 		viper.SetDefault("export.format", "csv")
 		viper.SetDefault("ai.enabled", true)
 		viper.SetDefault("ai.timeout", "10s")
 		viper.SetDefault("ai.model", "gpt-4-turbo")
+		viper.SetDefault("imap.folder", "INBOX")
+		viper.SetDefault("imap.tls", true)
+		viper.SetDefault("imap.processed_flag", "$BudgetAssistProcessed")
+		viper.SetDefault("imap.archive_dir", filepath.Join(userHomeDir, ".budgetassist", "mail"))
 		viper.SetDefault("logging.level", "info")
 		viper.SetDefault("logging.directory", filepath.Join(userHomeDir, ".budgetassist", "logs"))
 		viper.SetDefault("logging.file", fmt.Sprintf("budgetassist-%s.log", time.Now().Format("2006-01-02")))
@@ -325,6 +330,14 @@ and how they can be set using the 'config set' command.`,
 				Type:         "string",
 				Example:      "SEK, USD, EUR",
 			},
+			{
+				Key:          "currency.base",
+				Description:  "Currency transactions are converted to for reports",
+				DefaultValue: "SEK",
+				CurrentValue: viper.GetString("currency.base"),
+				Type:         "string",
+				Example:      "SEK, EUR, USD",
+			},
 			{
 				Key:          "export.format",
 				Description:  "Default export format",
@@ -381,6 +394,62 @@ and how they can be set using the 'config set' command.`,
 				Type:         "integer",
 				Example:      "3, 5, 10",
 			},
+			{
+				Key:          "imap.address",
+				Description:  "IMAP server to ingest attachments from, as host:port",
+				DefaultValue: "",
+				CurrentValue: viper.GetString("imap.address"),
+				Type:         "string",
+				Example:      "imap.gmail.com:993",
+			},
+			{
+				Key:          "imap.username",
+				Description:  "User name for the IMAP server",
+				DefaultValue: "",
+				CurrentValue: viper.GetString("imap.username"),
+				Type:         "string",
+				Example:      "anna@example.com",
+			},
+			{
+				Key:          "imap.password",
+				Description:  "Password for the IMAP server (can also be set via IMAP_PASSWORD env var)",
+				DefaultValue: "",
+				CurrentValue: maskSensitiveValue(viper.GetString("imap.password")),
+				Type:         "string",
+				Example:      "app-password",
+			},
+			{
+				Key:          "imap.folder",
+				Description:  "IMAP folder to read messages from",
+				DefaultValue: "INBOX",
+				CurrentValue: viper.GetString("imap.folder"),
+				Type:         "string",
+				Example:      "Fakturor",
+			},
+			{
+				Key:          "imap.tls",
+				Description:  "Connect to the IMAP server with TLS, otherwise STARTTLS is used when offered",
+				DefaultValue: "true",
+				CurrentValue: viper.GetBool("imap.tls"),
+				Type:         "boolean",
+				Example:      "true or false",
+			},
+			{
+				Key:          "imap.processed_flag",
+				Description:  "Keyword processed messages are marked with",
+				DefaultValue: "$BudgetAssistProcessed",
+				CurrentValue: viper.GetString("imap.processed_flag"),
+				Type:         "string",
+				Example:      "$BudgetAssistProcessed",
+			},
+			{
+				Key:          "imap.archive_dir",
+				Description:  "Directory messages with attachments are saved to",
+				DefaultValue: filepath.Join(userHomeDir, ".budgetassist", "mail"),
+				CurrentValue: viper.GetString("imap.archive_dir"),
+				Type:         "string",
+				Example:      "~/.budgetassist/mail",
+			},
 			{
 				Key:          "logging.level",
 				Description:  "Logging level",
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lindehoff/Budget-Assist/internal/core"
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/lindehoff/Budget-Assist/internal/exchange"
+	"github.com/spf13/cobra"
+)
+
+// exchangeCmd represents the exchange command
+var exchangeCmd = &cobra.Command{
+	Use:   "exchange",
+	Short: "Manage exchange rates",
+	Long: `Manage the exchange rates transactions are converted to the base currency with.
+
+Each transaction keeps the currency it was booked in and also stores its
+amount in the base currency (config currency.base), converted with the rate of
+its booking day. When no rate was published that day, the last rate of the
+week before is used.`,
+}
+
+// exchangeImportCmd represents the exchange import subcommand
+var exchangeImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import exchange rates from an ECB or Riksbank CSV file",
+	Long: `Import the exchange rates of a CSV file published by the European Central
+Bank or the Riksbank. Rates already stored for a day are replaced. Stored
+transactions that had no rate are converted afterwards.
+
+ECB files are the euro reference rates, e.g. eurofxref-hist.csv, with a
+column per currency. Riksbank files are the semicolon separated exports of
+the rates against the Swedish krona, with a column per series or a row per
+day and series.`,
+	Example: `  budgetassist exchange import eurofxref-hist.csv
+  budgetassist exchange import valutakurser.csv --source riksbank`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, _ := cmd.Flags().GetString("source")
+		base, err := baseCurrency()
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open exchange rates: %w", err)
+		}
+		defer file.Close()
+
+		rates, err := exchange.ParseRates(file, source)
+		if err != nil {
+			return err
+		}
+		if len(rates) == 0 {
+			return fmt.Errorf("no exchange rates found in %s", filepath.Base(args[0]))
+		}
+
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		if err := store.SaveExchangeRates(cmd.Context(), rates); err != nil {
+			return err
+		}
+		first, last := rates[0].Date, rates[0].Date
+		currencies := make(map[string]bool)
+		for _, rate := range rates {
+			if rate.Date.Before(first) {
+				first = rate.Date
+			}
+			if rate.Date.After(last) {
+				last = rate.Date
+			}
+			currencies[rate.Currency], currencies[rate.QuoteCurrency] = true, true
+		}
+		fmt.Printf("Imported %d %s exchange rates of %d currencies from %s to %s\n",
+			len(rates), rates[0].Source, len(currencies), first.Format("2006-01-02"), last.Format("2006-01-02"))
+
+		converted, missing, err := core.ConvertStoredTransactions(cmd.Context(), store, base, false)
+		if err != nil {
+			return err
+		}
+		printConversion(base, converted, missing)
+		return nil
+	},
+}
+
+// exchangeListCmd represents the exchange list subcommand
+var exchangeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored exchange rates",
+	Example: `  budgetassist exchange list --period 2026-09
+  budgetassist exchange list --currency USD --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != outputFormatTable && format != outputFormatJSON {
+			return fmt.Errorf("unsupported format: %s", format)
+		}
+		currency, _ := cmd.Flags().GetString("currency")
+		if currency != "" {
+			var err error
+			if currency, err = currencyCode(currency, "--currency"); err != nil {
+				return err
+			}
+		}
+
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		var rates []db.ExchangeRate
+		if period, _ := cmd.Flags().GetString("period"); period != "" {
+			start, end, err := core.ParsePeriod(period)
+			if err != nil {
+				return fmt.Errorf("invalid --period %q: expected YYYY-MM or YYYY", period)
+			}
+			last := end.AddDate(0, 0, -1)
+			rates, err = store.ListExchangeRates(cmd.Context(), &start, &last)
+			if err != nil {
+				return err
+			}
+		} else if rates, err = store.ListExchangeRates(cmd.Context(), nil, nil); err != nil {
+			return err
+		}
+		if currency != "" {
+			var matching []db.ExchangeRate
+			for _, rate := range rates {
+				if rate.Currency == currency || rate.QuoteCurrency == currency {
+					matching = append(matching, rate)
+				}
+			}
+			rates = matching
+		}
+
+		if format == outputFormatJSON {
+			return printJSON(rates)
+		}
+		if len(rates) == 0 {
+			fmt.Println("No exchange rates. Import them with: budgetassist exchange import <file>")
+			return nil
+		}
+		table := newTable()
+		table.SetHeader([]string{"Date", "Currency", "Rate", "Source"})
+		for _, rate := range rates {
+			table.Append([]string{
+				rate.Date.Format("2006-01-02"),
+				fmt.Sprintf("1 %s", rate.Currency),
+				fmt.Sprintf("%s %s", rate.Rate.String(), rate.QuoteCurrency),
+				valueOrDash(rate.Source),
+			})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+// exchangeConvertCmd represents the exchange convert subcommand
+var exchangeConvertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert stored transactions to the base currency",
+	Long: `Convert the stored transactions that have no amount in the base currency
+yet. Use --all after importing corrected rates to convert every transaction
+again. Changing currency.base converts all transactions on the next run.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		base, err := baseCurrency()
+		if err != nil {
+			return err
+		}
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		converted, missing, err := core.ConvertStoredTransactions(cmd.Context(), store, base, all)
+		if err != nil {
+			return err
+		}
+		printConversion(base, converted, missing)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exchangeCmd)
+	exchangeCmd.AddCommand(exchangeImportCmd)
+	exchangeCmd.AddCommand(exchangeListCmd)
+	exchangeCmd.AddCommand(exchangeConvertCmd)
+
+	exchangeImportCmd.Flags().String("source", exchange.SourceAuto, "Publisher of the file (auto, ecb, riksbank)")
+	exchangeListCmd.Flags().String("currency", "", "Only list rates of this currency")
+	exchangeListCmd.Flags().String("period", "", "Only list rates of this month (YYYY-MM) or year (YYYY)")
+	exchangeListCmd.Flags().String("format", outputFormatTable, "Output format (table, json)")
+	exchangeConvertCmd.Flags().Bool("all", false, "Convert transactions that already have an amount in the base currency again")
+}
+
+// printConversion prints how many transactions were converted to the base currency
+func printConversion(base string, converted, missing int) {
+	fmt.Printf("Converted %d transactions to %s\n", converted, base)
+	if missing > 0 {
+		fmt.Printf("⚠️  %d transactions have no exchange rate to %s for their booking day, import rates for those days\n", missing, base)
+	}
+}
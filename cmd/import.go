@@ -2,9 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/lindehoff/Budget-Assist/internal/core"
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/lindehoff/Budget-Assist/internal/processor"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -39,12 +45,18 @@ var importCmd = &cobra.Command{
 	
 Supported formats:
 - CSV files from major banks
+- XLSX workbooks described by a mapping profile
 - QIF (Quicken Interchange Format)
 - OFX (Open Financial Exchange)
-- PDF bank statements (experimental)
+- ISO 20022 camt.053/camt.054 XML statements
+- SWIFT MT940 statements
+
+The bank and format are detected from the file unless --bank is given.
+--format only checks that the detected file has the expected format.
 
 Example:
-  budgetassist import --format=csv --bank=chase statement.csv`,
+  budgetassist import statement.csv
+  budgetassist import --format=csv --bank=seb statement.csv`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) < 1 {
 			return &ImportError{
@@ -54,7 +66,11 @@ Example:
 			}
 		}
 
-		format, _ := cmd.Flags().GetString("format")
+		// The format is only compared with the detected parser when it was given
+		var format string
+		if cmd.Flags().Changed("format") {
+			format, _ = cmd.Flags().GetString("format")
+		}
 		bank, _ := cmd.Flags().GetString("bank")
 		currency, _ := cmd.Flags().GetString("currency")
 
@@ -62,6 +78,14 @@ Example:
 		if currency == "" {
 			currency = viper.GetString("import.default_currency")
 		}
+		currency, err := currencyCode(currency, "--currency")
+		if err != nil {
+			return &ImportError{Operation: "validate", Source: "currency", Err: err}
+		}
+		base, err := baseCurrency()
+		if err != nil {
+			return &ImportError{Operation: "validate", Source: "currency.base", Err: err}
+		}
 
 		filePath := args[0]
 		if !filepath.IsAbs(filePath) {
@@ -75,14 +99,223 @@ Example:
 			"currency", currency,
 		)
 
-		// TODO: Implement actual import logic in internal/core package
-		fmt.Printf("Import not yet implemented. Would import %s file from %s bank in %s currency\n",
-			format, bank, currency)
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		logger := slog.Default()
+
+		onDuplicate, _ := cmd.Flags().GetString("on-duplicate")
+		duplicatePolicy, err := core.ParseDuplicatePolicy(onDuplicate)
+		if err != nil {
+			return &ImportError{Operation: "validate", Source: "on-duplicate", Err: err}
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return &ImportError{
+				Operation: "open",
+				Source:    filePath,
+				Err:       fmt.Errorf("%w: %v", ErrInvalidSource, err),
+			}
+		}
+		defer file.Close()
+
+		proc, info, reader, err := newImportProcessor(format, bank, file, logger)
+		if err != nil {
+			return &ImportError{
+				Operation: "select_parser",
+				Source:    filePath,
+				Err:       err,
+			}
+		}
+
+		mapCategories, _ := cmd.Flags().GetBool("map-categories")
+		accountRef, _ := cmd.Flags().GetString("account")
 
+		var store db.Store
+		if !dryRun || mapCategories || accountRef != "" {
+			store, err = getStore()
+			if err != nil {
+				return &ImportError{
+					Operation: "initialize",
+					Source:    "store",
+					Err:       err,
+				}
+			}
+			defer store.Close()
+		}
+
+		opts := core.ImportOptions{
+			Currency:     currency,
+			BaseCurrency: base,
+			DryRun:       dryRun,
+			OnDuplicate:  duplicatePolicy,
+		}
+		if !dryRun {
+			opts.Batch, err = core.NewImportBatch(filePath, info.ID)
+			if err != nil {
+				return &ImportError{
+					Operation: "hash",
+					Source:    filePath,
+					Err:       fmt.Errorf("%w: %v", ErrInvalidSource, err),
+				}
+			}
+		}
+		if accountRef != "" {
+			account, err := resolveAccount(cmd.Context(), store, accountRef)
+			if err != nil {
+				return &ImportError{
+					Operation: "validate",
+					Source:    "account",
+					Err:       err,
+				}
+			}
+			opts.AccountID = &account.ID
+			// The account currency applies unless a currency is given
+			if !cmd.Flags().Changed("currency") {
+				opts.Currency = account.Currency
+			}
+		}
+		if mapCategories {
+			opts.Categories, err = core.NewCategoryMapper(cmd.Context(), store, viper.GetStringMapString("import.category_map"))
+			if err != nil {
+				return &ImportError{
+					Operation: "map_categories",
+					Source:    "store",
+					Err:       err,
+				}
+			}
+		}
+
+		importer := core.NewImporter(store, logger)
+		result, err := importer.Import(cmd.Context(), proc, reader, opts)
+		if err != nil {
+			if result != nil {
+				printRejectedRows(result.Rejected)
+			}
+			return &ImportError{
+				Operation: "import",
+				Source:    filePath,
+				Err:       fmt.Errorf("%w: %v", ErrParsingFailed, err),
+			}
+		}
+
+		if dryRun {
+			fmt.Printf("Dry run: %d transactions would be imported from %s\n\n",
+				len(result.Transactions), filepath.Base(filePath))
+			printImportedTransactions(result.Transactions)
+			printBalanceChecks(result.BalanceChecks)
+			printBalanceGaps(result.BalanceGaps)
+			printRejectedRows(result.Rejected)
+			return nil
+		}
+
+		fmt.Printf("Imported %d transactions from %s: %s\n", result.Stored, filepath.Base(filePath), result.Duplicates)
+		fmt.Printf("Import batch %d (undo with: budgetassist import undo %d)\n", opts.Batch.ID, opts.Batch.ID)
+		if mapCategories {
+			fmt.Printf("Mapped %d transactions onto existing categories\n", result.Categorized)
+		}
+		printBalanceChecks(result.BalanceChecks)
+		printBalanceGaps(result.BalanceGaps)
+		printRejectedRows(result.Rejected)
 		return nil
 	},
 }
 
+// newImportProcessor returns the registered parser for the given format and bank.
+// When bank is empty the parser is detected from the start of the file; the returned
+// reader must then be used in place of file.
+func newImportProcessor(format, bank string, file io.Reader, logger *slog.Logger) (processor.DocumentProcessor, processor.ParserInfo, io.Reader, error) {
+	registry, err := newImportRegistry()
+	if err != nil {
+		return nil, processor.ParserInfo{}, nil, err
+	}
+
+	var (
+		parser processor.Parser
+		info   processor.ParserInfo
+		reader = file
+	)
+	if bank != "" {
+		parser, info, err = registry.Get(bank, logger)
+	} else {
+		var sample []byte
+		sample, reader, err = processor.Sniff(file)
+		if err != nil {
+			return nil, processor.ParserInfo{}, nil, err
+		}
+		parser, info, err = registry.Detect(sample, logger)
+	}
+	if err != nil {
+		return nil, processor.ParserInfo{}, nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+
+	if format != "" && !strings.EqualFold(format, info.Format) {
+		return nil, processor.ParserInfo{}, nil, fmt.Errorf("%w: bank %q provides %s files, not %s", ErrUnsupportedFormat, info.ID, info.Format, format)
+	}
+
+	logger.Debug("selected import parser", "bank", info.ID, "format", info.Format)
+	return parser, info, reader, nil
+}
+
+// printImportedTransactions prints transactions as a table
+func printImportedTransactions(transactions []db.Transaction) {
+	table := newTable()
+	table.SetHeader([]string{"Date", "Description", "Amount", "Reference", "Balance"})
+	for _, tx := range transactions {
+		balance := ""
+		if tx.Balance.Valid {
+			balance = tx.Balance.Decimal.StringFixed(2)
+		}
+		table.Append([]string{
+			tx.Date.Format("2006-01-02"),
+			tx.Description,
+			tx.FormatAmount(),
+			tx.Reference,
+			balance,
+		})
+	}
+	table.Render()
+}
+
+// printBalanceChecks prints the statement balance verification, if the file stated balances
+func printBalanceChecks(checks []processor.BalanceCheck) {
+	if len(checks) == 0 {
+		return
+	}
+	fmt.Println("\nStatement balance check:")
+	for _, check := range checks {
+		symbol := "✅"
+		if !check.OK() {
+			symbol = "❌"
+		}
+		fmt.Printf("%s %s\n", symbol, check)
+	}
+}
+
+// printBalanceGaps lists the rows whose running balance does not follow from the rows before them
+func printBalanceGaps(gaps []processor.BalanceGap) {
+	if len(gaps) == 0 {
+		return
+	}
+	fmt.Printf("\nRunning balance check (%d gaps, rows may be missing or corrupted):\n", len(gaps))
+	for _, gap := range gaps {
+		fmt.Printf("❌ %s\n", gap)
+	}
+}
+
+// printRejectedRows lists the rows the parser skipped with the reason for each
+func printRejectedRows(rows []processor.RowError) {
+	if len(rows) == 0 {
+		return
+	}
+	fmt.Printf("\nRejected rows (%d):\n", len(rows))
+	for _, row := range rows {
+		fmt.Printf("❌ %s\n", row)
+		if row.Raw != "" {
+			fmt.Printf("    %s\n", row.Raw)
+		}
+	}
+}
+
 // importListCmd represents the import list subcommand
 var importListCmd = &cobra.Command{
 	Use:   "list",
@@ -92,32 +325,331 @@ This helps you identify the correct format and bank for your import.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Supported formats:")
 		fmt.Println("  - csv  : Comma-Separated Values")
+		fmt.Println("  - xlsx : Excel workbooks (mapping profile)")
 		fmt.Println("  - qif  : Quicken Interchange Format")
 		fmt.Println("  - ofx  : Open Financial Exchange")
-		fmt.Println("  - pdf  : PDF Bank Statements (experimental)")
+		fmt.Println("  - camt : ISO 20022 camt.053/camt.054 XML")
+		fmt.Println("  - mt940: SWIFT MT940 customer statement")
 		fmt.Println("\nSupported banks:")
-		fmt.Println("  - chase    : Chase Bank")
-		fmt.Println("  - bofa     : Bank of America")
-		fmt.Println("  - wells    : Wells Fargo")
-		fmt.Println("  - citi     : Citibank")
-		fmt.Println("  - discover : Discover Card")
-		fmt.Println("  - amex     : American Express")
-		fmt.Println("  - generic  : Generic CSV format")
+		registry, err := newImportRegistry()
+		if err != nil {
+			fmt.Printf("  (failed to load mapping profiles: %v)\n", err)
+			registry = processor.NewDefaultRegistry()
+		}
+		for _, info := range registry.List() {
+			fmt.Printf("  - %-16s : %s\n", info.ID, info.Description)
+		}
+	},
+}
+
+// importHistoryCmd represents the import history subcommand
+var importHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List import batches",
+	Long: `Display the import batches, most recent first, with the file each batch
+came from and how many of its rows were stored, skipped or rejected.
+
+A batch without a completion time was interrupted; its transactions can
+still be removed with "budgetassist import undo".`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := getStore()
+		if err != nil {
+			return &ImportError{Operation: "initialize", Source: "store", Err: err}
+		}
+		defer store.Close()
+
+		batches, err := store.ListImportBatches(cmd.Context())
+		if err != nil {
+			return &ImportError{Operation: "history", Source: "store", Err: err}
+		}
+		if len(batches) == 0 {
+			fmt.Println("No imports recorded.")
+			return nil
+		}
+
+		table := newTable()
+		table.SetHeader([]string{"Batch", "Started", "File", "Parser", "Parsed", "Stored", "Skipped", "Rejected", "Status"})
+		for _, batch := range batches {
+			status := "incomplete"
+			if batch.CompletedAt != nil {
+				status = "completed"
+			}
+			table.Append([]string{
+				fmt.Sprintf("%d", batch.ID),
+				batch.StartedAt.Format("2006-01-02 15:04"),
+				batch.FileName,
+				batch.Parser,
+				fmt.Sprintf("%d", batch.RowsParsed),
+				fmt.Sprintf("%d", batch.RowsStored),
+				fmt.Sprintf("%d", batch.RowsSkipped),
+				fmt.Sprintf("%d", batch.RowsRejected),
+				status,
+			})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+// importUndoCmd represents the import undo subcommand
+var importUndoCmd = &cobra.Command{
+	Use:   "undo <batch>",
+	Short: "Delete all transactions created by an import batch",
+	Long: `Delete the transactions an import batch created, together with the batch
+itself, in a single database transaction. Transactions that a later import
+flagged as duplicates of the deleted ones are kept.
+
+Example:
+  budgetassist import undo 12`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return &ImportError{
+				Operation: "undo",
+				Source:    args[0],
+				Err:       fmt.Errorf("invalid batch ID: %w", err),
+			}
+		}
+
+		store, err := getStore()
+		if err != nil {
+			return &ImportError{Operation: "initialize", Source: "store", Err: err}
+		}
+		defer store.Close()
+
+		batch, err := store.GetImportBatchByID(cmd.Context(), id)
+		if err != nil {
+			return &ImportError{Operation: "undo", Source: args[0], Err: err}
+		}
+
+		// Ask for confirmation unless --force is used
+		force, _ := cmd.Flags().GetBool("force")
+		if !force {
+			fmt.Printf("Are you sure you want to delete the %d transactions imported from %q (batch %d)? [y/N] ",
+				batch.RowsStored, batch.FileName, batch.ID)
+			var response string
+			_, err := fmt.Scanln(&response)
+			if err != nil {
+				return fmt.Errorf("failed to read response: %w", err)
+			}
+			if response != "y" && response != "Y" {
+				fmt.Println("Operation cancelled")
+				return nil
+			}
+		}
+
+		deleted, err := store.DeleteImportBatch(cmd.Context(), id)
+		if err != nil {
+			slog.Error("Failed to undo import batch", "batch", id, "error", err)
+			return &ImportError{Operation: "undo", Source: args[0], Err: err}
+		}
+
+		slog.Info("Successfully undid import batch", "batch", id, "transactions", deleted)
+		fmt.Printf("Deleted %d transactions from import batch %d (%s)\n", deleted, batch.ID, batch.FileName)
+		return nil
+	},
+}
+
+// newImportRegistry returns the built-in parsers together with the mapping profiles from the configuration
+func newImportRegistry() (*processor.Registry, error) {
+	registry := processor.NewDefaultRegistry()
+	profiles, err := loadImportProfiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, profile := range profiles {
+		if err := processor.RegisterProfile(registry, profile); err != nil {
+			return nil, fmt.Errorf("invalid mapping profile %q: %w", profile.ID, err)
+		}
+	}
+	return registry, nil
+}
+
+// loadImportProfiles reads the CSV mapping profiles stored under import.profiles in the configuration
+func loadImportProfiles() ([]processor.CSVProfile, error) {
+	var profiles []processor.CSVProfile
+	if err := viper.UnmarshalKey("import.profiles", &profiles); err != nil {
+		return nil, fmt.Errorf("%w: import.profiles: %v", ErrInvalidConfigFormat, err)
+	}
+	return profiles, nil
+}
+
+// resolveImportProfile returns the profile with the given id from the configuration,
+// or loads it from a YAML/JSON file when the argument is a path to one
+func resolveImportProfile(nameOrPath string) (processor.CSVProfile, error) {
+	switch strings.ToLower(filepath.Ext(nameOrPath)) {
+	case ".yaml", ".yml", ".json":
+		v := viper.New()
+		v.SetConfigFile(nameOrPath)
+		if err := v.ReadInConfig(); err != nil {
+			return processor.CSVProfile{}, fmt.Errorf("failed to read profile file: %w", err)
+		}
+		var profile processor.CSVProfile
+		if err := v.Unmarshal(&profile); err != nil {
+			return processor.CSVProfile{}, fmt.Errorf("%w: %v", ErrInvalidConfigFormat, err)
+		}
+		if err := profile.Validate(); err != nil {
+			return processor.CSVProfile{}, err
+		}
+		return profile, nil
+	}
+
+	profiles, err := loadImportProfiles()
+	if err != nil {
+		return processor.CSVProfile{}, err
+	}
+	for _, profile := range profiles {
+		if strings.EqualFold(profile.ID, nameOrPath) {
+			if err := profile.Validate(); err != nil {
+				return processor.CSVProfile{}, err
+			}
+			return profile, nil
+		}
+	}
+	return processor.CSVProfile{}, fmt.Errorf("mapping profile %q not found in import.profiles", nameOrPath)
+}
+
+// importProfileCmd represents the import profile command group
+var importProfileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage CSV mapping profiles",
+	Long: `Manage declarative CSV mapping profiles for bank exports.
+
+Profiles are stored under import.profiles in the configuration file and
+describe the delimiter, encoding, header names, date layout, number format
+and sign convention of a bank's CSV export. A configured profile can be
+used with --bank=<profile-id>.
+
+Example configuration:
+  import:
+    profiles:
+      - id: mybank
+        name: My Bank
+        delimiter: ";"
+        encoding: iso-8859-1
+        date_layout: "2006-01-02"
+        decimal_separator: ","
+        thousands_separator: " "
+        sign_convention: normal
+        columns:
+          date: Datum
+          description: Text
+          amount: Belopp
+          balance: Saldo`,
+}
+
+// importProfileListCmd represents the import profile list subcommand
+var importProfileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured CSV mapping profiles",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := loadImportProfiles()
+		if err != nil {
+			return err
+		}
+		if len(profiles) == 0 {
+			fmt.Println("No mapping profiles configured.")
+			return nil
+		}
+
+		table := newTable()
+		table.SetHeader([]string{"ID", "Name", "Format", "Delimiter", "Encoding", "Date Layout"})
+		for _, profile := range profiles {
+			if err := profile.Validate(); err != nil {
+				table.Append([]string{profile.ID, profile.Name, profile.Format, "", "", "invalid: " + err.Error()})
+				continue
+			}
+			table.Append([]string{profile.ID, profile.Name, profile.Format, profile.Delimiter, profile.Encoding, profile.DateLayout})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+// importProfileTestCmd represents the import profile test subcommand
+var importProfileTestCmd = &cobra.Command{
+	Use:   "test <profile> <file>",
+	Short: "Parse a file with a mapping profile and show the rows",
+	Long: `Parse a CSV or XLSX file with a mapping profile without storing anything.
+
+The profile is either the id of a profile in import.profiles or a path to
+a YAML or JSON file containing a single profile.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, err := resolveImportProfile(args[0])
+		if err != nil {
+			return &ImportError{Operation: "load_profile", Source: args[0], Err: err}
+		}
+
+		proc, err := processor.NewProfileParser(profile, slog.Default())
+		if err != nil {
+			return &ImportError{Operation: "load_profile", Source: args[0], Err: err}
+		}
+
+		file, err := os.Open(args[1])
+		if err != nil {
+			return &ImportError{
+				Operation: "open",
+				Source:    args[1],
+				Err:       fmt.Errorf("%w: %v", ErrInvalidSource, err),
+			}
+		}
+		defer file.Close()
+
+		transactions, err := proc.ProcessDocument(cmd.Context(), file)
+		var rejected []processor.RowError
+		if reporter, ok := proc.(processor.RowErrorReporter); ok {
+			rejected = reporter.RejectedRows()
+		}
+		if err != nil {
+			printRejectedRows(rejected)
+			return &ImportError{
+				Operation: "parse",
+				Source:    args[1],
+				Err:       fmt.Errorf("%w: %v", ErrParsingFailed, err),
+			}
+		}
+
+		fmt.Printf("Profile %q parsed %d transactions from %s\n\n", profile.ID, len(transactions), filepath.Base(args[1]))
+		table := newTable()
+		table.SetHeader([]string{"Date", "Description", "Amount", "Reference", "Balance"})
+		for _, tx := range transactions {
+			balance := ""
+			if tx.Balance.Valid {
+				balance = tx.Balance.Decimal.StringFixed(2)
+			}
+			table.Append([]string{
+				tx.Date.Format("2006-01-02"),
+				tx.Description,
+				tx.Amount.StringFixed(2),
+				tx.Reference,
+				balance,
+			})
+		}
+		table.Render()
+		printRejectedRows(rejected)
+		return nil
 	},
 }
 
 func init() {
 	importCmd.AddCommand(importListCmd)
+	importCmd.AddCommand(importProfileCmd)
+	importCmd.AddCommand(importHistoryCmd)
+	importCmd.AddCommand(importUndoCmd)
+	importProfileCmd.AddCommand(importProfileListCmd)
+	importProfileCmd.AddCommand(importProfileTestCmd)
 	rootCmd.AddCommand(importCmd)
 
 	// Add flags for the import command
-	importCmd.Flags().StringP("format", "f", "csv", "Import format (csv, qif, ofx, pdf)")
-	importCmd.Flags().StringP("bank", "b", "generic", "Bank template to use for parsing")
+	importCmd.Flags().StringP("format", "f", "", "Expected import format (csv, xlsx, qif, ofx, camt, mt940) (default: detect from file)")
+	importCmd.Flags().StringP("bank", "b", "", "Bank template to use for parsing (default: detect from file)")
 	importCmd.Flags().StringP("currency", "c", "", "Currency code (default from config)")
+	importCmd.Flags().String("account", "", "Account (ID or name) the transactions are booked on (default: match the account number in the file)")
 	importCmd.Flags().BoolP("dry-run", "d", false, "Validate import without saving")
+	importCmd.Flags().String("on-duplicate", string(core.DuplicateSkip), "What to do with transactions that are already stored (skip, flag, replace)")
+	importCmd.Flags().Bool("map-categories", false, "Map categories stated in the file (e.g. QIF L fields) onto existing categories using import.category_map")
 
-	// Mark required flags
-	if err := importCmd.MarkFlagRequired("format"); err != nil {
-		fmt.Printf("failed to mark format flag as required: %v\n", err)
-	}
+	importUndoCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
 }
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/ingest"
+	"github.com/lindehoff/Budget-Assist/internal/pipeline"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var ingestCmd = &cobra.Command{
+	Use:   "ingest",
+	Short: "Ingest documents from other sources",
+	Long:  `Fetch documents from sources other than the local file system and process them.`,
+}
+
+var ingestIMAPCmd = &cobra.Command{
+	Use:   "imap",
+	Short: "Process invoice and statement attachments from an IMAP folder",
+	Long: `Connect to the configured IMAP folder and process the PDF, CSV and other
+supported attachments of the messages that have not been processed yet.
+
+Each message with attachments is saved to imap.archive_dir and processed as a
+processing job of its own, see "budgetassist jobs". Stored transactions record
+the subject and sender of the message they came from. Messages are marked with
+the imap.processed_flag keyword once all their attachments are stored, so the
+next run only fetches new messages. Messages whose attachments could not be
+processed are left unmarked and retried on the next run.
+
+The server is configured with imap.address, imap.username and imap.password;
+the password can also be given in the IMAP_PASSWORD environment variable.`,
+	Example: `  budgetassist ingest imap
+  budgetassist ingest imap --folder Fakturor --since 2026-09-01`,
+	Args: cobra.NoArgs,
+	RunE: runIngestIMAP,
+}
+
+func init() {
+	rootCmd.AddCommand(ingestCmd)
+	ingestCmd.AddCommand(ingestIMAPCmd)
+	addProcessFlags(ingestIMAPCmd)
+	ingestIMAPCmd.Flags().String("folder", "", "IMAP folder to read (overrides imap.folder)")
+	ingestIMAPCmd.Flags().String("since", "", "Only read messages received on or after this date (YYYY-MM-DD)")
+}
+
+func runIngestIMAP(cmd *cobra.Command, args []string) error {
+	config, err := imapConfigFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	opts, err := processOptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	archiveDir := viper.GetString("imap.archive_dir")
+	if archiveDir == "" {
+		archiveDir = filepath.Join(userHomeDir, ".budgetassist", "mail")
+	}
+
+	// Use global logger configured in root command
+	logger := slog.Default()
+
+	// Get database connection from global config
+	store, err := getStore()
+	if err != nil {
+		return fmt.Errorf("failed to get database store: %w", err)
+	}
+	defer store.Close()
+
+	p, err := newProcessPipeline(cmd, store, logger)
+	if err != nil {
+		return err
+	}
+	if err := applyCategoryMapping(cmd, store, &opts); err != nil {
+		return err
+	}
+	if err := applyAccount(cmd, store, &opts); err != nil {
+		return err
+	}
+
+	mailbox, err := ingest.DialIMAP(config, logger)
+	if err != nil {
+		return fmt.Errorf("failed to open IMAP folder: %w", err)
+	}
+	defer mailbox.Close()
+
+	fmt.Printf("Reading %s on %s\n", config.Folder, config.Address)
+	results, err := p.IngestMailbox(cmd.Context(), mailbox, pipeline.MailOptions{
+		Process:    opts,
+		ArchiveDir: archiveDir,
+	})
+	if err != nil && results == nil {
+		return fmt.Errorf("failed to ingest messages: %w", err)
+	}
+	ingestErr := err
+
+	var failed, attachments int
+	for _, result := range results {
+		printMailResult(result)
+		attachments += len(result.Results)
+		if result.Error != nil {
+			failed++
+		}
+	}
+	fmt.Printf("\nMessages: %d, attachments processed: %d, failed messages: %d\n",
+		len(results), attachments, failed)
+
+	if ingestErr != nil {
+		return fmt.Errorf("failed to ingest messages: %w", ingestErr)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d messages could not be processed and will be retried on the next run", failed)
+	}
+	return nil
+}
+
+// imapConfigFromFlags builds the IMAP settings from the configuration and the command flags
+func imapConfigFromFlags(cmd *cobra.Command) (ingest.IMAPConfig, error) {
+	config := ingest.IMAPConfig{
+		Address:       viper.GetString("imap.address"),
+		Username:      viper.GetString("imap.username"),
+		Password:      os.Getenv("IMAP_PASSWORD"),
+		Folder:        viper.GetString("imap.folder"),
+		TLS:           !viper.IsSet("imap.tls") || viper.GetBool("imap.tls"),
+		ProcessedFlag: viper.GetString("imap.processed_flag"),
+	}
+	if config.Password == "" {
+		config.Password = viper.GetString("imap.password")
+	}
+	if config.Address == "" {
+		return config, fmt.Errorf("no IMAP server configured, set imap.address with 'budgetassist config set imap.address imap.example.com:993'")
+	}
+	if folder, _ := cmd.Flags().GetString("folder"); folder != "" {
+		config.Folder = folder
+	}
+	if config.Folder == "" {
+		config.Folder = ingest.DefaultIMAPFolder
+	}
+	if since, _ := cmd.Flags().GetString("since"); since != "" {
+		date, err := time.ParseInLocation("2006-01-02", since, time.Local)
+		if err != nil {
+			return config, fmt.Errorf("invalid --since %q: expected YYYY-MM-DD", since)
+		}
+		config.Since = date
+	}
+	return config, nil
+}
+
+// printMailResult prints the outcome of a message and each of its attachments
+func printMailResult(result pipeline.MailResult) {
+	subject := result.Subject
+	if subject == "" {
+		subject = "(no subject)"
+	}
+	switch {
+	case result.Error != nil && len(result.Results) == 0:
+		fmt.Printf("❌ %s from %s: %v\n", subject, result.Sender, result.Error)
+		return
+	case len(result.Results) == 0:
+		fmt.Printf("⏭️  %s from %s: No attachments to process\n", subject, result.Sender)
+		return
+	}
+
+	fmt.Printf("📧 %s from %s\n", subject, result.Sender)
+	for _, processed := range result.Results {
+		name := documentName(processed)
+		switch {
+		case processed.Error != nil:
+			fmt.Printf("    ❌ %s: %v\n", name, processed.Error)
+			for _, storeErr := range processed.StoreErrors {
+				fmt.Printf("        ❌ %s\n", storeErr)
+			}
+		case processed.Skipped:
+			fmt.Printf("    ⏭️  %s: Unchanged since import batch %d, skipped\n", name, processed.PreviousBatchID)
+		default:
+			fmt.Printf("    ✅ %s: Found %d transactions, stored %d (%s), import batch %d\n",
+				name,
+				processed.TransactionsFound,
+				processed.Stored,
+				processed.Duplicates,
+				processed.BatchID)
+		}
+		for _, row := range processed.Rejected {
+			fmt.Printf("        ⚠️  rejected %s\n", row)
+		}
+		for _, gap := range processed.BalanceGaps {
+			fmt.Printf("        ⚠️  balance gap %s\n", gap)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// jobsCmd represents the jobs command
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "Show processing jobs",
+	Long: `Show the progress of the jobs recorded by the process command.
+
+Each document of a job moves through the stages pending, extracted,
+analysed and stored, or ends as skipped or failed. Interrupted and failed
+jobs are continued with "budgetassist process --resume <job>".`,
+}
+
+// jobsListCmd represents the jobs list subcommand
+var jobsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List processing jobs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		jobs, err := store.ListProcessingJobs(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			fmt.Println("No processing jobs recorded.")
+			return nil
+		}
+
+		table := newTable()
+		table.SetHeader([]string{"Job", "Started", "Path", "Status", "Files", "Stored", "Skipped", "Failed", "Remaining"})
+		for _, job := range jobs {
+			counts := countJobFiles(job.Files)
+			table.Append([]string{
+				fmt.Sprintf("%d", job.ID),
+				job.CreatedAt.Format("2006-01-02 15:04"),
+				job.Path,
+				string(job.Status),
+				fmt.Sprintf("%d", len(job.Files)),
+				fmt.Sprintf("%d", counts[db.JobFileStored]),
+				fmt.Sprintf("%d", counts[db.JobFileSkipped]),
+				fmt.Sprintf("%d", counts[db.JobFileFailed]),
+				fmt.Sprintf("%d", counts[db.JobFilePending]+counts[db.JobFileExtracted]+counts[db.JobFileAnalysed]),
+			})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+// jobsShowCmd represents the jobs show subcommand
+var jobsShowCmd = &cobra.Command{
+	Use:   "show <job>",
+	Short: "Show the documents of a processing job",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid job ID: %w", err)
+		}
+
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		job, err := store.GetProcessingJobByID(cmd.Context(), id)
+		if err != nil {
+			return fmt.Errorf("failed to get processing job %d: %w", id, err)
+		}
+
+		fmt.Printf("Job %d: %s\n", job.ID, job.Path)
+		fmt.Printf("Status: %s\n", job.Status)
+		fmt.Printf("Started: %s\n", job.CreatedAt.Format("2006-01-02 15:04:05"))
+		if job.CompletedAt != nil {
+			fmt.Printf("Completed: %s\n", job.CompletedAt.Format("2006-01-02 15:04:05"))
+		}
+		fmt.Println()
+
+		table := newTable()
+		table.SetHeader([]string{"#", "File", "Status", "Found", "Stored", "Batch", "Error"})
+		for _, file := range job.Files {
+			status := string(file.Status)
+			if file.Status == db.JobFileFailed && file.RetryFrom != "" {
+				status = fmt.Sprintf("%s (resumes %s)", file.Status, file.RetryFrom)
+			}
+			batch := ""
+			if file.ImportBatchID != nil {
+				batch = fmt.Sprintf("%d", *file.ImportBatchID)
+			}
+			table.Append([]string{
+				fmt.Sprintf("%d", file.Position+1),
+				jobFileName(file),
+				status,
+				fmt.Sprintf("%d", file.TransactionsFound),
+				fmt.Sprintf("%d", file.Stored),
+				batch,
+				file.Error,
+			})
+		}
+		table.Render()
+
+		if job.Status != db.JobCompleted {
+			fmt.Printf("\nResume with: budgetassist process --resume %d\n", job.ID)
+		}
+		return nil
+	},
+}
+
+// jobFileName returns the file name of a job document, prefixed with the
+// archive or email it is extracted from
+func jobFileName(file db.JobFile) string {
+	if file.Entry == "" {
+		return filepath.Base(file.Path)
+	}
+	return filepath.Join(filepath.Base(file.Path), filepath.FromSlash(file.Entry))
+}
+
+// countJobFiles counts the documents of a job in each stage
+func countJobFiles(files []db.JobFile) map[db.JobFileStatus]int {
+	counts := make(map[db.JobFileStatus]int)
+	for _, file := range files {
+		counts[file.Status]++
+	}
+	return counts
+}
+
+func init() {
+	rootCmd.AddCommand(jobsCmd)
+	jobsCmd.AddCommand(jobsListCmd)
+	jobsCmd.AddCommand(jobsShowCmd)
+}
@@ -5,12 +5,14 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/lindehoff/Budget-Assist/internal/ai"
+	"github.com/lindehoff/Budget-Assist/internal/core"
+	"github.com/lindehoff/Budget-Assist/internal/db"
 	"github.com/lindehoff/Budget-Assist/internal/docprocess"
 	"github.com/lindehoff/Budget-Assist/internal/pipeline"
-	"github.com/lindehoff/Budget-Assist/internal/processor"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -25,42 +27,128 @@ The command will:
 3. Categorize transactions using AI
 4. Store results in the database
 
+ZIP archives, emails (.eml) and mailboxes (.mbox) are opened and each
+supported document or attachment in them is processed on its own. Each
+transaction records the container it came from and, for email attachments,
+the subject and sender of the email.
+
+Documents whose content was processed before are skipped, so a whole
+statements directory can be processed again to pick up new files. Use
+--force to process them again.
+
+Every run is recorded as a processing job. An interrupted or failed job is
+continued with --resume, which reuses the options the job was started with
+and picks up each document at the stage it reached. Only --workers and --no-ai
+can be given with --resume. See "budgetassist jobs".
+
 You can provide additional context about the documents using the following flags:
 --doc-type: Type of document (e.g., receipt, bank_statement, invoice)
 --transaction-insights: Additional context about the transactions
 --category-insights: Hints for transaction categorization`,
-	Args: cobra.ExactArgs(1),
+	Args: cobra.MaximumNArgs(1),
 	RunE: runProcess,
 }
 
 func init() {
 	rootCmd.AddCommand(processCmd)
-	processCmd.Flags().Bool("no-ai", false, "Skip AI categorization")
-	processCmd.Flags().String("doc-type", "", "Type of document (e.g., receipt, bank_statement, invoice)")
-	processCmd.Flags().String("transaction-insights", "", "Additional context about the transactions")
-	processCmd.Flags().String("category-insights", "", "Hints for transaction categorization")
+	addProcessFlags(processCmd)
+	processCmd.Flags().Bool("force", false, "Reprocess documents that were already processed with the same content")
+	processCmd.Flags().Int("workers", 1, "Number of documents to process in parallel")
+	processCmd.Flags().Uint("resume", 0, "Resume the processing job with this ID")
 }
 
-func runProcess(cmd *cobra.Command, args []string) error {
-	path := args[0]
+// jobOptionFlags are the flags stored with a processing job, which a resumed job keeps
+var jobOptionFlags = []string{"doc-type", "transaction-insights", "category-insights", "bank", "account",
+	"currency", "map-categories", "on-duplicate", "force"}
 
-	// Validate path exists
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return fmt.Errorf("path does not exist: %s", path)
+// addProcessFlags adds the flags that configure how documents are processed
+func addProcessFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("no-ai", false, "Skip AI categorization")
+	cmd.Flags().String("doc-type", "", "Type of document (e.g., receipt, bank_statement, invoice)")
+	cmd.Flags().String("transaction-insights", "", "Additional context about the transactions")
+	cmd.Flags().String("category-insights", "", "Hints for transaction categorization")
+	cmd.Flags().String("bank", "", "Bank parser to use for statement files (default: detect from file)")
+	cmd.Flags().String("account", "", "Account (ID or name) the transactions are booked on (default: match the account number in the file)")
+	cmd.Flags().String("currency", "", "Currency of transactions whose document states none (default: the account currency, or from config)")
+	cmd.Flags().Bool("map-categories", false, "Map categories stated in the document (e.g. QIF) onto existing categories instead of using AI")
+	cmd.Flags().String("on-duplicate", string(core.DuplicateSkip), "What to do with transactions stored by an earlier run (skip, flag, replace)")
+}
+
+// processOptionsFromFlags reads the processing options added by addProcessFlags
+func processOptionsFromFlags(cmd *cobra.Command) (pipeline.ProcessOptions, error) {
+	onDuplicate, _ := cmd.Flags().GetString("on-duplicate")
+	duplicatePolicy, err := core.ParseDuplicatePolicy(onDuplicate)
+	if err != nil {
+		return pipeline.ProcessOptions{}, fmt.Errorf("invalid --on-duplicate: %w", err)
 	}
 
-	// Use global logger configured in root command
-	logger := slog.Default()
+	// Get insights from flags
+	docType, _ := cmd.Flags().GetString("doc-type")
+	transactionInsights, _ := cmd.Flags().GetString("transaction-insights")
+	categoryInsights, _ := cmd.Flags().GetString("category-insights")
+	bank, _ := cmd.Flags().GetString("bank")
 
-	// Log that we're starting processing
-	logger.Info("Starting document processing", "path", path)
+	currency, _ := cmd.Flags().GetString("currency")
+	if currency == "" {
+		currency = viper.GetString("import.default_currency")
+	}
+	currency, err = currencyCode(currency, "--currency")
+	if err != nil {
+		return pipeline.ProcessOptions{}, err
+	}
+	base, err := baseCurrency()
+	if err != nil {
+		return pipeline.ProcessOptions{}, err
+	}
 
-	// Get database connection from global config
-	store, err := getStore()
+	return pipeline.ProcessOptions{
+		DocumentType:        docType,
+		TransactionInsights: transactionInsights,
+		CategoryInsights:    categoryInsights,
+		Bank:                bank,
+		Currency:            currency,
+		BaseCurrency:        base,
+		OnDuplicate:         duplicatePolicy,
+	}, nil
+}
+
+// applyCategoryMapping loads the category mapper when --map-categories is set
+func applyCategoryMapping(cmd *cobra.Command, store db.Store, opts *pipeline.ProcessOptions) error {
+	mapCategories, _ := cmd.Flags().GetBool("map-categories")
+	if !mapCategories {
+		return nil
+	}
+	overrides := viper.GetStringMapString("import.category_map")
+	categories, err := core.NewCategoryMapper(cmd.Context(), store, overrides)
 	if err != nil {
-		return fmt.Errorf("failed to get database store: %w", err)
+		return fmt.Errorf("failed to load categories for mapping: %w", err)
+	}
+	opts.Categories = categories
+	opts.MapCategories, opts.CategoryMap = true, overrides
+	return nil
+}
+
+// applyAccount binds the processed transactions to the account given with --account,
+// whose currency they are booked in unless --currency is given
+func applyAccount(cmd *cobra.Command, store db.Store, opts *pipeline.ProcessOptions) error {
+	ref, _ := cmd.Flags().GetString("account")
+	if ref == "" {
+		return nil
+	}
+	account, err := resolveAccount(cmd.Context(), store, ref)
+	if err != nil {
+		return err
 	}
+	opts.AccountID = &account.ID
+	// The account currency applies unless a currency is given
+	if !cmd.Flags().Changed("currency") {
+		opts.Currency = account.Currency
+	}
+	return nil
+}
 
+// newProcessPipeline creates the processing pipeline, with the AI service unless --no-ai is set
+func newProcessPipeline(cmd *cobra.Command, store db.Store, logger *slog.Logger) (*pipeline.Pipeline, error) {
 	// Check if AI should be skipped
 	skipAI, _ := cmd.Flags().GetBool("no-ai")
 	var aiService ai.Service
@@ -92,7 +180,7 @@ func runProcess(cmd *cobra.Command, args []string) error {
 		}
 
 		if aiConfig.APIKey == "" {
-			return fmt.Errorf("OpenAI API key not found in environment variable OPENAI_API_KEY or config file (ai.api_key)")
+			return nil, fmt.Errorf("OpenAI API key not found in environment variable OPENAI_API_KEY or config file (ai.api_key)")
 		}
 
 		// Initialize AI service
@@ -104,40 +192,111 @@ func runProcess(cmd *cobra.Command, args []string) error {
 
 	// Initialize processors
 	pdfProcessor := docprocess.NewPDFProcessor(logger, aiService)
-	csvProcessor := processor.NewSEBProcessor(logger)
+	csvParsers, err := newImportRegistry()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load import parsers: %w", err)
+	}
 	logger.Debug("Initialized document processors")
 
-	// Get insights from flags
-	docType, _ := cmd.Flags().GetString("doc-type")
-	transactionInsights, _ := cmd.Flags().GetString("transaction-insights")
-	categoryInsights, _ := cmd.Flags().GetString("category-insights")
+	return pipeline.NewPipeline(pdfProcessor, csvParsers, aiService, store, logger), nil
+}
 
-	// Create processing options
-	opts := pipeline.ProcessOptions{
-		DocumentType:        docType,
-		TransactionInsights: transactionInsights,
-		CategoryInsights:    categoryInsights,
+func runProcess(cmd *cobra.Command, args []string) error {
+	resume, _ := cmd.Flags().GetUint("resume")
+	var path string
+	switch {
+	case resume != 0 && len(args) > 0:
+		return fmt.Errorf("a path cannot be given with --resume")
+	case resume == 0 && len(args) == 0:
+		return fmt.Errorf("a path is required unless --resume is given")
+	case resume != 0:
+		if err := checkResumeFlags(cmd); err != nil {
+			return err
+		}
+	default:
+		path = args[0]
+
+		// Validate path exists
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("path does not exist: %s", path)
+		}
+	}
+
+	workers, _ := cmd.Flags().GetInt("workers")
+	if workers < 1 {
+		return fmt.Errorf("invalid --workers: must be at least 1")
+	}
+
+	// Use global logger configured in root command
+	logger := slog.Default()
+
+	// Log that we're starting processing
+	logger.Info("Starting document processing", "path", path)
+
+	// Get database connection from global config
+	store, err := getStore()
+	if err != nil {
+		return fmt.Errorf("failed to get database store: %w", err)
 	}
-	logger.Debug("Processing options",
-		"document_type", docType,
-		"transaction_insights", transactionInsights != "",
-		"category_insights", categoryInsights != "")
 
 	// Create processing pipeline
-	p := pipeline.NewPipeline(pdfProcessor, csvProcessor, aiService, store, logger)
+	p, err := newProcessPipeline(cmd, store, logger)
+	if err != nil {
+		return err
+	}
+
+	// Start a new job, or continue an earlier one with the options it was started with
+	var job *db.ProcessingJob
+	var opts pipeline.ProcessOptions
+	if resume != 0 {
+		job, opts, err = p.LoadJob(cmd.Context(), resume)
+		if err != nil {
+			return err
+		}
+		path = job.Path
+	} else {
+		if opts, err = processOptionsFromFlags(cmd); err != nil {
+			return err
+		}
+		opts.Force, _ = cmd.Flags().GetBool("force")
+		if err := applyCategoryMapping(cmd, store, &opts); err != nil {
+			return err
+		}
+		if err := applyAccount(cmd, store, &opts); err != nil {
+			return err
+		}
+	}
+	opts.Workers = workers
+	logger.Debug("Processing options",
+		"document_type", opts.DocumentType,
+		"transaction_insights", opts.TransactionInsights != "",
+		"category_insights", opts.CategoryInsights != "")
+
+	if job == nil {
+		job, err = p.CreateJob(cmd.Context(), path, opts)
+		if err != nil {
+			return fmt.Errorf("failed to process documents: %w", err)
+		}
+	}
+	fmt.Printf("Processing job %d: %s (%d files)\n", job.ID, job.Path, len(job.Files))
 
 	// Process documents
-	logger.Info("Processing documents", "path", path)
-	results, err := p.ProcessDocuments(cmd.Context(), path, opts)
+	logger.Info("Processing documents", "path", path, "job", job.ID)
+	results, err := p.RunJob(cmd.Context(), job, opts)
 	if err != nil {
 		logger.Error("Failed to process documents", "error", err)
-		return fmt.Errorf("failed to process documents: %w", err)
+		// An interrupted run still reports the documents it finished
+		if results == nil {
+			return fmt.Errorf("failed to process documents: %w", err)
+		}
 	}
+	processErr := err
 
 	// Log results summary
 	logger.Info("Processing complete",
 		"files_processed", len(results),
-		"successful", len(results)-countFailures(results),
+		"skipped", countSkipped(results),
+		"successful", len(results)-countSkipped(results)-countFailures(results),
 		"failed", countFailures(results),
 		"total_transactions", countTransactions(results))
 
@@ -145,30 +304,105 @@ func runProcess(cmd *cobra.Command, args []string) error {
 	fmt.Printf("\nProcessing Results:\n")
 	fmt.Printf("==================\n")
 
-	var totalTransactions int
+	var totalTransactions, storedTransactions, failedTransactions, rejectedRows int
 	var failures int
+	var duplicates core.DedupSummary
+	documents := make(map[pipeline.DocumentStatus][]string)
 
 	for _, result := range results {
-		if result.Error != nil {
-			fmt.Printf("❌ %s: %v\n", filepath.Base(result.FilePath), result.Error)
+		if result.Status != "" && !result.Skipped && !result.EarlierRun {
+			documents[result.Status] = append(documents[result.Status], documentName(result))
+		}
+		switch {
+		case result.Error != nil:
+			fmt.Printf("❌ %s: %v\n", documentName(result), result.Error)
+			for _, storeErr := range result.StoreErrors {
+				fmt.Printf("    ❌ %s\n", storeErr)
+			}
 			failures++
-		} else {
-			fmt.Printf("✅ %s: Found %d transactions\n",
-				filepath.Base(result.FilePath),
-				result.TransactionsFound)
+			failedTransactions += result.Failed
+		case result.EarlierRun && !result.Skipped:
+			fmt.Printf("☑️  %s: Stored %d of %d transactions in an earlier run, import batch %d\n",
+				documentName(result),
+				result.Stored,
+				result.TransactionsFound,
+				result.BatchID)
+			totalTransactions += result.TransactionsFound
+			storedTransactions += result.Stored
+		case result.Skipped:
+			fmt.Printf("⏭️  %s: Unchanged since import batch %d, skipped\n",
+				documentName(result),
+				result.PreviousBatchID)
+		default:
+			fmt.Printf("✅ %s: Found %d transactions, stored %d (%s), import batch %d\n",
+				documentName(result),
+				result.TransactionsFound,
+				result.Stored,
+				result.Duplicates,
+				result.BatchID)
 			totalTransactions += result.TransactionsFound
+			storedTransactions += result.Stored
+			duplicates.Add(result.Duplicates)
 		}
+		for _, row := range result.Rejected {
+			fmt.Printf("    ⚠️  rejected %s\n", row)
+		}
+		for _, gap := range result.BalanceGaps {
+			fmt.Printf("    ⚠️  balance gap %s\n", gap)
+		}
+		rejectedRows += len(result.Rejected)
 	}
 
+	skipped := countSkipped(results)
+	printDocumentList("New files", documents[pipeline.DocumentNew])
+	printDocumentList("Changed files", documents[pipeline.DocumentChanged])
+	printDocumentList("Reprocessed unchanged files", documents[pipeline.DocumentUnchanged])
+
 	fmt.Printf("\nSummary:\n")
-	fmt.Printf("- Files processed: %d\n", len(results))
-	fmt.Printf("- Successful: %d\n", len(results)-failures)
+	fmt.Printf("- Files processed: %d\n", len(results)-skipped)
+	fmt.Printf("- Skipped (unchanged): %d\n", skipped)
+	fmt.Printf("- New files: %d\n", len(documents[pipeline.DocumentNew]))
+	fmt.Printf("- Changed files: %d\n", len(documents[pipeline.DocumentChanged]))
+	fmt.Printf("- Successful: %d\n", len(results)-skipped-failures)
 	fmt.Printf("- Failed: %d\n", failures)
 	fmt.Printf("- Total transactions found: %d\n", totalTransactions)
+	fmt.Printf("- Transactions stored: %d\n", storedTransactions)
+	fmt.Printf("- New transactions: %d\n", duplicates.New)
+	fmt.Printf("- Already known: %d\n", duplicates.Known())
+	fmt.Printf("- Transactions failed: %d\n", failedTransactions)
+	fmt.Printf("- Rows rejected by parsers: %d\n", rejectedRows)
 
+	if processErr != nil || failures > 0 {
+		fmt.Printf("\nResume with: budgetassist process --resume %d\n", job.ID)
+	}
+	if processErr != nil {
+		return fmt.Errorf("failed to process documents: %w", processErr)
+	}
 	return nil
 }
 
+// documentName returns the file name of a processed document, prefixed with
+// the archive or email it was extracted from
+func documentName(result pipeline.ProcessingResult) string {
+	if result.Container != "" {
+		if name, err := filepath.Rel(filepath.Dir(result.Container), result.FilePath); err == nil {
+			return name
+		}
+	}
+	return filepath.Base(result.FilePath)
+}
+
+// printDocumentList prints the names of the documents under a heading, if any
+func printDocumentList(heading string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	fmt.Printf("\n%s (%d):\n", heading, len(names))
+	for _, name := range names {
+		fmt.Printf("  - %s\n", name)
+	}
+}
+
 // Helper functions for logging
 func countFailures(results []pipeline.ProcessingResult) int {
 	count := 0
@@ -187,3 +421,29 @@ func countTransactions(results []pipeline.ProcessingResult) int {
 	}
 	return count
 }
+
+func countSkipped(results []pipeline.ProcessingResult) int {
+	count := 0
+	for _, result := range results {
+		if result.Skipped {
+			count++
+		}
+	}
+	return count
+}
+
+// checkResumeFlags rejects the flags a resumed job would ignore, as it keeps
+// the options it was started with
+func checkResumeFlags(cmd *cobra.Command) error {
+	var given []string
+	for _, name := range jobOptionFlags {
+		if cmd.Flags().Changed(name) {
+			given = append(given, "--"+name)
+		}
+	}
+	if len(given) > 0 {
+		return fmt.Errorf("%s cannot be given with --resume, the job continues with the options it was started with",
+			strings.Join(given, ", "))
+	}
+	return nil
+}
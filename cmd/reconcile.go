@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/core"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+// reconcileCmd represents the reconcile command
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile <account>",
+	Short: "Check the stored transactions of an account against its statement balances",
+	Long: `Compare the transactions stored for an account in a period with the opening
+and closing balances of its statements, and report the discrepancy.
+
+Balances are recorded when statements are imported: the balances camt, MT940
+and OFX files state, and the running balance of SEB exports. The opening
+balance of the account counts as well. For statements whose balances were not
+imported, such as PDF statements, give them with --opening and --closing.
+
+When the transactions do not add up, the transactions that may be missing and
+those that may have been stored twice are listed. Candidates marked with ✱
+resolve the discrepancy on their own.`,
+	Example: `  budgetassist reconcile "SEB Lönekonto" --period 2026-09
+  budgetassist reconcile 2 --period 2026-09 --opening 12500.00 --closing 9875.50`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReconcile,
+}
+
+func init() {
+	rootCmd.AddCommand(reconcileCmd)
+	reconcileCmd.Flags().String("period", "", "Month (YYYY-MM) or year (YYYY) to reconcile (required)")
+	reconcileCmd.Flags().String("opening", "", "Balance at the start of the period, overrides the imported balances")
+	reconcileCmd.Flags().String("closing", "", "Balance at the end of the period, overrides the imported balances")
+	reconcileCmd.Flags().String("format", outputFormatTable, "Output format (table, json)")
+	_ = reconcileCmd.MarkFlagRequired("period")
+}
+
+func runReconcile(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	if format != outputFormatTable && format != outputFormatJSON {
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+	period, _ := cmd.Flags().GetString("period")
+	start, end, err := core.ParsePeriod(period)
+	if err != nil {
+		return fmt.Errorf("invalid --period %q: expected YYYY-MM or YYYY", period)
+	}
+	var opts core.ReconcileOptions
+	for _, flag := range []struct {
+		name    string
+		balance *decimal.NullDecimal
+	}{{"opening", &opts.Opening}, {"closing", &opts.Closing}} {
+		if !cmd.Flags().Changed(flag.name) {
+			continue
+		}
+		value, _ := cmd.Flags().GetString(flag.name)
+		amount, err := decimal.NewFromString(value)
+		if err != nil {
+			return fmt.Errorf("invalid --%s %q: %w", flag.name, value, err)
+		}
+		*flag.balance = decimal.NewNullDecimal(amount)
+	}
+
+	store, err := getStore()
+	if err != nil {
+		return fmt.Errorf("failed to get database store: %w", err)
+	}
+	defer store.Close()
+
+	account, err := resolveAccount(cmd.Context(), store, args[0])
+	if err != nil {
+		return err
+	}
+	result, err := core.Reconcile(cmd.Context(), store, *account, start, end, opts)
+	if errors.Is(err, core.ErrNoBalance) {
+		return fmt.Errorf("%w; import a statement with balances for the period or give --opening and --closing", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	if format == outputFormatJSON {
+		if err := printJSON(result); err != nil {
+			return err
+		}
+	} else {
+		printReconciliation(period, start, end, result)
+	}
+	if !result.OK() {
+		return fmt.Errorf("%s does not reconcile for %s, discrepancy %s %s",
+			account.Name, period, result.Discrepancy.StringFixed(2), account.Currency)
+	}
+	return nil
+}
+
+// printReconciliation prints the balances, the discrepancy and the candidates of a reconciliation
+func printReconciliation(period string, start, end time.Time, result *core.Reconciliation) {
+	currency := result.Account.Currency
+	last := result.To.AddDate(0, 0, -1)
+	fmt.Printf("Reconciling %s for %s\n", result.Account.Name, period)
+	if !result.From.Equal(start) || !result.To.Equal(end) {
+		fmt.Printf("⚠️  The statement balances cover %s to %s of the period only\n",
+			result.From.Format("2006-01-02"), last.Format("2006-01-02"))
+	}
+	fmt.Println()
+	fmt.Printf("Opening balance %s: %14s %s\n", result.From.Format("2006-01-02"), result.Opening.StringFixed(2), currency)
+	fmt.Printf("%-26s %14s %s\n", fmt.Sprintf("Transactions (%d):", result.Transactions), result.Sum.StringFixed(2), currency)
+	fmt.Printf("Closing balance %s: %14s %s\n", last.Format("2006-01-02"), result.Closing.StringFixed(2), currency)
+
+	if result.OK() {
+		fmt.Printf("\n✅ The transactions agree with the balances\n")
+		return
+	}
+	fmt.Printf("\n❌ Discrepancy: %s %s\n", result.Discrepancy.StringFixed(2), currency)
+
+	for _, list := range []struct {
+		title      string
+		candidates []core.ReconcileCandidate
+	}{
+		{"Possibly missing", result.Missing},
+		{"Possibly duplicated", result.Duplicated},
+	} {
+		if len(list.candidates) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s:\n", list.title)
+		table := newTable()
+		table.SetHeader([]string{"", "ID", "Date", "Description", "Amount", "Reason"})
+		for _, candidate := range list.candidates {
+			mark, id := "", "-"
+			if candidate.ClosesGap {
+				mark = "✱"
+			}
+			if candidate.TransactionID != 0 {
+				id = fmt.Sprintf("%d", candidate.TransactionID)
+			}
+			table.Append([]string{
+				mark,
+				id,
+				candidate.Date.Format("2006-01-02"),
+				valueOrDash(candidate.Description),
+				candidate.Amount.StringFixed(2),
+				candidate.Reason,
+			})
+		}
+		table.Render()
+	}
+	if len(result.Missing) == 0 && len(result.Duplicated) == 0 {
+		fmt.Println("No candidate transactions found, the statement may contain transactions that were never imported")
+	}
+}
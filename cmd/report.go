@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lindehoff/Budget-Assist/internal/core"
+	"github.com/spf13/cobra"
+)
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report income and expenses",
+}
+
+// reportSummaryCmd represents the report summary subcommand
+var reportSummaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Summarize income and expenses by category in the base currency",
+	Long: `Summarize the income and expenses of a period by category. Amounts in other
+currencies are converted to the base currency (config currency.base) with the
+exchange rate of their booking day. Transfers between your own accounts and
+flagged duplicates are left out.
+
+Transactions without an exchange rate are not part of the totals and are
+listed by currency; import the rates with 'budgetassist exchange import'.`,
+	Example: `  budgetassist report summary --period 2026-09
+  budgetassist report summary --period 2026 --account "SEB Lönekonto" --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != outputFormatTable && format != outputFormatJSON {
+			return fmt.Errorf("unsupported format: %s", format)
+		}
+		period, _ := cmd.Flags().GetString("period")
+		start, end, err := core.ParsePeriod(period)
+		if err != nil {
+			return fmt.Errorf("invalid --period %q: expected YYYY-MM or YYYY", period)
+		}
+		base, err := baseCurrency()
+		if err != nil {
+			return err
+		}
+
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		opts := core.SummaryOptions{BaseCurrency: base}
+		if ref, _ := cmd.Flags().GetString("account"); ref != "" {
+			account, err := resolveAccount(cmd.Context(), store, ref)
+			if err != nil {
+				return err
+			}
+			opts.AccountIDs = []uint{account.ID}
+		}
+		summary, err := core.Summarize(cmd.Context(), store, start, end, opts)
+		if err != nil {
+			return err
+		}
+
+		if format == outputFormatJSON {
+			return printJSON(summary)
+		}
+		printSummary(period, summary)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportSummaryCmd)
+
+	reportSummaryCmd.Flags().String("period", "", "Month (YYYY-MM) or year (YYYY) to summarize (required)")
+	reportSummaryCmd.Flags().String("account", "", "Only summarize transactions of this account (ID or name)")
+	reportSummaryCmd.Flags().String("format", outputFormatTable, "Output format (table, json)")
+	_ = reportSummaryCmd.MarkFlagRequired("period")
+}
+
+// printSummary prints the income and expenses of a summary by category
+func printSummary(period string, summary *core.Summary) {
+	base := summary.BaseCurrency
+	fmt.Printf("Summary for %s in %s (%d transactions)\n\n", period, base, summary.Transactions)
+	if len(summary.Categories) > 0 {
+		table := newTable()
+		table.SetHeader([]string{"Category", "Transactions", "Income", "Expenses", "Net"})
+		for _, category := range summary.Categories {
+			table.Append([]string{
+				valueOrDash(category.Category),
+				fmt.Sprintf("%d", category.Transactions),
+				category.Income.StringFixed(2),
+				category.Expenses.StringFixed(2),
+				category.Income.Add(category.Expenses).StringFixed(2),
+			})
+		}
+		table.Render()
+		fmt.Println()
+	}
+	fmt.Printf("Income:   %14s %s\n", summary.Income.StringFixed(2), base)
+	fmt.Printf("Expenses: %14s %s\n", summary.Expenses.StringFixed(2), base)
+	fmt.Printf("Net:      %14s %s\n", summary.Net.StringFixed(2), base)
+
+	if len(summary.Unconverted) == 0 {
+		return
+	}
+	currencies := make([]string, 0, len(summary.Unconverted))
+	for currency := range summary.Unconverted {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	fmt.Printf("\n⚠️  Not included, no exchange rate to %s:\n", base)
+	for _, currency := range currencies {
+		fmt.Printf("    %s %s\n", summary.Unconverted[currency].StringFixed(2), currency)
+	}
+}
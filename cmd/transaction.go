@@ -0,0 +1,644 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/core"
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+// categoryNone removes the category of a transaction when passed to --category
+const categoryNone = "none"
+
+// transactionCmd represents the transaction command
+var transactionCmd = &cobra.Command{
+	Use:     "transaction",
+	Aliases: []string{"transactions", "tx"},
+	Short:   "Manage stored transactions",
+	Long: `List, search, show, edit and delete the transactions stored by imports and
+document processing.`,
+}
+
+// transactionListCmd represents the transaction list subcommand
+var transactionListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored transactions",
+	Long: `List the stored transactions, newest first. Amounts are in the currency of the
+transaction; --min-amount and --max-amount compare in that currency too, with
+expenses being negative.`,
+	Example: `  budgetassist transaction list --period 2026-09
+  budgetassist transaction list --from 2026-09-01 --to 2026-09-15 --account "SEB Lönekonto"
+  budgetassist transaction list --category Mat --max-amount -500 --format csv > mat.csv`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTransactionList(cmd, "")
+	},
+}
+
+// transactionSearchCmd represents the transaction search subcommand
+var transactionSearchCmd = &cobra.Command{
+	Use:   "search <text>",
+	Short: "Search transactions by description or reference",
+	Long: `List the transactions whose description or reference contains the text,
+ignoring case. Takes the same filters as 'budgetassist transaction list'.`,
+	Example: `  budgetassist transaction search ica
+  budgetassist transaction search "spotify" --period 2026 --format json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTransactionList(cmd, args[0])
+	},
+}
+
+// transactionShowCmd represents the transaction show subcommand
+var transactionShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a transaction with its raw data and AI analysis",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != outputFormatTable && format != outputFormatJSON {
+			return fmt.Errorf("unsupported format: %s", format)
+		}
+		id, err := parseID(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid transaction ID: %w", err)
+		}
+
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		tx, err := lookupTransaction(cmd.Context(), store, id)
+		if err != nil {
+			return err
+		}
+		if format == outputFormatJSON {
+			return printJSON(tx)
+		}
+		names, err := newTransactionNames(cmd.Context(), store)
+		if err != nil {
+			return err
+		}
+		printTransaction(tx, names)
+		return nil
+	},
+}
+
+// transactionEditCmd represents the transaction edit subcommand
+var transactionEditCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Change the category, description or amount of a transaction",
+	Long: `Change the category, description or amount of a stored transaction. Only the
+given flags are changed. Setting --category without --subcategory removes the
+subcategory; --category none removes both.
+
+A new amount is converted to the base currency again. The amount of a transfer
+between your own accounts cannot be changed; unlink it with
+'budgetassist transfer unlink' first.`,
+	Example: `  budgetassist transaction edit 42 --category Mat --subcategory Livsmedel
+  budgetassist transaction edit 42 --description "ICA Maxi Lindhagen" --amount -349.90
+  budgetassist transaction edit 42 --category none`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid transaction ID: %w", err)
+		}
+		flags := cmd.Flags()
+		if !flags.Changed("description") && !flags.Changed("amount") && !flags.Changed("category") && !flags.Changed("subcategory") {
+			return fmt.Errorf("nothing to change, use --description, --amount, --category or --subcategory")
+		}
+
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		update, err := transactionUpdate(cmd.Context(), store, id, cmd)
+		if err != nil {
+			return err
+		}
+		tx, err := core.EditTransaction(cmd.Context(), store, id, update)
+		if err != nil {
+			return err
+		}
+
+		names, err := newTransactionNames(cmd.Context(), store)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Transaction %d updated\n\n", tx.ID)
+		printTransaction(tx, names)
+		return nil
+	},
+}
+
+// transactionDeleteCmd represents the transaction delete subcommand
+var transactionDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a transaction",
+	Long: `Delete a stored transaction. When it is part of a transfer, the other side
+is unlinked and counts as income or expense again. Transactions flagged as
+duplicates of it are no longer flagged.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid transaction ID: %w", err)
+		}
+
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		tx, err := lookupTransaction(cmd.Context(), store, id)
+		if err != nil {
+			return err
+		}
+
+		// Ask for confirmation unless --force is used
+		force, _ := cmd.Flags().GetBool("force")
+		if !force {
+			fmt.Printf("Are you sure you want to delete transaction %d (%s, %q, %s)? [y/N] ",
+				tx.ID, tx.Date.Format("2006-01-02"), tx.Description, tx.FormatAmount())
+			var response string
+			_, err := fmt.Scanln(&response)
+			if err != nil {
+				return fmt.Errorf("failed to read response: %w", err)
+			}
+			if response != "y" && response != "Y" {
+				fmt.Println("Operation cancelled")
+				return nil
+			}
+		}
+
+		if err := store.DeleteTransaction(cmd.Context(), tx.ID); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Transaction %d deleted\n", tx.ID)
+		if tx.IsTransfer() {
+			fmt.Printf("Transaction %d is no longer part of a transfer\n", *tx.TransferOfID)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(transactionCmd)
+	transactionCmd.AddCommand(transactionListCmd)
+	transactionCmd.AddCommand(transactionSearchCmd)
+	transactionCmd.AddCommand(transactionShowCmd)
+	transactionCmd.AddCommand(transactionEditCmd)
+	transactionCmd.AddCommand(transactionDeleteCmd)
+
+	addTransactionFilterFlags(transactionListCmd)
+	transactionListCmd.Flags().String("text", "", "Only list transactions whose description or reference contains the text")
+	addTransactionFilterFlags(transactionSearchCmd)
+
+	transactionShowCmd.Flags().StringP("format", "f", outputFormatTable, "Output format (table|json)")
+
+	transactionEditCmd.Flags().String("description", "", "New description")
+	transactionEditCmd.Flags().String("amount", "", "New amount in the currency of the transaction, negative for expenses")
+	transactionEditCmd.Flags().String("category", "", "New category (ID or name), or \"none\" to remove it")
+	transactionEditCmd.Flags().String("subcategory", "", "New subcategory (ID or name)")
+
+	transactionDeleteCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+}
+
+// addTransactionFilterFlags adds the flags shared by transaction list and search
+func addTransactionFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().String("from", "", "Only list transactions booked on or after the date (YYYY-MM-DD)")
+	cmd.Flags().String("to", "", "Only list transactions booked on or before the date (YYYY-MM-DD)")
+	cmd.Flags().String("period", "", "Only list transactions of this month (YYYY-MM) or year (YYYY)")
+	cmd.Flags().String("category", "", "Only list transactions of this category (ID or name)")
+	cmd.Flags().String("account", "", "Only list transactions of this account (ID or name)")
+	cmd.Flags().String("min-amount", "", "Only list transactions with at least this amount")
+	cmd.Flags().String("max-amount", "", "Only list transactions with at most this amount")
+	cmd.Flags().Int("limit", 0, "Maximum number of transactions to list, 0 for all")
+	cmd.Flags().StringP("format", "f", outputFormatTable, "Output format (table|json|csv)")
+	cmd.MarkFlagsMutuallyExclusive("period", "from")
+	cmd.MarkFlagsMutuallyExclusive("period", "to")
+}
+
+// runTransactionList lists the transactions matching the filter flags and text
+func runTransactionList(cmd *cobra.Command, text string) error {
+	format, _ := cmd.Flags().GetString("format")
+	if format != outputFormatTable && format != outputFormatJSON && format != outputFormatCSV {
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+	limit, _ := cmd.Flags().GetInt("limit")
+	if limit < 0 {
+		return fmt.Errorf("invalid --limit %d: must not be negative", limit)
+	}
+
+	store, err := getStore()
+	if err != nil {
+		return fmt.Errorf("failed to get database store: %w", err)
+	}
+	defer store.Close()
+
+	filter, err := transactionFilter(cmd.Context(), store, cmd)
+	if err != nil {
+		return err
+	}
+	if text != "" {
+		filter.Text = text
+	}
+	transactions, err := core.ListTransactions(cmd.Context(), store, filter)
+	if err != nil {
+		return err
+	}
+	if limit > 0 && len(transactions) > limit {
+		transactions = transactions[:limit]
+	}
+
+	if format == outputFormatJSON {
+		return printJSON(transactions)
+	}
+	names, err := newTransactionNames(cmd.Context(), store)
+	if err != nil {
+		return err
+	}
+	if format == outputFormatCSV {
+		return writeTransactionsCSV(transactions, names)
+	}
+	if len(transactions) == 0 {
+		fmt.Println("No transactions found")
+		return nil
+	}
+	printTransactions(transactions, names)
+	return nil
+}
+
+// transactionFilter builds the store filter from the list and search flags
+func transactionFilter(ctx context.Context, store db.Store, cmd *cobra.Command) (*db.TransactionFilter, error) {
+	flags := cmd.Flags()
+	filter := &db.TransactionFilter{}
+	if period, _ := flags.GetString("period"); period != "" {
+		start, end, err := core.ParsePeriod(period)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --period %q: expected YYYY-MM or YYYY", period)
+		}
+		last := end.AddDate(0, 0, -1)
+		filter.StartDate, filter.EndDate = &start, &last
+	}
+	for name, target := range map[string]**time.Time{"from": &filter.StartDate, "to": &filter.EndDate} {
+		value, _ := flags.GetString(name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s %q: expected YYYY-MM-DD", name, value)
+		}
+		*target = &date
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		return nil, fmt.Errorf("--to %s is before --from %s", filter.EndDate.Format("2006-01-02"), filter.StartDate.Format("2006-01-02"))
+	}
+
+	for name, target := range map[string]**decimal.Decimal{"min-amount": &filter.MinAmount, "max-amount": &filter.MaxAmount} {
+		value, _ := flags.GetString(name)
+		if value == "" {
+			continue
+		}
+		amount, err := decimal.NewFromString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s %q: expected a number", name, value)
+		}
+		*target = &amount
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && filter.MaxAmount.LessThan(*filter.MinAmount) {
+		return nil, fmt.Errorf("--max-amount %s is less than --min-amount %s", filter.MaxAmount, filter.MinAmount)
+	}
+
+	if ref, _ := flags.GetString("category"); ref != "" {
+		category, err := resolveCategory(ctx, store, ref)
+		if err != nil {
+			return nil, err
+		}
+		filter.CategoryID = &category.ID
+	}
+	if ref, _ := flags.GetString("account"); ref != "" {
+		account, err := resolveAccount(ctx, store, ref)
+		if err != nil {
+			return nil, err
+		}
+		filter.AccountIDs = []uint{account.ID}
+	}
+	if flags.Lookup("text") != nil {
+		filter.Text, _ = flags.GetString("text")
+	}
+	return filter, nil
+}
+
+// transactionUpdate builds the changes of transaction edit from the flags that were set
+func transactionUpdate(ctx context.Context, store db.Store, id uint, cmd *cobra.Command) (core.TransactionUpdate, error) {
+	flags := cmd.Flags()
+	var update core.TransactionUpdate
+	if flags.Changed("description") {
+		description, _ := flags.GetString("description")
+		update.Description = &description
+	}
+	if flags.Changed("amount") {
+		value, _ := flags.GetString("amount")
+		amount, err := decimal.NewFromString(value)
+		if err != nil {
+			return update, fmt.Errorf("invalid --amount %q: expected a number", value)
+		}
+		update.Amount = &amount
+	}
+	if !flags.Changed("category") && !flags.Changed("subcategory") {
+		return update, nil
+	}
+
+	update.SetCategory = true
+	if flags.Changed("category") {
+		ref, _ := flags.GetString("category")
+		if ref != "" && !strings.EqualFold(ref, categoryNone) {
+			category, err := resolveCategory(ctx, store, ref)
+			if err != nil {
+				return update, err
+			}
+			update.CategoryID = &category.ID
+		}
+	} else {
+		// Only the subcategory changes, keep the category
+		tx, err := lookupTransaction(ctx, store, id)
+		if err != nil {
+			return update, err
+		}
+		update.CategoryID = tx.CategoryID
+	}
+	if ref, _ := flags.GetString("subcategory"); ref != "" {
+		subcategory, err := resolveSubcategory(ctx, store, ref)
+		if err != nil {
+			return update, err
+		}
+		update.SubcategoryID = &subcategory.ID
+	}
+	return update, nil
+}
+
+// lookupTransaction looks up a transaction by ID
+func lookupTransaction(ctx context.Context, store db.Store, id uint) (*db.Transaction, error) {
+	tx, err := store.GetTransactionByID(ctx, id)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, fmt.Errorf("transaction %d not found, see 'budgetassist transaction list'", id)
+	}
+	return tx, err
+}
+
+// resolveCategory finds a category by ID or name
+func resolveCategory(ctx context.Context, store db.Store, ref string) (*db.Category, error) {
+	if id, err := parseID(ref); err == nil {
+		category, err := store.GetCategoryByID(ctx, id)
+		if err == nil {
+			return category, nil
+		}
+		if !errors.Is(err, db.ErrNotFound) {
+			return nil, err
+		}
+	}
+	category, err := store.GetCategoryByName(ctx, ref)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, fmt.Errorf("category %q not found, see 'budgetassist category list'", ref)
+	}
+	return category, err
+}
+
+// resolveSubcategory finds a subcategory by ID or name
+func resolveSubcategory(ctx context.Context, store db.Store, ref string) (*db.Subcategory, error) {
+	if id, err := parseID(ref); err == nil {
+		subcategory, err := store.GetSubcategoryByID(ctx, id)
+		if err == nil {
+			return subcategory, nil
+		}
+		if !errors.Is(err, db.ErrNotFound) {
+			return nil, err
+		}
+	}
+	subcategory, err := store.GetSubcategoryByName(ctx, ref)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, fmt.Errorf("subcategory %q not found, see 'budgetassist category list'", ref)
+	}
+	return subcategory, err
+}
+
+// transactionNames holds the names of the accounts, categories and
+// subcategories transactions refer to by ID
+type transactionNames struct {
+	accounts      accountNameMap
+	categories    map[uint]string
+	subcategories map[uint]string
+}
+
+// newTransactionNames loads the names of all accounts, categories and subcategories
+func newTransactionNames(ctx context.Context, store db.Store) (*transactionNames, error) {
+	accounts, err := accountNames(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := store.ListCategories(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	subcategories, err := store.ListSubcategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := &transactionNames{
+		accounts:      accounts,
+		categories:    make(map[uint]string, len(categories)),
+		subcategories: make(map[uint]string, len(subcategories)),
+	}
+	for _, category := range categories {
+		names.categories[category.ID] = category.Name
+	}
+	for _, subcategory := range subcategories {
+		names.subcategories[subcategory.ID] = subcategory.Name
+	}
+	return names, nil
+}
+
+// category returns the category and subcategory of the transaction, e.g.
+// "Mat / Livsmedel", or an empty string when it has none
+func (n *transactionNames) category(tx *db.Transaction) string {
+	if tx.CategoryID == nil {
+		return ""
+	}
+	name, ok := n.categories[*tx.CategoryID]
+	if !ok {
+		name = fmt.Sprintf("category %d", *tx.CategoryID)
+	}
+	if tx.SubcategoryID == nil {
+		return name
+	}
+	subcategory, ok := n.subcategories[*tx.SubcategoryID]
+	if !ok {
+		subcategory = fmt.Sprintf("subcategory %d", *tx.SubcategoryID)
+	}
+	return name + " / " + subcategory
+}
+
+// transactionStatus describes whether the transaction is a transfer or a duplicate
+func transactionStatus(tx *db.Transaction) string {
+	var status []string
+	if tx.IsTransfer() {
+		status = append(status, fmt.Sprintf("transfer with %d (%s)", *tx.TransferOfID, tx.TransferStatus))
+	}
+	if tx.DuplicateOfID != nil {
+		status = append(status, fmt.Sprintf("duplicate of %d", *tx.DuplicateOfID))
+	}
+	return strings.Join(status, ", ")
+}
+
+// formatBaseAmount formats the amount of the transaction in the base currency, or
+// returns an empty string when it was not converted
+func formatBaseAmount(tx *db.Transaction) string {
+	if !tx.BaseAmount.Valid {
+		return ""
+	}
+	return fmt.Sprintf("%s %s", tx.BaseAmount.Decimal.StringFixed(2), tx.BaseCurrency)
+}
+
+// printTransactions prints the transactions as a table
+func printTransactions(transactions []db.Transaction, names *transactionNames) {
+	table := newTable()
+	table.SetHeader([]string{"ID", "Date", "Account", "Description", "Amount", "Base Amount", "Category", "Status"})
+	total := make(map[string]decimal.Decimal)
+	var currencies []string
+	for i := range transactions {
+		tx := &transactions[i]
+		table.Append([]string{
+			fmt.Sprintf("%d", tx.ID),
+			tx.Date.Format("2006-01-02"),
+			names.accounts.of(tx.AccountID),
+			tx.Description,
+			fmt.Sprintf("%s %s", tx.Amount.StringFixed(2), tx.Currency),
+			valueOrDash(formatBaseAmount(tx)),
+			valueOrDash(names.category(tx)),
+			valueOrDash(transactionStatus(tx)),
+		})
+		if _, ok := total[tx.Currency]; !ok {
+			currencies = append(currencies, tx.Currency)
+		}
+		total[tx.Currency] = total[tx.Currency].Add(tx.Amount)
+	}
+	table.Render()
+
+	sums := make([]string, 0, len(currencies))
+	for _, currency := range currencies {
+		sums = append(sums, fmt.Sprintf("%s %s", total[currency].StringFixed(2), currency))
+	}
+	fmt.Printf("%d transactions, total %s\n", len(transactions), strings.Join(sums, ", "))
+}
+
+// writeTransactionsCSV writes the transactions as CSV to standard output
+func writeTransactionsCSV(transactions []db.Transaction, names *transactionNames) error {
+	w := csv.NewWriter(os.Stdout)
+	if err := w.Write([]string{"id", "date", "account", "description", "reference", "amount", "currency",
+		"base_amount", "base_currency", "category", "status"}); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for i := range transactions {
+		tx := &transactions[i]
+		base := ""
+		if tx.BaseAmount.Valid {
+			base = tx.BaseAmount.Decimal.StringFixed(2)
+		}
+		account := ""
+		if tx.AccountID != nil {
+			account = names.accounts.of(tx.AccountID)
+		}
+		if err := w.Write([]string{
+			fmt.Sprintf("%d", tx.ID),
+			tx.Date.Format("2006-01-02"),
+			account,
+			tx.Description,
+			tx.Reference,
+			tx.Amount.StringFixed(2),
+			tx.Currency,
+			base,
+			tx.BaseCurrency,
+			names.category(tx),
+			transactionStatus(tx),
+		}); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// printTransaction prints all details of a transaction, including its raw data and AI analysis
+func printTransaction(tx *db.Transaction, names *transactionNames) {
+	date := func(value time.Time) string {
+		if value.IsZero() {
+			return "-"
+		}
+		return value.Format("2006-01-02")
+	}
+
+	fmt.Printf("Transaction %d: %s\n", tx.ID, tx.Description)
+	fmt.Printf("Date:             %s\n", date(tx.Date))
+	fmt.Printf("Transaction date: %s\n", date(tx.TransactionDate))
+	fmt.Printf("Value date:       %s\n", date(tx.ValueDate))
+	fmt.Printf("Amount:           %s %s\n", tx.Amount.StringFixed(2), tx.Currency)
+	fmt.Printf("Base amount:      %s\n", valueOrDash(formatBaseAmount(tx)))
+	balance := "-"
+	if tx.Balance.Valid {
+		balance = tx.Balance.Decimal.StringFixed(2)
+	}
+	fmt.Printf("Balance:          %s\n", balance)
+	fmt.Printf("Account:          %s\n", names.accounts.of(tx.AccountID))
+	fmt.Printf("Category:         %s\n", valueOrDash(names.category(tx)))
+	fmt.Printf("Reference:        %s\n", valueOrDash(tx.Reference))
+	fmt.Printf("Status:           %s\n", valueOrDash(transactionStatus(tx)))
+	fmt.Printf("Source:           %s\n", valueOrDash(tx.Source))
+	if tx.SourceContainer != "" {
+		fmt.Printf("Container:        %s\n", tx.SourceContainer)
+		fmt.Printf("Entry:            %s\n", valueOrDash(tx.SourceEntry))
+	}
+	if tx.MessageSubject != "" || tx.MessageSender != "" {
+		fmt.Printf("Email:            %q from %s\n", tx.MessageSubject, valueOrDash(tx.MessageSender))
+	}
+	if tx.ImportBatchID != nil {
+		fmt.Printf("Import batch:     %d\n", *tx.ImportBatchID)
+	}
+	fmt.Printf("Fingerprint:      %s\n", valueOrDash(tx.Fingerprint))
+
+	if tx.RawData != "" {
+		fmt.Printf("\nRaw data:\n%s\n", indentJSON(tx.RawData))
+	}
+	if tx.AIAnalysis != "" {
+		fmt.Printf("\nAI analysis:\n%s\n", indentJSON(tx.AIAnalysis))
+	}
+}
+
+// indentJSON indents the value when it is JSON and returns it unchanged otherwise
+func indentJSON(value string) string {
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(value), "", "  "); err != nil {
+		return value
+	}
+	return out.String()
+}
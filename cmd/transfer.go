@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/core"
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// transferCmd represents the transfer command
+var transferCmd = &cobra.Command{
+	Use:   "transfer",
+	Short: "Manage transfers between your own accounts",
+	Long: `Manage transfers between your own accounts.
+
+Moving money from one account to another shows up as an expense on one account
+and as income on the other. Transactions paired as a transfer are neither, and
+are left out of income and expense totals. Transfers are referred to by the ID
+of either of their transactions.`,
+}
+
+// transferDetectCmd represents the transfer detect subcommand
+var transferDetectCmd = &cobra.Command{
+	Use:   "detect",
+	Short: "Pair transactions that move money between your own accounts",
+	Long: `Pair each outgoing transaction with an incoming transaction of the same
+amount and currency on another account, booked within --window days of it.
+Transactions without an account and transfers unlinked before are left alone.`,
+	Example: `  budgetassist transfer detect --dry-run
+  budgetassist transfer detect --period 2026-09 --window 5`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		window, _ := cmd.Flags().GetInt("window")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		opts := core.TransferOptions{Window: window}
+		if period, _ := cmd.Flags().GetString("period"); period != "" {
+			start, end, err := core.ParsePeriod(period)
+			if err != nil {
+				return fmt.Errorf("invalid --period %q: expected YYYY-MM or YYYY", period)
+			}
+			last := end.AddDate(0, 0, -1)
+			opts.StartDate, opts.EndDate = &start, &last
+		}
+
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		pairs, err := core.DetectTransfers(cmd.Context(), store, opts)
+		if err != nil {
+			return err
+		}
+		if len(pairs) == 0 {
+			fmt.Println("No transfers found")
+			return nil
+		}
+		if !dryRun {
+			if err := core.LinkTransfers(cmd.Context(), store, pairs); err != nil {
+				return err
+			}
+		}
+
+		if err := printTransfers(cmd.Context(), store, pairs); err != nil {
+			return err
+		}
+		if dryRun {
+			fmt.Printf("\nDry run: found %d transfers, nothing was changed\n", len(pairs))
+			return nil
+		}
+		fmt.Printf("\nLinked %d transfers. Confirm them with 'budgetassist transfer confirm <id>' or undo one with 'budgetassist transfer unlink <id>'\n", len(pairs))
+		return nil
+	},
+}
+
+// transferListCmd represents the transfer list subcommand
+var transferListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List transfers",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		store, err := getStore()
+		if err != nil {
+			return fmt.Errorf("failed to get database store: %w", err)
+		}
+		defer store.Close()
+
+		filter := &db.TransactionFilter{}
+		if ref, _ := cmd.Flags().GetString("account"); ref != "" {
+			account, err := resolveAccount(cmd.Context(), store, ref)
+			if err != nil {
+				return err
+			}
+			filter.AccountIDs = []uint{account.ID}
+		}
+		pairs, err := core.ListTransfers(cmd.Context(), store, filter)
+		if err != nil {
+			return err
+		}
+
+		switch format {
+		case outputFormatJSON:
+			return printJSON(pairs)
+		case outputFormatTable:
+		default:
+			return fmt.Errorf("unsupported format: %s", format)
+		}
+		if len(pairs) == 0 {
+			fmt.Println("No transfers. Find them with: budgetassist transfer detect")
+			return nil
+		}
+		return printTransfers(cmd.Context(), store, pairs)
+	},
+}
+
+// transferLinkCmd represents the transfer link subcommand
+var transferLinkCmd = &cobra.Command{
+	Use:     "link <transaction-id> <transaction-id>",
+	Short:   "Pair two transactions as a transfer",
+	Example: `  budgetassist transfer link 1042 1057`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		otherID, err := parseID(args[1])
+		if err != nil {
+			return err
+		}
+		return changeTransfer(cmd, "Linked", func(ctx context.Context, store db.Store) (*core.TransferPair, error) {
+			return core.LinkTransfer(ctx, store, id, otherID)
+		})
+	},
+}
+
+// transferConfirmCmd represents the transfer confirm subcommand
+var transferConfirmCmd = &cobra.Command{
+	Use:   "confirm <transaction-id>",
+	Short: "Confirm a detected transfer",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		return changeTransfer(cmd, "Confirmed", func(ctx context.Context, store db.Store) (*core.TransferPair, error) {
+			return core.ConfirmTransfer(ctx, store, id)
+		})
+	},
+}
+
+// transferUnlinkCmd represents the transfer unlink subcommand
+var transferUnlinkCmd = &cobra.Command{
+	Use:   "unlink <transaction-id>",
+	Short: "Make the transactions of a transfer income and expense again",
+	Long: `Unlink the transfer a transaction is part of. Both transactions count as
+income and expense again and are not paired by 'budgetassist transfer detect'
+anymore; pair them by hand with 'budgetassist transfer link'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		return changeTransfer(cmd, "Unlinked", func(ctx context.Context, store db.Store) (*core.TransferPair, error) {
+			return core.UnlinkTransfer(ctx, store, id)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(transferCmd)
+	transferCmd.AddCommand(transferDetectCmd)
+	transferCmd.AddCommand(transferListCmd)
+	transferCmd.AddCommand(transferLinkCmd)
+	transferCmd.AddCommand(transferConfirmCmd)
+	transferCmd.AddCommand(transferUnlinkCmd)
+
+	transferDetectCmd.Flags().Int("window", core.DefaultTransferWindow, "Most days between the outgoing and the incoming transaction")
+	transferDetectCmd.Flags().String("period", "", "Only pair outgoing transactions of this month (YYYY-MM) or year (YYYY)")
+	transferDetectCmd.Flags().Bool("dry-run", false, "Show the transfers found without linking them")
+	transferListCmd.Flags().String("account", "", "Only list transfers from this account (ID or name)")
+	transferListCmd.Flags().String("format", outputFormatTable, "Output format (table, json)")
+}
+
+// changeTransfer runs a change to a transfer and prints the transfer
+func changeTransfer(cmd *cobra.Command, verb string, change func(ctx context.Context, store db.Store) (*core.TransferPair, error)) error {
+	store, err := getStore()
+	if err != nil {
+		return fmt.Errorf("failed to get database store: %w", err)
+	}
+	defer store.Close()
+
+	pair, err := change(cmd.Context(), store)
+	if err != nil {
+		return err
+	}
+	names, err := accountNames(cmd.Context(), store)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s transfer of %s %s from %s (%d) to %s (%d)\n", verb,
+		pair.To.Amount.StringFixed(2), pair.To.Currency,
+		names.of(pair.From.AccountID), pair.From.ID,
+		names.of(pair.To.AccountID), pair.To.ID)
+	return nil
+}
+
+// printTransfers prints a table of transfers
+func printTransfers(ctx context.Context, store db.Store, pairs []core.TransferPair) error {
+	names, err := accountNames(ctx, store)
+	if err != nil {
+		return err
+	}
+	table := newTable()
+	table.SetHeader([]string{"From ID", "To ID", "Date", "From", "To", "Amount", "Days", "Status"})
+	for _, pair := range pairs {
+		days := int(pair.To.Date.Sub(pair.From.Date).Round(24*time.Hour) / (24 * time.Hour))
+		table.Append([]string{
+			fmt.Sprintf("%d", pair.From.ID),
+			fmt.Sprintf("%d", pair.To.ID),
+			pair.From.Date.Format("2006-01-02"),
+			names.of(pair.From.AccountID),
+			names.of(pair.To.AccountID),
+			fmt.Sprintf("%s %s", pair.To.Amount.StringFixed(2), pair.To.Currency),
+			fmt.Sprintf("%+d", days),
+			valueOrDash(pair.Status()),
+		})
+	}
+	table.Render()
+	return nil
+}
+
+// accountNameMap maps account IDs to names
+type accountNameMap map[uint]string
+
+// of returns the name of the account, or a dash when there is none
+func (m accountNameMap) of(id *uint) string {
+	if id == nil {
+		return "-"
+	}
+	if name, ok := m[*id]; ok {
+		return name
+	}
+	return fmt.Sprintf("account %d", *id)
+}
+
+// accountNames returns the names of all accounts by ID
+func accountNames(ctx context.Context, store db.Store) (accountNameMap, error) {
+	accounts, err := store.ListAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(accountNameMap, len(accounts))
+	for _, account := range accounts {
+		names[account.ID] = account.Name
+	}
+	return names, nil
+}
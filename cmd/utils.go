@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/lindehoff/Budget-Assist/internal/ai"
 	"github.com/lindehoff/Budget-Assist/internal/db"
@@ -47,6 +48,24 @@ func getAIService() (ai.Service, error) {
 	return ai.NewOpenAIService(config, store, slog.Default()), nil
 }
 
+// currencyCode returns the upper-case ISO 4217 code of a currency given by the
+// user, SEK when empty
+func currencyCode(value, name string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(value))
+	if code == "" {
+		return db.CurrencySEK, nil
+	}
+	if !db.IsCurrencyCode(code) {
+		return "", fmt.Errorf("invalid %s %q: not an ISO 4217 currency code", name, value)
+	}
+	return code, nil
+}
+
+// baseCurrency returns the configured currency transactions are converted to for reports
+func baseCurrency() (string, error) {
+	return currencyCode(viper.GetString("currency.base"), "currency.base")
+}
+
 // printJSON prints data as formatted JSON
 func printJSON(data interface{}) error {
 	jsonData, err := json.MarshalIndent(data, "", "  ")
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/pipeline"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch <dir>",
+	Short: "Process documents dropped into a folder",
+	Long: `Watch a folder and process the documents that are dropped into it.
+
+Documents already in the folder are processed when watching starts. A new
+document is processed once it has not changed for the debounce period, so
+files that are still being copied or downloaded are left alone. Processed
+documents are moved to the "processed" subfolder and documents that could not
+be processed to the "failed" subfolder. Documents that were processed before
+with the same content are moved to "processed" without being imported again.
+
+Every document is processed as a processing job of its own, see
+"budgetassist jobs". Stop watching with Ctrl-C.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWatch,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	addProcessFlags(watchCmd)
+	watchCmd.Flags().Duration("debounce", pipeline.DefaultWatchDebounce, "How long a file must stay unchanged before it is processed")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	dir := args[0]
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("directory does not exist: %s", dir)
+	}
+	debounce, _ := cmd.Flags().GetDuration("debounce")
+	if debounce <= 0 {
+		return fmt.Errorf("invalid --debounce: must be positive")
+	}
+	opts, err := processOptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Use global logger configured in root command
+	logger := slog.Default()
+
+	// Get database connection from global config
+	store, err := getStore()
+	if err != nil {
+		return fmt.Errorf("failed to get database store: %w", err)
+	}
+	defer store.Close()
+
+	p, err := newProcessPipeline(cmd, store, logger)
+	if err != nil {
+		return err
+	}
+	if err := applyCategoryMapping(cmd, store, &opts); err != nil {
+		return err
+	}
+	if err := applyAccount(cmd, store, &opts); err != nil {
+		return err
+	}
+
+	fmt.Printf("Watching %s (Ctrl-C to stop)\n", dir)
+	err = p.Watch(cmd.Context(), dir, pipeline.WatchOptions{
+		Process:  opts,
+		Debounce: debounce,
+		OnResult: printWatchResult,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch folder: %w", err)
+	}
+	fmt.Println("Stopped watching")
+	return nil
+}
+
+// printWatchResult prints the outcome of each document of a file picked up by watch
+func printWatchResult(result pipeline.WatchResult) {
+	timestamp := time.Now().Format("15:04:05")
+	if len(result.Results) == 0 && result.Error != nil {
+		fmt.Printf("%s ❌ %s: %v\n", timestamp, filepath.Base(result.Path), result.Error)
+	}
+	for _, processed := range result.Results {
+		name := documentName(processed)
+		switch {
+		case processed.Error != nil:
+			fmt.Printf("%s ❌ %s: %v\n", timestamp, name, processed.Error)
+			for _, storeErr := range processed.StoreErrors {
+				fmt.Printf("    ❌ %s\n", storeErr)
+			}
+		case processed.Skipped:
+			fmt.Printf("%s ⏭️  %s: Unchanged since import batch %d, skipped\n",
+				timestamp, name, processed.PreviousBatchID)
+		default:
+			fmt.Printf("%s ✅ %s: Found %d transactions, stored %d (%s), import batch %d\n",
+				timestamp, name,
+				processed.TransactionsFound,
+				processed.Stored,
+				processed.Duplicates,
+				processed.BatchID)
+		}
+		for _, row := range processed.Rejected {
+			fmt.Printf("    ⚠️  rejected %s\n", row)
+		}
+		for _, gap := range processed.BalanceGaps {
+			fmt.Printf("    ⚠️  balance gap %s\n", gap)
+		}
+	}
+	if result.MovedTo != "" {
+		fmt.Printf("    moved to %s\n", result.MovedTo)
+	} else if len(result.Results) > 0 && result.Error != nil {
+		fmt.Printf("    ❌ %v\n", result.Error)
+	}
+}
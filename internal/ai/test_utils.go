@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	db "github.com/lindehoff/Budget-Assist/internal/db"
 )
@@ -108,6 +109,30 @@ func (m *MockStore) StoreTransaction(ctx context.Context, tx *db.Transaction) er
 	return nil
 }
 
+func (m *MockStore) CreateProcessingJob(ctx context.Context, job *db.ProcessingJob) error {
+	return nil
+}
+
+func (m *MockStore) UpdateProcessingJob(ctx context.Context, job *db.ProcessingJob) error {
+	return nil
+}
+
+func (m *MockStore) GetProcessingJobByID(ctx context.Context, id uint) (*db.ProcessingJob, error) {
+	return nil, db.ErrNotFound
+}
+
+func (m *MockStore) ListProcessingJobs(ctx context.Context) ([]db.ProcessingJob, error) {
+	return nil, nil
+}
+
+func (m *MockStore) UpdateJobFile(ctx context.Context, file *db.JobFile) error {
+	return nil
+}
+
+func (m *MockStore) WithTransaction(ctx context.Context, fn func(store db.Store) error) error {
+	return fn(m)
+}
+
 func (m *MockStore) Close() error {
 	return nil
 }
@@ -152,6 +177,10 @@ func (m *MockStore) GetTransactionByID(ctx context.Context, id uint) (*db.Transa
 	return nil, nil
 }
 
+func (m *MockStore) GetTransactionByFingerprint(ctx context.Context, fingerprint string) (*db.Transaction, error) {
+	return nil, db.ErrNotFound
+}
+
 func (m *MockStore) ListTransactions(ctx context.Context, filter *db.TransactionFilter) ([]db.Transaction, error) {
 	return nil, nil
 }
@@ -160,6 +189,66 @@ func (m *MockStore) DeleteTransaction(ctx context.Context, id uint) error {
 	return nil
 }
 
+func (m *MockStore) CreateAccount(ctx context.Context, account *db.Account) error {
+	return nil
+}
+
+func (m *MockStore) UpdateAccount(ctx context.Context, account *db.Account) error {
+	return nil
+}
+
+func (m *MockStore) GetAccountByID(ctx context.Context, id uint) (*db.Account, error) {
+	return nil, db.ErrNotFound
+}
+
+func (m *MockStore) GetAccountByName(ctx context.Context, name string) (*db.Account, error) {
+	return nil, db.ErrNotFound
+}
+
+func (m *MockStore) ListAccounts(ctx context.Context) ([]db.Account, error) {
+	return nil, nil
+}
+
+func (m *MockStore) DeleteAccount(ctx context.Context, id uint) (int64, error) {
+	return 0, db.ErrNotFound
+}
+
+func (m *MockStore) CreateStatementBalance(ctx context.Context, balance *db.StatementBalance) error {
+	return nil
+}
+
+func (m *MockStore) ListStatementBalances(ctx context.Context, accountID uint) ([]db.StatementBalance, error) {
+	return nil, nil
+}
+
+func (m *MockStore) SaveExchangeRates(ctx context.Context, rates []db.ExchangeRate) error {
+	return nil
+}
+
+func (m *MockStore) ListExchangeRates(ctx context.Context, start, end *time.Time) ([]db.ExchangeRate, error) {
+	return nil, nil
+}
+
+func (m *MockStore) CreateImportBatch(ctx context.Context, batch *db.ImportBatch) error {
+	return nil
+}
+
+func (m *MockStore) UpdateImportBatch(ctx context.Context, batch *db.ImportBatch) error {
+	return nil
+}
+
+func (m *MockStore) GetImportBatchByID(ctx context.Context, id uint) (*db.ImportBatch, error) {
+	return nil, db.ErrNotFound
+}
+
+func (m *MockStore) ListImportBatches(ctx context.Context) ([]db.ImportBatch, error) {
+	return nil, nil
+}
+
+func (m *MockStore) DeleteImportBatch(ctx context.Context, id uint) (int64, error) {
+	return 0, db.ErrNotFound
+}
+
 func (m *MockStore) CreatePrompt(ctx context.Context, prompt *db.Prompt) error {
 	if m.prompts == nil {
 		m.prompts = make(map[db.PromptType]*db.Prompt)
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+)
+
+// BindAccounts assigns imported transactions to the account they were booked
+// on. When accountID is set every transaction is assigned to it. Otherwise a
+// transaction is assigned to the account whose number the statement states
+// for it, as camt, MT940 and multi-account exports do; transactions of unknown
+// accounts are left without one.
+func BindAccounts(ctx context.Context, store db.Store, transactions []db.Transaction, accountID *uint) error {
+	if accountID != nil {
+		for i := range transactions {
+			id := *accountID
+			transactions[i].AccountID = &id
+		}
+		return nil
+	}
+	if store == nil {
+		return nil
+	}
+
+	accounts, err := store.ListAccounts(ctx)
+	if err != nil {
+		return NewOperationError("bind_accounts", err)
+	}
+	byNumber := accountsByNumber(accounts)
+	if len(byNumber) == 0 {
+		return nil
+	}
+
+	for i := range transactions {
+		if id, ok := byNumber[statedAccountNumber(&transactions[i])]; ok {
+			transactions[i].AccountID = &id
+		}
+	}
+	return nil
+}
+
+// accountsByNumber maps the normalized numbers of the accounts to their ids
+func accountsByNumber(accounts []db.Account) map[string]uint {
+	byNumber := make(map[string]uint, len(accounts))
+	for _, account := range accounts {
+		if account.Number != "" {
+			byNumber[db.NormalizeAccountNumber(account.Number)] = account.ID
+		}
+	}
+	return byNumber
+}
+
+// statedAccountNumber returns the normalized account number the statement
+// states for a transaction, or an empty string
+func statedAccountNumber(tx *db.Transaction) string {
+	if tx.RawData == "" {
+		return ""
+	}
+	var raw struct {
+		Account   string `json:"Account"`
+		AccountID string `json:"AccountID"` // OFX
+	}
+	if err := json.Unmarshal([]byte(tx.RawData), &raw); err != nil {
+		return ""
+	}
+	if raw.Account == "" {
+		raw.Account = raw.AccountID
+	}
+	return db.NormalizeAccountNumber(raw.Account)
+}
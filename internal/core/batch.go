@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+)
+
+// FileSHA256 returns the hex encoded SHA-256 of the file content
+func FileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// NewImportBatch describes the import of a file by the given parser
+func NewImportBatch(path, parser string) (*db.ImportBatch, error) {
+	hash, err := FileSHA256(path)
+	if err != nil {
+		return nil, NewResourceOperationError("hash", path, err)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, NewResourceOperationError("resolve_path", path, err)
+	}
+	return &db.ImportBatch{
+		FileName: filepath.Base(path),
+		FilePath: absPath,
+		FileHash: hash,
+		Parser:   parser,
+	}, nil
+}
+
+// BeginImportBatch records the start of an import batch
+func BeginImportBatch(ctx context.Context, store db.Store, batch *db.ImportBatch) error {
+	batch.StartedAt = time.Now()
+	if err := store.CreateImportBatch(ctx, batch); err != nil {
+		return NewOperationError("create_batch", err)
+	}
+	return nil
+}
+
+// CompleteImportBatch records the row counts of a finished import batch
+func CompleteImportBatch(ctx context.Context, store db.Store, batch *db.ImportBatch, parsed, rejected int, duplicates DedupSummary) error {
+	completed := time.Now()
+	batch.RowsParsed = parsed
+	batch.RowsRejected = rejected
+	batch.RowsStored = duplicates.Stored()
+	batch.RowsSkipped = duplicates.Skipped
+	batch.CompletedAt = &completed
+	if err := store.UpdateImportBatch(ctx, batch); err != nil {
+		return NewOperationError("complete_batch", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/lindehoff/Budget-Assist/internal/processor"
+)
+
+// CategoryMapper maps category names found in imported documents, such as the
+// QIF L field, onto existing categories and subcategories
+type CategoryMapper struct {
+	categories    map[string]uint
+	subcategories map[string]uint
+	overrides     map[string]string
+}
+
+// NewCategoryMapper loads the categories and subcategories from the store.
+// Overrides map a document category ("Food:Groceries") onto a local
+// "Category:Subcategory" pair when the names differ.
+func NewCategoryMapper(ctx context.Context, store db.Store, overrides map[string]string) (*CategoryMapper, error) {
+	categories, err := store.ListCategories(ctx, nil)
+	if err != nil {
+		return nil, NewOperationError("load_categories", err)
+	}
+	subcategories, err := store.ListSubcategories(ctx)
+	if err != nil {
+		return nil, NewOperationError("load_subcategories", err)
+	}
+
+	m := &CategoryMapper{
+		categories:    make(map[string]uint, len(categories)),
+		subcategories: make(map[string]uint, len(subcategories)),
+		overrides:     make(map[string]string, len(overrides)),
+	}
+	for _, c := range categories {
+		if c.IsActive {
+			m.categories[normalizeCategoryName(c.Name)] = c.ID
+		}
+	}
+	for _, s := range subcategories {
+		if s.IsActive {
+			m.subcategories[normalizeCategoryName(s.Name)] = s.ID
+		}
+	}
+	for from, to := range overrides {
+		m.overrides[normalizeCategoryName(from)] = to
+	}
+	return m, nil
+}
+
+// Map returns the category and subcategory ids for a parsed transaction.
+// ok is false when the transaction has no category or the category is unknown.
+func (m *CategoryMapper) Map(tx processor.Transaction) (categoryID, subcategoryID *uint, ok bool) {
+	if m == nil || tx.Category == "" {
+		return nil, nil, false
+	}
+
+	category, subcategory := tx.Category, tx.SubCategory
+	key := category
+	if subcategory != "" {
+		key = category + ":" + subcategory
+	}
+	if target, found := m.overrides[normalizeCategoryName(key)]; found {
+		category, subcategory, _ = strings.Cut(target, ":")
+	} else if target, found := m.overrides[normalizeCategoryName(category)]; found {
+		category, _, _ = strings.Cut(target, ":")
+	}
+
+	id, found := m.categories[normalizeCategoryName(category)]
+	if !found {
+		return nil, nil, false
+	}
+	categoryID = &id
+
+	if subcategory != "" {
+		if subID, found := m.subcategories[normalizeCategoryName(subcategory)]; found {
+			subcategoryID = &subID
+		}
+	}
+	return categoryID, subcategoryID, true
+}
+
+func normalizeCategoryName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
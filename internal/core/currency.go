@@ -0,0 +1,198 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/shopspring/decimal"
+)
+
+// RateLookback is the most days an exchange rate is used after the day it was
+// published for, covering weekends and bank holidays without rates
+const RateLookback = 7
+
+// CurrencyConverter converts amounts to a base currency with the stored
+// exchange rates. A rate is used in either direction, and currencies without a
+// rate to the base currency are converted through a currency both have rates
+// for, e.g. USD to SEK through the euro rates of the ECB.
+type CurrencyConverter struct {
+	base       string
+	rates      map[[2]string][]db.ExchangeRate // Rates by currency and quote currency, ordered by date
+	currencies []string                        // Currencies with rates, the candidates for converting through
+}
+
+// NewCurrencyConverter loads the exchange rates needed to convert amounts
+// booked between start and end, nil for no limit, to the base currency
+func NewCurrencyConverter(ctx context.Context, store db.Store, base string, start, end *time.Time) (*CurrencyConverter, error) {
+	if !db.IsCurrencyCode(base) {
+		return nil, NewValidationError("base_currency", base, "not an ISO 4217 currency code")
+	}
+	if start != nil {
+		from := start.AddDate(0, 0, -RateLookback)
+		start = &from
+	}
+	rates, err := store.ListExchangeRates(ctx, start, end)
+	if err != nil {
+		return nil, NewOperationError("load_exchange_rates", err)
+	}
+
+	c := &CurrencyConverter{base: base, rates: make(map[[2]string][]db.ExchangeRate)}
+	seen := make(map[string]bool)
+	for _, rate := range rates {
+		pair := [2]string{rate.Currency, rate.QuoteCurrency}
+		c.rates[pair] = append(c.rates[pair], rate)
+		for _, currency := range pair {
+			if !seen[currency] {
+				seen[currency] = true
+				c.currencies = append(c.currencies, currency)
+			}
+		}
+	}
+	sort.Strings(c.currencies)
+	return c, nil
+}
+
+// Base returns the currency amounts are converted to
+func (c *CurrencyConverter) Base() string {
+	return c.base
+}
+
+// Convert converts an amount booked on a day to the base currency, rounded to
+// cents. It reports false when no rate is known for the day.
+func (c *CurrencyConverter) Convert(amount decimal.Decimal, currency string, date time.Time) (decimal.Decimal, bool) {
+	rate, ok := c.Rate(currency, date)
+	if !ok {
+		return decimal.Decimal{}, false
+	}
+	return amount.Mul(rate).Round(2), true
+}
+
+// Rate returns the price of one unit of the currency in the base currency on a day
+func (c *CurrencyConverter) Rate(currency string, date time.Time) (decimal.Decimal, bool) {
+	if currency == c.base {
+		return decimal.NewFromInt(1), true
+	}
+	if rate, ok := c.rate(currency, c.base, date); ok {
+		return rate, true
+	}
+	for _, via := range c.currencies {
+		if via == currency || via == c.base {
+			continue
+		}
+		first, ok := c.rate(currency, via, date)
+		if !ok {
+			continue
+		}
+		if second, ok := c.rate(via, c.base, date); ok {
+			return first.Mul(second), true
+		}
+	}
+	return decimal.Decimal{}, false
+}
+
+// rate returns the price of one unit of from in to, using a rate published
+// for either direction
+func (c *CurrencyConverter) rate(from, to string, date time.Time) (decimal.Decimal, bool) {
+	if rate, ok := latestRate(c.rates[[2]string{from, to}], date); ok {
+		return rate, true
+	}
+	if rate, ok := latestRate(c.rates[[2]string{to, from}], date); ok {
+		return decimal.NewFromInt(1).Div(rate), true
+	}
+	return decimal.Decimal{}, false
+}
+
+// latestRate returns the last rate published on or before the booking day,
+// at most RateLookback days before it
+func latestRate(rates []db.ExchangeRate, date time.Time) (decimal.Decimal, bool) {
+	day := bookingDay(date)
+	i := sort.Search(len(rates), func(i int) bool { return rates[i].Date.After(day) })
+	if i == 0 || day.Sub(rates[i-1].Date) > RateLookback*24*time.Hour {
+		return decimal.Decimal{}, false
+	}
+	return rates[i-1].Rate, true
+}
+
+// Apply sets the base amount of a transaction. It reports false, and clears
+// the base amount, when no rate is known for the booking day.
+func (c *CurrencyConverter) Apply(tx *db.Transaction) bool {
+	amount, ok := c.Convert(tx.Amount, tx.Currency, tx.Date)
+	if !ok {
+		tx.BaseAmount, tx.BaseCurrency = decimal.NullDecimal{}, ""
+		return false
+	}
+	tx.BaseAmount, tx.BaseCurrency = decimal.NewNullDecimal(amount), c.base
+	return true
+}
+
+// ConvertTransactions sets the base amounts of transactions about to be
+// stored and returns how many have no exchange rate
+func ConvertTransactions(ctx context.Context, store db.Store, base string, transactions []db.Transaction) (int, error) {
+	if len(transactions) == 0 {
+		return 0, nil
+	}
+	start, end := transactions[0].Date, transactions[0].Date
+	for _, tx := range transactions {
+		if tx.Date.Before(start) {
+			start = tx.Date
+		}
+		if tx.Date.After(end) {
+			end = tx.Date
+		}
+	}
+	converter, err := NewCurrencyConverter(ctx, store, base, &start, &end)
+	if err != nil {
+		return 0, err
+	}
+	missing := 0
+	for i := range transactions {
+		if !converter.Apply(&transactions[i]) {
+			missing++
+		}
+	}
+	return missing, nil
+}
+
+// ConvertStoredTransactions sets the base amounts of stored transactions that
+// have none in the base currency, or of all of them when all is set, as after
+// changing the base currency or importing corrected rates. It returns how many
+// transactions were converted and how many still have no exchange rate.
+func ConvertStoredTransactions(ctx context.Context, store db.Store, base string, all bool) (int, int, error) {
+	transactions, err := store.ListTransactions(ctx, nil)
+	if err != nil {
+		return 0, 0, NewOperationError("convert_transactions", err)
+	}
+	converter, err := NewCurrencyConverter(ctx, store, base, nil, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	converted, missing := 0, 0
+	err = store.WithTransaction(ctx, func(store db.Store) error {
+		for _, tx := range transactions {
+			if !all && tx.BaseCurrency == base && tx.BaseAmount.Valid {
+				continue
+			}
+			stale := tx.BaseAmount.Valid
+			if !converter.Apply(&tx) {
+				missing++
+				if !stale {
+					continue
+				}
+				// A base amount in another currency is cleared rather than kept
+			} else {
+				converted++
+			}
+			if err := store.UpdateTransaction(ctx, &tx); err != nil {
+				return NewOperationError("convert_transactions", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return converted, missing, nil
+}
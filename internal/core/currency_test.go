@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/shopspring/decimal"
+)
+
+// createExchangeRates stores Riksbank and ECB rates for the first days of September 2026
+func createExchangeRates(t *testing.T, ctx context.Context, store db.Store) {
+	t.Helper()
+	day := func(d int) time.Time { return time.Date(2026, 9, d, 0, 0, 0, 0, time.UTC) }
+	rates := []db.ExchangeRate{
+		{Date: day(1), Currency: db.CurrencyEUR, QuoteCurrency: db.CurrencySEK, Rate: decimal.RequireFromString("11.00"), Source: "riksbank"},
+		{Date: day(4), Currency: db.CurrencyEUR, QuoteCurrency: db.CurrencySEK, Rate: decimal.RequireFromString("11.20"), Source: "riksbank"},
+		{Date: day(4), Currency: db.CurrencyEUR, QuoteCurrency: db.CurrencyUSD, Rate: decimal.RequireFromString("1.12"), Source: "ecb"},
+	}
+	if err := store.SaveExchangeRates(ctx, rates); err != nil {
+		t.Fatalf("SaveExchangeRates() unexpected error: %v", err)
+	}
+}
+
+func TestCurrencyConverter_Convert(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		amount   string
+		currency string
+		day      int
+		want     string
+		wantOK   bool
+	}{
+		{name: "Successfully_keep_base_currency", base: db.CurrencySEK, amount: "-100", currency: db.CurrencySEK, day: 1, want: "-100", wantOK: true},
+		{name: "Successfully_convert_with_rate_of_day", base: db.CurrencySEK, amount: "-10", currency: db.CurrencyEUR, day: 4, want: "-112", wantOK: true},
+		{name: "Successfully_use_last_published_rate", base: db.CurrencySEK, amount: "10", currency: db.CurrencyEUR, day: 3, want: "110", wantOK: true},
+		{name: "Successfully_convert_inverse_rate", base: db.CurrencyEUR, amount: "112", currency: db.CurrencySEK, day: 4, want: "10", wantOK: true},
+		{name: "Successfully_convert_through_euro", base: db.CurrencySEK, amount: "11.2", currency: db.CurrencyUSD, day: 4, want: "112", wantOK: true},
+		{name: "Error_rate_too_old", base: db.CurrencySEK, amount: "10", currency: db.CurrencyEUR, day: 12},
+		{name: "Error_no_rate_before_day", base: db.CurrencySEK, amount: "10", currency: db.CurrencyUSD, day: 1},
+		{name: "Error_unknown_currency", base: db.CurrencySEK, amount: "10", currency: "NOK", day: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			ctx := context.Background()
+			store := db.NewMockStore()
+			createExchangeRates(t, ctx, store)
+			converter, err := NewCurrencyConverter(ctx, store, tt.base, nil, nil)
+			if err != nil {
+				t.Fatalf("NewCurrencyConverter() unexpected error: %v", err)
+			}
+
+			// Execute
+			got, ok := converter.Convert(decimal.RequireFromString(tt.amount), tt.currency, time.Date(2026, 9, tt.day, 14, 30, 0, 0, time.UTC))
+
+			// Verify
+			if ok != tt.wantOK {
+				t.Fatalf("Convert() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("Convert() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCurrencyConverter_Error_invalid_base(t *testing.T) {
+	_, err := NewCurrencyConverter(context.Background(), db.NewMockStore(), "kronor", nil, nil)
+	if err == nil {
+		t.Error("NewCurrencyConverter() expected an error for an invalid base currency")
+	}
+}
+
+func TestConvertStoredTransactions(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	store := db.NewMockStore()
+	createExchangeRates(t, ctx, store)
+	transactions := []*db.Transaction{
+		{Description: "Hotel", Date: time.Date(2026, 9, 4, 0, 0, 0, 0, time.UTC), Amount: decimal.RequireFromString("-100"), Currency: db.CurrencyEUR},
+		{Description: "Taxi", Date: time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC), Amount: decimal.RequireFromString("-20"), Currency: db.CurrencyEUR},
+		{Description: "ICA", Date: time.Date(2026, 9, 4, 0, 0, 0, 0, time.UTC), Amount: decimal.RequireFromString("-50"), Currency: db.CurrencySEK},
+	}
+	for _, tx := range transactions {
+		if err := store.CreateTransaction(ctx, tx); err != nil {
+			t.Fatalf("CreateTransaction() unexpected error: %v", err)
+		}
+	}
+
+	// Execute
+	converted, missing, err := ConvertStoredTransactions(ctx, store, db.CurrencySEK, false)
+
+	// Verify
+	if err != nil {
+		t.Fatalf("ConvertStoredTransactions() unexpected error: %v", err)
+	}
+	if converted != 2 || missing != 1 {
+		t.Errorf("ConvertStoredTransactions() = %d converted, %d missing, want 2 and 1", converted, missing)
+	}
+	hotel, _ := store.GetTransactionByID(ctx, transactions[0].ID)
+	if !hotel.BaseAmount.Valid || !hotel.BaseAmount.Decimal.Equal(decimal.RequireFromString("-1120")) || hotel.BaseCurrency != db.CurrencySEK {
+		t.Errorf("base amount = %v %s, want -1120 SEK", hotel.BaseAmount, hotel.BaseCurrency)
+	}
+
+	// Only transactions without a base amount in the base currency are converted again
+	converted, _, _ = ConvertStoredTransactions(ctx, store, db.CurrencySEK, false)
+	if converted != 0 {
+		t.Errorf("ConvertStoredTransactions() converted %d transactions again, want 0", converted)
+	}
+	// Changing the base currency converts them all, the euro amounts at par
+	converted, missing, _ = ConvertStoredTransactions(ctx, store, db.CurrencyEUR, false)
+	if converted != 3 || missing != 0 {
+		t.Errorf("ConvertStoredTransactions() to EUR = %d converted, %d missing, want 3 and 0", converted, missing)
+	}
+}
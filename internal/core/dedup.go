@@ -0,0 +1,187 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+)
+
+// DuplicatePolicy decides what happens to imported transactions that are already stored
+type DuplicatePolicy string
+
+// Duplicate policies
+const (
+	DuplicateSkip    DuplicatePolicy = "skip"    // Leave the stored transaction as it is
+	DuplicateFlag    DuplicatePolicy = "flag"    // Store the duplicate, linked to the stored transaction
+	DuplicateReplace DuplicatePolicy = "replace" // Overwrite the stored transaction
+)
+
+// ParseDuplicatePolicy parses the --on-duplicate value, defaulting to skip
+func ParseDuplicatePolicy(value string) (DuplicatePolicy, error) {
+	switch policy := DuplicatePolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case "":
+		return DuplicateSkip, nil
+	case DuplicateSkip, DuplicateFlag, DuplicateReplace:
+		return policy, nil
+	default:
+		return "", NewValidationError("on_duplicate", value, "must be skip, flag or replace")
+	}
+}
+
+// DedupSummary counts how the transactions of an import compared to the stored ones
+type DedupSummary struct {
+	New      int
+	Skipped  int
+	Flagged  int
+	Replaced int
+}
+
+// Known returns the number of transactions that were already stored
+func (s DedupSummary) Known() int {
+	return s.Skipped + s.Flagged + s.Replaced
+}
+
+// Stored returns the number of transactions that were written to the store
+func (s DedupSummary) Stored() int {
+	return s.New + s.Flagged + s.Replaced
+}
+
+// Add adds the counts of another summary
+func (s *DedupSummary) Add(other DedupSummary) {
+	s.New += other.New
+	s.Skipped += other.Skipped
+	s.Flagged += other.Flagged
+	s.Replaced += other.Replaced
+}
+
+func (s DedupSummary) String() string {
+	known := fmt.Sprintf("%d already known", s.Known())
+	var details []string
+	for _, count := range []struct {
+		n    int
+		verb string
+	}{{s.Skipped, "skipped"}, {s.Flagged, "flagged"}, {s.Replaced, "replaced"}} {
+		if count.n > 0 {
+			details = append(details, fmt.Sprintf("%d %s", count.n, count.verb))
+		}
+	}
+	if len(details) > 0 {
+		known += " (" + strings.Join(details, ", ") + ")"
+	}
+	return fmt.Sprintf("%d new, %s", s.New, known)
+}
+
+// Deduplicator stores the transactions of one document, applying the
+// duplicate policy to transactions an earlier import already stored
+type Deduplicator struct {
+	store       db.Store
+	policy      DuplicatePolicy
+	occurrences map[string]int
+	summary     DedupSummary
+}
+
+// NewDeduplicator creates a deduplicator for a single document
+func NewDeduplicator(store db.Store, policy DuplicatePolicy) *Deduplicator {
+	if policy == "" {
+		policy = DuplicateSkip
+	}
+	return &Deduplicator{
+		store:       store,
+		policy:      policy,
+		occurrences: make(map[string]int),
+	}
+}
+
+// Store fingerprints the transaction and stores it unless the policy says otherwise
+func (d *Deduplicator) Store(ctx context.Context, tx *db.Transaction) error {
+	key := fingerprintKey(tx)
+	d.occurrences[key]++
+	tx.Fingerprint = hashFingerprint(key, d.occurrences[key])
+
+	existing, err := d.store.GetTransactionByFingerprint(ctx, tx.Fingerprint)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return NewOperationError("find_duplicate", err)
+	}
+	if existing == nil {
+		if err := d.store.CreateTransaction(ctx, tx); err != nil {
+			return NewOperationError("store", err)
+		}
+		d.summary.New++
+		return nil
+	}
+
+	switch d.policy {
+	case DuplicateFlag:
+		tx.DuplicateOfID = &existing.ID
+		if err := d.store.CreateTransaction(ctx, tx); err != nil {
+			return NewOperationError("store", err)
+		}
+		d.summary.Flagged++
+	case DuplicateReplace:
+		// The transaction stays with the batch that created it, keeps its links
+		// to a transfer or an original, and categories assigned to it survive a
+		// re-import without one
+		tx.ID = existing.ID
+		tx.ImportBatchID = existing.ImportBatchID
+		tx.TransferOfID, tx.TransferStatus = existing.TransferOfID, existing.TransferStatus
+		tx.DuplicateOfID = existing.DuplicateOfID
+		if tx.CategoryID == nil {
+			tx.CategoryID, tx.SubcategoryID = existing.CategoryID, existing.SubcategoryID
+		}
+		if err := d.store.UpdateTransaction(ctx, tx); err != nil {
+			return NewOperationError("replace", err)
+		}
+		d.summary.Replaced++
+	default:
+		d.summary.Skipped++
+	}
+	return nil
+}
+
+// Summary returns the counts for the transactions stored so far
+func (d *Deduplicator) Summary() DedupSummary {
+	return d.summary
+}
+
+// TransactionFingerprint returns the fingerprint of a transaction. Identical
+// rows within one document, such as two equal card purchases on the same day,
+// are told apart by their occurrence, starting at 1.
+func TransactionFingerprint(tx *db.Transaction, occurrence int) string {
+	return hashFingerprint(fingerprintKey(tx), occurrence)
+}
+
+// fingerprintKey joins the account, date, amount, normalized description and
+// bank reference that identify a transaction across imports
+func fingerprintKey(tx *db.Transaction) string {
+	date := tx.Date
+	if date.IsZero() {
+		date = tx.TransactionDate
+	}
+	return strings.Join([]string{
+		transactionAccount(tx),
+		date.Format("2006-01-02"),
+		tx.Amount.String(),
+		strings.Join(strings.Fields(strings.ToLower(tx.Description)), " "),
+		strings.TrimSpace(tx.Reference),
+	}, "\x1f")
+}
+
+func hashFingerprint(key string, occurrence int) string {
+	sum := sha256.Sum256([]byte(key + "\x1f" + strconv.Itoa(occurrence)))
+	return hex.EncodeToString(sum[:])
+}
+
+// transactionAccount identifies the account of a transaction: the account it
+// is bound to, or else the account number the statement states, if any
+func transactionAccount(tx *db.Transaction) string {
+	if tx.AccountID != nil {
+		return "id:" + strconv.FormatUint(uint64(*tx.AccountID), 10)
+	}
+	return statedAccountNumber(tx)
+}
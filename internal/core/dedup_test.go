@@ -0,0 +1,236 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/shopspring/decimal"
+)
+
+func createDedupTransactions() []db.Transaction {
+	date := time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC)
+	return []db.Transaction{
+		{Date: date, Amount: decimal.RequireFromString("-45.00"), Description: "Pressbyrån", Reference: "5490990004", Currency: db.CurrencySEK},
+		{Date: date, Amount: decimal.RequireFromString("-45.00"), Description: "Pressbyrån", Reference: "5490990004", Currency: db.CurrencySEK},
+		{Date: date, Amount: decimal.RequireFromString("25000"), Description: "Lön", Reference: "5490990005", Currency: db.CurrencySEK},
+	}
+}
+
+func storeWithDeduplicator(t *testing.T, store db.Store, policy DuplicatePolicy, transactions []db.Transaction) DedupSummary {
+	t.Helper()
+	dedup := NewDeduplicator(store, policy)
+	for i := range transactions {
+		if err := dedup.Store(context.TODO(), &transactions[i]); err != nil {
+			t.Fatalf("Store() unexpected error: %v", err)
+		}
+	}
+	return dedup.Summary()
+}
+
+func TestDeduplicator_Store(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      DuplicatePolicy
+		reimport    func(txs []db.Transaction)
+		wantSummary DedupSummary
+		wantStored  int
+	}{
+		{
+			name:        "Successfully_skip_known_transactions",
+			policy:      DuplicateSkip,
+			wantSummary: DedupSummary{Skipped: 3},
+			wantStored:  3,
+		},
+		{
+			name:        "Successfully_flag_known_transactions",
+			policy:      DuplicateFlag,
+			wantSummary: DedupSummary{Flagged: 3},
+			wantStored:  6,
+		},
+		{
+			name:        "Successfully_replace_known_transactions",
+			policy:      DuplicateReplace,
+			wantSummary: DedupSummary{Replaced: 3},
+			wantStored:  3,
+		},
+		{
+			name:   "Successfully_match_normalized_description",
+			policy: DuplicateSkip,
+			reimport: func(txs []db.Transaction) {
+				txs[2].Description = "  LÖN  "
+			},
+			wantSummary: DedupSummary{Skipped: 3},
+			wantStored:  3,
+		},
+		{
+			name:   "Successfully_store_additional_identical_row_as_new",
+			policy: DuplicateSkip,
+			reimport: func(txs []db.Transaction) {
+				txs[2] = txs[0]
+			},
+			wantSummary: DedupSummary{New: 1, Skipped: 2},
+			wantStored:  4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := db.NewMockStore()
+			first := storeWithDeduplicator(t, store, tt.policy, createDedupTransactions())
+			if first != (DedupSummary{New: 3}) {
+				t.Fatalf("first import summary = %+v, want 3 new", first)
+			}
+
+			again := createDedupTransactions()
+			if tt.reimport != nil {
+				tt.reimport(again)
+			}
+			got := storeWithDeduplicator(t, store, tt.policy, again)
+			if got != tt.wantSummary {
+				t.Errorf("second import summary = %+v, want %+v", got, tt.wantSummary)
+			}
+
+			stored, _ := store.ListTransactions(context.TODO(), nil)
+			if len(stored) != tt.wantStored {
+				t.Errorf("store contains %d transactions, want %d", len(stored), tt.wantStored)
+			}
+			for _, tx := range stored {
+				if tx.Fingerprint == "" {
+					t.Errorf("transaction %d has no fingerprint", tx.ID)
+				}
+				if tt.policy == DuplicateFlag && tx.ID > 3 && tx.DuplicateOfID == nil {
+					t.Errorf("transaction %d is not flagged as duplicate", tx.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestDeduplicator_Successfully_keep_category_on_replace(t *testing.T) {
+	store := db.NewMockStore()
+	categoryID := uint(7)
+	original := createDedupTransactions()[:1]
+	original[0].CategoryID = &categoryID
+	storeWithDeduplicator(t, store, DuplicateReplace, original)
+
+	replacement := createDedupTransactions()[:1]
+	replacement[0].RawData = `{"Balance":"100.00"}`
+	storeWithDeduplicator(t, store, DuplicateReplace, replacement)
+
+	got, err := store.GetTransactionByID(context.TODO(), original[0].ID)
+	if err != nil {
+		t.Fatalf("GetTransactionByID() unexpected error: %v", err)
+	}
+	if got.RawData != replacement[0].RawData {
+		t.Errorf("RawData = %q, want %q", got.RawData, replacement[0].RawData)
+	}
+	if got.CategoryID == nil || *got.CategoryID != categoryID {
+		t.Errorf("CategoryID = %v, want %d", got.CategoryID, categoryID)
+	}
+}
+
+func TestDeduplicator_Successfully_keep_transfer_on_replace(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMockStore()
+	transfer := func() []db.Transaction {
+		date := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+		return []db.Transaction{
+			{Date: date, Amount: decimal.RequireFromString("-5000"), Description: "Till sparkonto", Currency: db.CurrencySEK},
+			{Date: date, Amount: decimal.RequireFromString("5000"), Description: "Från lönekonto", Currency: db.CurrencySEK},
+		}
+	}
+	original := transfer()
+	storeWithDeduplicator(t, store, DuplicateReplace, original)
+	if _, err := LinkTransfer(ctx, store, original[0].ID, original[1].ID); err != nil {
+		t.Fatalf("LinkTransfer() unexpected error: %v", err)
+	}
+
+	summary := storeWithDeduplicator(t, store, DuplicateReplace, transfer())
+
+	if summary.Replaced != 2 {
+		t.Fatalf("Replaced = %d, want 2", summary.Replaced)
+	}
+	for i, other := range []uint{original[1].ID, original[0].ID} {
+		got, err := store.GetTransactionByID(ctx, original[i].ID)
+		if err != nil {
+			t.Fatalf("GetTransactionByID() unexpected error: %v", err)
+		}
+		if got.TransferOfID == nil || *got.TransferOfID != other || got.TransferStatus != db.TransferConfirmed {
+			t.Errorf("transaction %d TransferOfID = %v (%q), want %d (%q)",
+				got.ID, got.TransferOfID, got.TransferStatus, other, db.TransferConfirmed)
+		}
+	}
+}
+
+func TestDeduplicator_Successfully_store_same_row_on_two_accounts(t *testing.T) {
+	store := db.NewMockStore()
+
+	// Each card is its own import, with the transactions bound to its account
+	var summary DedupSummary
+	for _, accountID := range []uint{1, 2} {
+		transactions := createDedupTransactions()[2:]
+		transactions[0].AccountID = &accountID
+		summary.New += storeWithDeduplicator(t, store, DuplicateSkip, transactions).New
+	}
+
+	if summary.New != 2 {
+		t.Errorf("New = %d, want 2", summary.New)
+	}
+	if stored, _ := store.ListTransactions(context.TODO(), nil); len(stored) != 2 {
+		t.Errorf("store contains %d transactions, want 2", len(stored))
+	}
+}
+
+func TestTransactionFingerprint(t *testing.T) {
+	base := createDedupTransactions()[0]
+	tests := []struct {
+		name   string
+		modify func(tx *db.Transaction)
+		same   bool
+	}{
+		{name: "Successfully_ignore_description_case_and_spacing", modify: func(tx *db.Transaction) { tx.Description = " PRESSBYRÅN " }, same: true},
+		{name: "Successfully_ignore_trailing_zeros", modify: func(tx *db.Transaction) { tx.Amount = decimal.RequireFromString("-45") }, same: true},
+		{name: "Successfully_separate_amounts", modify: func(tx *db.Transaction) { tx.Amount = decimal.RequireFromString("-46") }},
+		{name: "Successfully_separate_references", modify: func(tx *db.Transaction) { tx.Reference = "5490990099" }},
+		{name: "Successfully_separate_accounts", modify: func(tx *db.Transaction) { tx.RawData = `{"Account":"5490 12 345"}` }},
+		{name: "Successfully_separate_ofx_accounts", modify: func(tx *db.Transaction) { tx.RawData = `{"AccountID":"5490 12 345"}` }},
+		{name: "Successfully_separate_bound_accounts", modify: func(tx *db.Transaction) { accountID := uint(2); tx.AccountID = &accountID }},
+		{name: "Successfully_separate_dates", modify: func(tx *db.Transaction) { tx.Date = tx.Date.AddDate(0, 0, 1) }},
+	}
+
+	want := TransactionFingerprint(&base, 1)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := base
+			tt.modify(&tx)
+			if got := TransactionFingerprint(&tx, 1); (got == want) != tt.same {
+				t.Errorf("TransactionFingerprint() equal = %v, want %v", got == want, tt.same)
+			}
+		})
+	}
+	if TransactionFingerprint(&base, 2) == want {
+		t.Error("TransactionFingerprint() does not separate occurrences")
+	}
+}
+
+func TestParseDuplicatePolicy(t *testing.T) {
+	for input, want := range map[string]DuplicatePolicy{"": DuplicateSkip, "skip": DuplicateSkip, "FLAG": DuplicateFlag, " replace ": DuplicateReplace} {
+		got, err := ParseDuplicatePolicy(input)
+		if err != nil || got != want {
+			t.Errorf("ParseDuplicatePolicy(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseDuplicatePolicy("merge"); err == nil || !strings.Contains(err.Error(), "skip, flag or replace") {
+		t.Errorf("ParseDuplicatePolicy(merge) error = %v, want validation error", err)
+	}
+}
+
+func TestDedupSummary_String(t *testing.T) {
+	summary := DedupSummary{New: 2, Skipped: 3, Flagged: 1}
+	if got, want := summary.String(), "2 new, 4 already known (3 skipped, 1 flagged)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
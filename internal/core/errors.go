@@ -16,6 +16,11 @@ func (e OperationError) Error() string {
 	return fmt.Sprintf("%s operation failed: %v", e.Operation, e.Err)
 }
 
+// Unwrap returns the underlying error
+func (e OperationError) Unwrap() error {
+	return e.Err
+}
+
 // ValidationError represents validation failures
 type ValidationError struct {
 	Field   string
@@ -33,6 +38,7 @@ var (
 	ErrNotFound         = fmt.Errorf("resource not found")
 	ErrAlreadyExists    = fmt.Errorf("resource already exists")
 	ErrInvalidOperation = fmt.Errorf("invalid operation")
+	ErrNoBalance        = fmt.Errorf("no statement balance")
 )
 
 // Error constructors
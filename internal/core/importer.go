@@ -0,0 +1,220 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/lindehoff/Budget-Assist/internal/processor"
+)
+
+// ImportOptions contains runtime options for importing a document
+type ImportOptions struct {
+	// Currency is the ISO currency code assigned to all imported transactions
+	Currency string
+	// BaseCurrency is the currency the stored transactions are converted to with
+	// the stored exchange rates; empty disables conversion
+	BaseCurrency string
+	// Categories maps document categories (e.g. QIF) onto existing categories; nil disables mapping
+	Categories *CategoryMapper
+	// AccountID binds all imported transactions to an account; when nil they are
+	// bound by the account number the document states, if it matches an account
+	AccountID *uint
+	// DryRun parses and converts the document without storing anything
+	DryRun bool
+	// OnDuplicate decides what happens to transactions that are already stored, default skip
+	OnDuplicate DuplicatePolicy
+	// Batch records the imported file and links the stored transactions to it; nil disables recording
+	Batch *db.ImportBatch
+}
+
+// ImportResult represents the outcome of an import
+type ImportResult struct {
+	Transactions []db.Transaction
+	// BalanceChecks compares the statement balances with the parsed transactions, when the parser reads balances
+	BalanceChecks []processor.BalanceCheck
+	// BalanceGaps lists the rows whose running balance does not follow from the rows before them
+	BalanceGaps []processor.BalanceGap
+	// Rejected lists the rows the parser skipped, when the parser reports them
+	Rejected []processor.RowError
+	// Duplicates counts the new and already stored transactions
+	Duplicates  DedupSummary
+	Stored      int
+	Categorized int
+}
+
+// Importer converts parsed documents into stored transactions
+type Importer struct {
+	store  db.Store
+	logger *slog.Logger
+}
+
+// NewImporter creates a new Importer
+func NewImporter(store db.Store, logger *slog.Logger) *Importer {
+	return &Importer{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Import parses the reader with the given processor and stores the resulting transactions
+func (i *Importer) Import(ctx context.Context, proc processor.DocumentProcessor, reader io.Reader, opts ImportOptions) (*ImportResult, error) {
+	if proc == nil {
+		return nil, NewOperationError("import", fmt.Errorf("%w: processor is required", ErrInvalidInput))
+	}
+	if opts.Currency == "" {
+		return nil, NewValidationError("currency", opts.Currency, "currency is required")
+	}
+	if !db.IsCurrencyCode(opts.Currency) {
+		return nil, NewValidationError("currency", opts.Currency, "not an ISO 4217 currency code")
+	}
+
+	rawTransactions, err := proc.ProcessDocument(ctx, reader)
+	var rejected []processor.RowError
+	if reporter, ok := proc.(processor.RowErrorReporter); ok {
+		rejected = reporter.RejectedRows()
+		for _, row := range rejected {
+			i.logger.Warn("rejected row", "line", row.Line, "reason", row.Reason)
+		}
+	}
+	if err != nil {
+		// The rejected rows explain why a document yielded no transactions
+		return &ImportResult{Rejected: rejected}, NewOperationError("parse", err)
+	}
+
+	result := &ImportResult{
+		Transactions: make([]db.Transaction, 0, len(rawTransactions)),
+		Rejected:     rejected,
+	}
+	for _, tx := range rawTransactions {
+		dbTx, err := ConvertTransaction(tx, opts.Currency)
+		if err != nil {
+			return nil, NewOperationError("convert", err)
+		}
+		if categoryID, subcategoryID, ok := opts.Categories.Map(tx); ok {
+			dbTx.CategoryID = categoryID
+			dbTx.SubcategoryID = subcategoryID
+			result.Categorized++
+		}
+		result.Transactions = append(result.Transactions, dbTx)
+	}
+
+	if err := BindAccounts(ctx, i.store, result.Transactions, opts.AccountID); err != nil {
+		return result, err
+	}
+
+	if provider, ok := proc.(processor.BalanceProvider); ok {
+		result.BalanceChecks = processor.CheckBalances(rawTransactions, provider.Balances())
+		for _, check := range result.BalanceChecks {
+			if !check.OK() {
+				i.logger.Warn("statement balances do not match transactions",
+					"account", check.Account,
+					"discrepancy", check.Discrepancy)
+			}
+		}
+	}
+
+	result.BalanceGaps = processor.CheckRunningBalance(rawTransactions)
+	for _, gap := range result.BalanceGaps {
+		i.logger.Warn("running balance does not match transactions, rows may be missing",
+			"date", gap.Date.Format("2006-01-02"),
+			"description", gap.Description,
+			"difference", gap.Difference())
+	}
+
+	if opts.DryRun {
+		i.logger.Info("dry run, skipping storage", "transactions", len(result.Transactions))
+		return result, nil
+	}
+
+	// The batch, its transactions and statement balances are stored together,
+	// so that a failing row leaves nothing half imported behind
+	err = i.store.WithTransaction(ctx, func(store db.Store) error {
+		if opts.Batch != nil {
+			if err := BeginImportBatch(ctx, store, opts.Batch); err != nil {
+				return err
+			}
+			for idx := range result.Transactions {
+				result.Transactions[idx].ImportBatchID = &opts.Batch.ID
+			}
+		}
+
+		if opts.BaseCurrency != "" {
+			missing, err := ConvertTransactions(ctx, store, opts.BaseCurrency, result.Transactions)
+			if err != nil {
+				return err
+			}
+			if missing > 0 {
+				i.logger.Warn("no exchange rate for transactions, import rates and run 'budgetassist exchange convert'",
+					"transactions", missing,
+					"base_currency", opts.BaseCurrency)
+			}
+		}
+
+		dedup := NewDeduplicator(store, opts.OnDuplicate)
+		for idx := range result.Transactions {
+			err := dedup.Store(ctx, &result.Transactions[idx])
+			result.Duplicates = dedup.Summary()
+			result.Stored = result.Duplicates.Stored()
+			if err != nil {
+				return err
+			}
+		}
+
+		var batchID *uint
+		if opts.Batch != nil {
+			batchID = &opts.Batch.ID
+		}
+		if _, err := StoreStatementBalances(ctx, store, StatementBalances(proc, rawTransactions), opts.AccountID, batchID); err != nil {
+			return err
+		}
+
+		if opts.Batch != nil {
+			return CompleteImportBatch(ctx, store, opts.Batch, len(rawTransactions), len(result.Rejected), result.Duplicates)
+		}
+		return nil
+	})
+	if err != nil {
+		result.Stored = 0
+		i.logger.Error("rolled back import", "error", err)
+		return result, fmt.Errorf("import rolled back: %w", err)
+	}
+
+	i.logger.Info("import completed",
+		"stored", result.Stored,
+		"new", result.Duplicates.New,
+		"known", result.Duplicates.Known(),
+		"categorized", result.Categorized)
+	return result, nil
+}
+
+// ConvertTransaction converts a parsed transaction into a database transaction.
+// The currency stated in the document takes precedence over the given default.
+func ConvertTransaction(tx processor.Transaction, currency string) (db.Transaction, error) {
+	rawData, err := json.Marshal(tx.RawData)
+	if err != nil {
+		return db.Transaction{}, fmt.Errorf("failed to marshal raw data: %w", err)
+	}
+	if tx.Currency != "" {
+		currency = tx.Currency
+	}
+	if !db.IsCurrencyCode(currency) {
+		return db.Transaction{}, fmt.Errorf("invalid currency: %s", currency)
+	}
+
+	return db.Transaction{
+		Date:            tx.Date,
+		TransactionDate: tx.Date,
+		ValueDate:       tx.ValueDate,
+		Amount:          tx.Amount,
+		Balance:         tx.Balance,
+		Description:     tx.Description,
+		Reference:       tx.Reference,
+		Source:          tx.Source,
+		RawData:         string(rawData),
+		Currency:        currency,
+	}, nil
+}
@@ -0,0 +1,400 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/lindehoff/Budget-Assist/internal/processor"
+	"github.com/shopspring/decimal"
+)
+
+type stubProcessor struct {
+	transactions []processor.Transaction
+	err          error
+}
+
+func (p *stubProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([]processor.Transaction, error) {
+	return p.transactions, p.err
+}
+
+// rejectingProcessor is a stubProcessor that also reports rejected rows
+type rejectingProcessor struct {
+	stubProcessor
+	rejected []processor.RowError
+}
+
+func (p *rejectingProcessor) RejectedRows() []processor.RowError {
+	return p.rejected
+}
+
+// failingStore is a db.MockStore that fails to create the transactions with the given description
+type failingStore struct {
+	*db.MockStore
+	failDescription string
+}
+
+func (s *failingStore) CreateTransaction(ctx context.Context, tx *db.Transaction) error {
+	if tx.Description == s.failDescription {
+		return errors.New("constraint failed")
+	}
+	return s.MockStore.CreateTransaction(ctx, tx)
+}
+
+func (s *failingStore) WithTransaction(ctx context.Context, fn func(store db.Store) error) error {
+	return s.MockStore.WithTransaction(ctx, func(db.Store) error { return fn(s) })
+}
+
+func createTestTransactions() []processor.Transaction {
+	return []processor.Transaction{
+		{
+			Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.NewFromFloat(-1000),
+			Description: "ICA Kvantum",
+			Reference:   "5490990004",
+			Source:      "SEB",
+			RawData:     map[string]any{"Balance": "2814.160"},
+		},
+		{
+			Date:        time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.NewFromFloat(25000),
+			Description: "Lön",
+			Reference:   "5490990005",
+			Source:      "SEB",
+		},
+	}
+}
+
+func TestImporter_Import(t *testing.T) {
+	tests := []struct {
+		name       string
+		proc       processor.DocumentProcessor
+		opts       ImportOptions
+		wantStored int
+		wantFound  int
+		wantErr    string
+	}{
+		{
+			name:       "Successfully_import_and_store_transactions",
+			proc:       &stubProcessor{transactions: createTestTransactions()},
+			opts:       ImportOptions{Currency: db.CurrencySEK},
+			wantStored: 2,
+			wantFound:  2,
+		},
+		{
+			name:       "Successfully_dry_run_without_storing",
+			proc:       &stubProcessor{transactions: createTestTransactions()},
+			opts:       ImportOptions{Currency: db.CurrencyEUR, DryRun: true},
+			wantStored: 0,
+			wantFound:  2,
+		},
+		{
+			name:    "Import_error_missing_currency",
+			proc:    &stubProcessor{transactions: createTestTransactions()},
+			opts:    ImportOptions{},
+			wantErr: "currency is required",
+		},
+		{
+			name:    "Import_error_unknown_currency",
+			proc:    &stubProcessor{transactions: createTestTransactions()},
+			opts:    ImportOptions{Currency: "KR"},
+			wantErr: "not an ISO 4217 currency code",
+		},
+		{
+			name:    "Import_error_parse_failed",
+			proc:    &stubProcessor{err: errors.New("bad header")},
+			opts:    ImportOptions{Currency: db.CurrencySEK},
+			wantErr: "parse operation failed: bad header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := db.NewMockStore()
+			importer := NewImporter(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			// TODO: Replace context.TODO() with a test context carrying deadlines
+			result, err := importer.Import(context.TODO(), tt.proc, strings.NewReader(""), tt.opts)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("Import() error = nil, want %q", tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Import() error = %q, want %q", err.Error(), tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Import() unexpected error: %v", err)
+			}
+
+			if result.Stored != tt.wantStored {
+				t.Errorf("Import() stored = %d, want %d", result.Stored, tt.wantStored)
+			}
+			if len(result.Transactions) != tt.wantFound {
+				t.Errorf("Import() transactions = %d, want %d", len(result.Transactions), tt.wantFound)
+			}
+
+			stored, _ := store.ListTransactions(context.TODO(), nil)
+			if len(stored) != tt.wantStored {
+				t.Errorf("store contains %d transactions, want %d", len(stored), tt.wantStored)
+			}
+			for _, tx := range result.Transactions {
+				if tx.Currency != tt.opts.Currency {
+					t.Errorf("transaction currency = %s, want %s", tx.Currency, tt.opts.Currency)
+				}
+				if tx.Date.IsZero() {
+					t.Error("transaction date is zero")
+				}
+			}
+		})
+	}
+}
+
+func TestImporter_Import_Successfully_report_rejected_rows(t *testing.T) {
+	rejected := []processor.RowError{{Line: 3, Reason: "parse_amount: invalid amount format 'abc'"}}
+	tests := []struct {
+		name    string
+		proc    *rejectingProcessor
+		wantErr bool
+	}{
+		{
+			name: "Successfully_report_rows_skipped_during_import",
+			proc: &rejectingProcessor{stubProcessor: stubProcessor{transactions: createTestTransactions()}, rejected: rejected},
+		},
+		{
+			name:    "Successfully_report_rows_when_parsing_fails",
+			proc:    &rejectingProcessor{stubProcessor: stubProcessor{err: errors.New("no valid transactions found")}, rejected: rejected},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			importer := NewImporter(db.NewMockStore(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+			result, err := importer.Import(context.TODO(), tt.proc, strings.NewReader(""), ImportOptions{Currency: db.CurrencySEK, DryRun: true})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Import() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result == nil {
+				t.Fatal("Import() result = nil, want rejected rows")
+			}
+			if len(result.Rejected) != 1 || result.Rejected[0] != rejected[0] {
+				t.Errorf("Import() rejected = %v, want %v", result.Rejected, rejected)
+			}
+		})
+	}
+}
+
+func TestConvertTransaction_Successfully_prefer_document_currency(t *testing.T) {
+	tests := []struct {
+		name         string
+		currency     string
+		wantCurrency string
+	}{
+		{name: "Successfully_use_default_currency", currency: "", wantCurrency: db.CurrencySEK},
+		{name: "Successfully_use_document_currency", currency: db.CurrencyEUR, wantCurrency: db.CurrencyEUR},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := createTestTransactions()[0]
+			tx.Currency = tt.currency
+
+			got, err := ConvertTransaction(tx, db.CurrencySEK)
+			if err != nil {
+				t.Fatalf("ConvertTransaction() unexpected error: %v", err)
+			}
+			if got.Currency != tt.wantCurrency {
+				t.Errorf("ConvertTransaction() currency = %s, want %s", got.Currency, tt.wantCurrency)
+			}
+		})
+	}
+}
+
+func TestImporter_Import_Successfully_map_categories(t *testing.T) {
+	// TODO: Replace context.TODO() with a test context carrying deadlines
+	ctx := context.TODO()
+	store := db.NewMockStore()
+	food := &db.Category{Name: "Mat", TypeID: 1, IsActive: true}
+	if err := store.CreateCategory(ctx, food); err != nil {
+		t.Fatalf("CreateCategory() unexpected error: %v", err)
+	}
+	groceries := &db.Subcategory{Name: "Groceries", IsActive: true}
+	if err := store.CreateSubcategory(ctx, groceries); err != nil {
+		t.Fatalf("CreateSubcategory() unexpected error: %v", err)
+	}
+
+	mapper, err := NewCategoryMapper(ctx, store, map[string]string{"Food": "Mat"})
+	if err != nil {
+		t.Fatalf("NewCategoryMapper() unexpected error: %v", err)
+	}
+
+	transactions := createTestTransactions()
+	transactions[0].Category = "Food"
+	transactions[0].SubCategory = "Groceries"
+	transactions[1].Category = "Unknown"
+
+	importer := NewImporter(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	result, err := importer.Import(ctx, &stubProcessor{transactions: transactions}, strings.NewReader(""), ImportOptions{
+		Currency:   db.CurrencySEK,
+		Categories: mapper,
+		DryRun:     true,
+	})
+	if err != nil {
+		t.Fatalf("Import() unexpected error: %v", err)
+	}
+
+	if result.Categorized != 1 {
+		t.Errorf("Import() categorized = %d, want 1", result.Categorized)
+	}
+	mapped := result.Transactions[0]
+	if mapped.CategoryID == nil || *mapped.CategoryID != food.ID {
+		t.Errorf("transaction category = %v, want %d", mapped.CategoryID, food.ID)
+	}
+	if mapped.SubcategoryID == nil || *mapped.SubcategoryID != groceries.ID {
+		t.Errorf("transaction subcategory = %v, want %d", mapped.SubcategoryID, groceries.ID)
+	}
+	if result.Transactions[1].CategoryID != nil {
+		t.Errorf("unknown category should not be mapped, got %d", *result.Transactions[1].CategoryID)
+	}
+}
+
+func TestImporter_Import_Successfully_undo_import_batch(t *testing.T) {
+	store := db.NewMockStore()
+	importer := NewImporter(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	proc := &rejectingProcessor{
+		stubProcessor: stubProcessor{transactions: createTestTransactions()},
+		rejected:      []processor.RowError{{Line: 4, Reason: "parse_date: invalid date format ''"}},
+	}
+
+	batch := &db.ImportBatch{FileName: "statement.csv", FileHash: "abc123", Parser: "seb"}
+	if _, err := importer.Import(context.TODO(), proc, strings.NewReader(""), ImportOptions{Currency: db.CurrencySEK, Batch: batch}); err != nil {
+		t.Fatalf("Import() unexpected error: %v", err)
+	}
+
+	got, err := store.GetImportBatchByID(context.TODO(), batch.ID)
+	if err != nil {
+		t.Fatalf("GetImportBatchByID() unexpected error: %v", err)
+	}
+	if got.RowsParsed != 2 || got.RowsStored != 2 || got.RowsRejected != 1 || got.CompletedAt == nil {
+		t.Errorf("import batch = %+v, want 2 parsed, 2 stored, 1 rejected and completed", got)
+	}
+	stored, _ := store.ListTransactions(context.TODO(), nil)
+	for _, tx := range stored {
+		if tx.ImportBatchID == nil || *tx.ImportBatchID != batch.ID {
+			t.Errorf("transaction %d ImportBatchID = %v, want %d", tx.ID, tx.ImportBatchID, batch.ID)
+		}
+	}
+
+	deleted, err := store.DeleteImportBatch(context.TODO(), batch.ID)
+	if err != nil {
+		t.Fatalf("DeleteImportBatch() unexpected error: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("DeleteImportBatch() deleted = %d, want 2", deleted)
+	}
+	if remaining, _ := store.ListTransactions(context.TODO(), nil); len(remaining) != 0 {
+		t.Errorf("store contains %d transactions after undo, want 0", len(remaining))
+	}
+}
+
+func TestImporter_Import_Error_roll_back_failed_import(t *testing.T) {
+	// Setup
+	store := &failingStore{MockStore: db.NewMockStore(), failDescription: "Lön"}
+	importer := NewImporter(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	batch := &db.ImportBatch{FileName: "statement.csv", FileHash: "abc123", Parser: "seb"}
+
+	// Execute
+	result, err := importer.Import(context.TODO(), &stubProcessor{transactions: createTestTransactions()},
+		strings.NewReader(""), ImportOptions{Currency: db.CurrencySEK, Batch: batch})
+
+	// Verify
+	if err == nil || !strings.Contains(err.Error(), "import rolled back") {
+		t.Fatalf("Import() error = %v, want a rolled back import", err)
+	}
+	if result.Stored != 0 {
+		t.Errorf("Import() stored = %d, want 0", result.Stored)
+	}
+	if stored, _ := store.ListTransactions(context.TODO(), nil); len(stored) != 0 {
+		t.Errorf("store contains %d transactions after rollback, want 0", len(stored))
+	}
+	if batches, _ := store.ListImportBatches(context.TODO()); len(batches) != 0 {
+		t.Errorf("store contains %d import batches after rollback, want 0", len(batches))
+	}
+}
+
+func TestImporter_Import_Successfully_bind_accounts(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMockStore()
+	checking := &db.Account{Name: "SEB Lönekonto", Number: "5490 99-000 01", Type: db.AccountTypeChecking, Currency: db.CurrencySEK}
+	card := &db.Account{Name: "Eurocard", Number: "SE45 5000 0000 0583 9825 7466", Type: db.AccountTypeCreditCard, Currency: db.CurrencySEK}
+	for _, account := range []*db.Account{checking, card} {
+		if err := store.CreateAccount(ctx, account); err != nil {
+			t.Fatalf("CreateAccount() unexpected error: %v", err)
+		}
+	}
+
+	transactions := createTestTransactions()
+	transactions[0].RawData = map[string]any{"Account": "SE4550000000058398257466"}
+	transactions[1].RawData = map[string]any{"Account": "1234 56 789"}
+
+	tests := []struct {
+		name      string
+		accountID *uint
+		want      []*uint
+	}{
+		{name: "Successfully_bind_by_stated_account_number", want: []*uint{&card.ID, nil}},
+		{name: "Successfully_bind_to_given_account", accountID: &checking.ID, want: []*uint{&checking.ID, &checking.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			importer := NewImporter(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			result, err := importer.Import(ctx, &stubProcessor{transactions: transactions}, strings.NewReader(""), ImportOptions{
+				Currency:  db.CurrencySEK,
+				AccountID: tt.accountID,
+				DryRun:    true,
+			})
+			if err != nil {
+				t.Fatalf("Import() unexpected error: %v", err)
+			}
+			for i, want := range tt.want {
+				got := result.Transactions[i].AccountID
+				if (got == nil) != (want == nil) || (got != nil && *got != *want) {
+					t.Errorf("transaction[%d] account = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestImporter_Import_Successfully_convert_to_base_currency(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMockStore()
+	createExchangeRates(t, ctx, store)
+	transactions := createTestTransactions()
+	for i := range transactions {
+		transactions[i].Date = time.Date(2026, 9, 4+i, 0, 0, 0, 0, time.UTC)
+	}
+
+	importer := NewImporter(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	result, err := importer.Import(ctx, &stubProcessor{transactions: transactions}, strings.NewReader(""), ImportOptions{
+		Currency:     db.CurrencyEUR,
+		BaseCurrency: db.CurrencySEK,
+	})
+
+	if err != nil {
+		t.Fatalf("Import() unexpected error: %v", err)
+	}
+	for i, want := range []string{"-11200", "280000"} {
+		tx := result.Transactions[i]
+		if !tx.BaseAmount.Valid || !tx.BaseAmount.Decimal.Equal(decimal.RequireFromString(want)) || tx.BaseCurrency != db.CurrencySEK {
+			t.Errorf("transaction[%d] base amount = %v %s, want %s SEK", i, tx.BaseAmount, tx.BaseCurrency, want)
+		}
+	}
+}
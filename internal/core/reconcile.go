@@ -0,0 +1,388 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/lindehoff/Budget-Assist/internal/processor"
+	"github.com/shopspring/decimal"
+)
+
+// StatementBalances returns the balances a parsed statement states: those the
+// parser reads from the statement, such as the camt and MT940 balances, or else
+// the ones that follow from the running balance of its rows
+func StatementBalances(proc processor.DocumentProcessor, transactions []processor.Transaction) []processor.StatementBalance {
+	if provider, ok := proc.(processor.BalanceProvider); ok {
+		if balances := provider.Balances(); len(balances) > 0 {
+			return balances
+		}
+	}
+	return processor.RunningBalances(transactions)
+}
+
+// StoreStatementBalances records the balances of an imported statement, so that
+// the account can be reconciled later. Balances are bound to accounts the way
+// transactions are, see BindAccounts. Balances of unknown accounts and balances
+// that are recorded already are left out. It returns the number of balances recorded.
+func StoreStatementBalances(ctx context.Context, store db.Store, balances []processor.StatementBalance, accountID, batchID *uint) (int, error) {
+	if len(balances) == 0 {
+		return 0, nil
+	}
+	accounts, err := store.ListAccounts(ctx)
+	if err != nil {
+		return 0, NewOperationError("store_balances", err)
+	}
+	byID := make(map[uint]db.Account, len(accounts))
+	for _, account := range accounts {
+		byID[account.ID] = account
+	}
+	byNumber := accountsByNumber(accounts)
+
+	recorded := make(map[uint][]db.StatementBalance)
+	stored := 0
+	for _, balance := range balances {
+		id, ok := byNumber[db.NormalizeAccountNumber(balance.Account)]
+		if accountID != nil {
+			id, ok = *accountID, true
+		}
+		account, known := byID[id]
+		if !ok || !known {
+			continue
+		}
+
+		existing, loaded := recorded[id]
+		if !loaded {
+			if existing, err = store.ListStatementBalances(ctx, id); err != nil {
+				return stored, NewOperationError("store_balances", err)
+			}
+		}
+		if slices.ContainsFunc(existing, func(b db.StatementBalance) bool {
+			return b.Type == balance.Type && bookingDay(b.Date).Equal(bookingDay(balance.Date)) && b.Amount.Equal(balance.Amount)
+		}) {
+			recorded[id] = existing
+			continue
+		}
+
+		record := db.StatementBalance{
+			AccountID:     id,
+			Date:          balance.Date,
+			Type:          balance.Type,
+			Amount:        balance.Amount,
+			Currency:      balance.Currency,
+			ImportBatchID: batchID,
+		}
+		if record.Currency == "" {
+			record.Currency = account.Currency
+		}
+		if err := store.CreateStatementBalance(ctx, &record); err != nil {
+			return stored, NewOperationError("store_balances", err)
+		}
+		recorded[id] = append(existing, record)
+		stored++
+	}
+	return stored, nil
+}
+
+// ParsePeriod parses a month (2026-09) or a year (2026) and returns its first
+// day and the first day after it
+func ParsePeriod(value string) (time.Time, time.Time, error) {
+	value = strings.TrimSpace(value)
+	if start, err := time.Parse("2006-01", value); err == nil {
+		return start, start.AddDate(0, 1, 0), nil
+	}
+	if start, err := time.Parse("2006", value); err == nil {
+		return start, start.AddDate(1, 0, 0), nil
+	}
+	return time.Time{}, time.Time{}, NewValidationError("period", value, "must be a month (YYYY-MM) or a year (YYYY)")
+}
+
+// ReconcileOptions contains the balances to reconcile against when the
+// statement balances were not imported, e.g. for PDF statements
+type ReconcileOptions struct {
+	Opening decimal.NullDecimal // Balance at the start of the period
+	Closing decimal.NullDecimal // Balance at the end of the period
+}
+
+// ReconcileCandidate is a transaction that may be missing from, or stored twice
+// in, the reconciled period
+type ReconcileCandidate struct {
+	Date          time.Time
+	Amount        decimal.Decimal
+	Description   string
+	Reason        string
+	TransactionID uint // Stored transaction the candidate refers to, zero for a gap in the running balance
+	ClosesGap     bool // Adding the missing or removing the duplicated amount resolves the discrepancy
+}
+
+// Reconciliation is the result of comparing the stored transactions of an
+// account with its statement balances
+type Reconciliation struct {
+	Account      db.Account
+	From         time.Time // Day of the opening balance
+	To           time.Time // Day after the closing balance
+	Opening      decimal.Decimal
+	Closing      decimal.Decimal
+	Sum          decimal.Decimal // Sum of the transactions booked from From until To
+	Transactions int
+	Discrepancy  decimal.Decimal // Closing - (Opening + Sum)
+	Missing      []ReconcileCandidate
+	Duplicated   []ReconcileCandidate
+}
+
+// OK reports whether the stored transactions agree with the balances
+func (r *Reconciliation) OK() bool {
+	return r.Discrepancy.IsZero()
+}
+
+// balancePoint is a balance at the start of a day
+type balancePoint struct {
+	at     time.Time
+	amount decimal.Decimal
+	id     uint // Statement balance id, later imports win over earlier ones
+}
+
+// Reconcile compares the transactions stored for the account in the period
+// from start until end with the balances stated at its start and end. The
+// earliest balance within the period is used as opening balance and the latest
+// as closing balance, so a period is reconciled as far as statements cover it.
+// The opening balance of the account counts as a statement balance. When the
+// transactions do not add up, the transactions that may be missing or stored
+// twice are listed.
+func Reconcile(ctx context.Context, store db.Store, account db.Account, start, end time.Time, opts ReconcileOptions) (*Reconciliation, error) {
+	opening, closing, err := reconcileBalances(ctx, store, account, start, end, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dates are compared by day below, the store filter only narrows the search
+	from, to := opening.at.AddDate(0, 0, -1), closing.at.AddDate(0, 0, 1)
+	transactions, err := store.ListTransactions(ctx, &db.TransactionFilter{StartDate: &from, EndDate: &to, AccountIDs: []uint{account.ID}})
+	if err != nil {
+		return nil, NewResourceOperationError("reconcile", account.Name, err)
+	}
+	// Unbound transactions may belong to the account, they are listed as missing
+	unboundTransactions, err := store.ListTransactions(ctx, &db.TransactionFilter{StartDate: &from, EndDate: &to, Unbound: true})
+	if err != nil {
+		return nil, NewResourceOperationError("reconcile", account.Name, err)
+	}
+	transactions = append(transactions, unboundTransactions...)
+	sort.SliceStable(transactions, func(i, j int) bool {
+		if !transactions[i].Date.Equal(transactions[j].Date) {
+			return transactions[i].Date.Before(transactions[j].Date)
+		}
+		return transactions[i].ID < transactions[j].ID
+	})
+
+	result := &Reconciliation{
+		Account: account,
+		From:    opening.at,
+		To:      closing.at,
+		Opening: opening.amount,
+		Closing: closing.amount,
+		Sum:     decimal.Zero,
+	}
+	var counted, flagged, unbound []db.Transaction
+	for _, tx := range transactions {
+		day := bookingDay(tx.Date)
+		if day.Before(result.From) || !day.Before(result.To) {
+			continue
+		}
+		switch {
+		case tx.AccountID == nil:
+			unbound = append(unbound, tx)
+		case tx.DuplicateOfID != nil:
+			// Flagged duplicates repeat a transaction that is counted already
+			flagged = append(flagged, tx)
+		default:
+			counted = append(counted, tx)
+			result.Sum = result.Sum.Add(tx.Amount)
+		}
+	}
+	result.Transactions = len(counted)
+	result.Discrepancy = result.Closing.Sub(result.Opening.Add(result.Sum))
+	if result.OK() {
+		return result, nil
+	}
+
+	result.Missing = runningBalanceGaps(result, counted)
+	for _, tx := range flagged {
+		result.Missing = append(result.Missing, candidate(tx, fmt.Sprintf("flagged as a duplicate of transaction %d", *tx.DuplicateOfID), result.Discrepancy))
+	}
+	for _, tx := range unbound {
+		if tx.Currency == account.Currency && tx.Amount.Equal(result.Discrepancy) {
+			result.Missing = append(result.Missing, candidate(tx, "not bound to an account", result.Discrepancy))
+		}
+	}
+	result.Duplicated = duplicateCandidates(counted, result.Discrepancy.Neg())
+	return result, nil
+}
+
+// reconcileBalances selects the opening and closing balance of the period
+func reconcileBalances(ctx context.Context, store db.Store, account db.Account, start, end time.Time, opts ReconcileOptions) (balancePoint, balancePoint, error) {
+	var points []balancePoint
+	if !account.OpeningDate.IsZero() {
+		points = append(points, balancePoint{at: bookingDay(account.OpeningDate), amount: account.OpeningBalance})
+	}
+	balances, err := store.ListStatementBalances(ctx, account.ID)
+	if err != nil {
+		return balancePoint{}, balancePoint{}, NewResourceOperationError("reconcile", account.Name, err)
+	}
+	for _, balance := range balances {
+		points = append(points, balancePoint{at: balance.Instant(), amount: balance.Amount, id: balance.ID})
+	}
+
+	opening := balancePoint{at: start, amount: opts.Opening.Decimal}
+	if !opts.Opening.Valid {
+		found := false
+		for _, p := range points {
+			if p.at.Before(start) || !p.at.Before(end) {
+				continue
+			}
+			if !found || p.at.Before(opening.at) || (p.at.Equal(opening.at) && p.id > opening.id) {
+				opening, found = p, true
+			}
+		}
+		if !found {
+			return opening, opening, NewResourceOperationError("reconcile", account.Name,
+				fmt.Errorf("%w between %s and %s", ErrNoBalance, start.Format("2006-01-02"), end.AddDate(0, 0, -1).Format("2006-01-02")))
+		}
+	}
+
+	closing := balancePoint{at: end, amount: opts.Closing.Decimal}
+	if !opts.Closing.Valid {
+		found := false
+		for _, p := range points {
+			if !p.at.After(opening.at) || p.at.After(end) {
+				continue
+			}
+			if !found || p.at.After(closing.at) || (p.at.Equal(closing.at) && p.id > closing.id) {
+				closing, found = p, true
+			}
+		}
+		if !found {
+			return opening, closing, NewResourceOperationError("reconcile", account.Name,
+				fmt.Errorf("%w after the opening balance of %s up to %s", ErrNoBalance, opening.at.Format("2006-01-02"), end.AddDate(0, 0, -1).Format("2006-01-02")))
+		}
+	}
+	return opening, closing, nil
+}
+
+// runningBalanceGaps follows the running balance of the transactions from the
+// opening to the closing balance and returns the amounts missing where it
+// breaks. Rows booked on the same day are taken in the order their balances
+// continue each other, since the order they were stored in is not reliable.
+// It applies only when every transaction states a balance.
+func runningBalanceGaps(r *Reconciliation, transactions []db.Transaction) []ReconcileCandidate {
+	for _, tx := range transactions {
+		if !tx.Balance.Valid {
+			return nil
+		}
+	}
+
+	var gaps []ReconcileCandidate
+	remaining := slices.Clone(transactions)
+	balance := r.Opening
+	for len(remaining) > 0 {
+		day := bookingDay(remaining[0].Date)
+		next := -1
+		for i, tx := range remaining {
+			if !bookingDay(tx.Date).Equal(day) {
+				break
+			}
+			if tx.Balance.Decimal.Sub(tx.Amount).Equal(balance) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			next = chainStart(remaining, day)
+			tx := remaining[next]
+			missing := tx.Balance.Decimal.Sub(tx.Amount).Sub(balance)
+			gaps = append(gaps, ReconcileCandidate{
+				Date:      tx.Date,
+				Amount:    missing,
+				Reason:    fmt.Sprintf("gap in the running balance before %q", tx.Description),
+				ClosesGap: missing.Equal(r.Discrepancy),
+			})
+		}
+		balance = remaining[next].Balance.Decimal
+		remaining = slices.Delete(remaining, next, next+1)
+	}
+
+	if len(transactions) > 0 && !balance.Equal(r.Closing) {
+		missing := r.Closing.Sub(balance)
+		gaps = append(gaps, ReconcileCandidate{
+			Date:      r.To.AddDate(0, 0, -1),
+			Amount:    missing,
+			Reason:    "gap in the running balance before the closing balance",
+			ClosesGap: missing.Equal(r.Discrepancy),
+		})
+	}
+	return gaps
+}
+
+// chainStart returns the index of the first row booked on the day that no
+// other row of the day leads up to
+func chainStart(transactions []db.Transaction, day time.Time) int {
+	for i, tx := range transactions {
+		if !bookingDay(tx.Date).Equal(day) {
+			break
+		}
+		before := tx.Balance.Decimal.Sub(tx.Amount)
+		continues := false
+		for j, other := range transactions {
+			if !bookingDay(other.Date).Equal(day) {
+				break
+			}
+			continues = continues || (i != j && other.Balance.Decimal.Equal(before))
+		}
+		if !continues {
+			return i
+		}
+	}
+	return 0
+}
+
+// duplicateCandidates returns the transactions that repeat the day, amount and
+// description of an earlier transaction
+func duplicateCandidates(transactions []db.Transaction, surplus decimal.Decimal) []ReconcileCandidate {
+	type key struct {
+		day         time.Time
+		amount      string
+		description string
+	}
+	seen := make(map[key]uint, len(transactions))
+	var candidates []ReconcileCandidate
+	for _, tx := range transactions {
+		k := key{bookingDay(tx.Date), tx.Amount.String(), strings.ToLower(strings.TrimSpace(tx.Description))}
+		if original, ok := seen[k]; ok {
+			candidates = append(candidates, candidate(tx, fmt.Sprintf("same day, amount and description as transaction %d", original), surplus))
+			continue
+		}
+		seen[k] = tx.ID
+	}
+	return candidates
+}
+
+// candidate describes a stored transaction whose amount resolves the
+// discrepancy when it equals gap
+func candidate(tx db.Transaction, reason string, gap decimal.Decimal) ReconcileCandidate {
+	return ReconcileCandidate{
+		Date:          tx.Date,
+		Amount:        tx.Amount,
+		Description:   tx.Description,
+		Reason:        reason,
+		TransactionID: tx.ID,
+		ClosesGap:     tx.Amount.Equal(gap),
+	}
+}
+
+// bookingDay returns the day a date falls on, as stored dates carry no time of day
+func bookingDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
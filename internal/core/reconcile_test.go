@@ -0,0 +1,258 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/lindehoff/Budget-Assist/internal/processor"
+	"github.com/shopspring/decimal"
+)
+
+// importStatement imports SEB rows, newest first, onto a new account and
+// returns the account with its stored transactions by description
+func importStatement(t *testing.T, ctx context.Context, store *db.MockStore) (db.Account, map[string]db.Transaction) {
+	t.Helper()
+	account := db.Account{Name: "SEB Lönekonto", Type: db.AccountTypeChecking, Currency: db.CurrencySEK}
+	if err := store.CreateAccount(ctx, &account); err != nil {
+		t.Fatalf("CreateAccount() unexpected error: %v", err)
+	}
+
+	row := func(day int, description, amount, balance string) processor.Transaction {
+		return processor.Transaction{
+			Date:        time.Date(2026, 9, day, 0, 0, 0, 0, time.UTC),
+			Description: description,
+			Amount:      decimal.RequireFromString(amount),
+			Balance:     decimal.NewNullDecimal(decimal.RequireFromString(balance)),
+			RawData:     map[string]any{},
+		}
+	}
+	rows := []processor.Transaction{
+		row(28, "Hyra", "-8000.00", "17900.00"),
+		row(25, "Lön", "25000.00", "25900.00"),
+		row(3, "ICA Maxi", "-100.00", "900.00"),
+	}
+
+	importer := NewImporter(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	result, err := importer.Import(ctx, &stubProcessor{transactions: rows}, strings.NewReader(""), ImportOptions{
+		Currency:  db.CurrencySEK,
+		AccountID: &account.ID,
+	})
+	if err != nil {
+		t.Fatalf("Import() unexpected error: %v", err)
+	}
+	stored := make(map[string]db.Transaction)
+	for _, tx := range result.Transactions {
+		stored[tx.Description] = tx
+	}
+	return account, stored
+}
+
+func TestReconcile(t *testing.T) {
+	start, end, err := ParsePeriod("2026-09")
+	if err != nil {
+		t.Fatalf("ParsePeriod() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		change          func(t *testing.T, store *db.MockStore, account db.Account, stored map[string]db.Transaction)
+		wantDiscrepancy string
+		wantMissing     []string
+		wantDuplicated  []string
+	}{
+		{
+			name:            "Successfully_reconcile_imported_statement",
+			wantDiscrepancy: "0",
+		},
+		{
+			name: "Successfully_find_missing_transaction",
+			change: func(t *testing.T, store *db.MockStore, account db.Account, stored map[string]db.Transaction) {
+				if err := store.DeleteTransaction(context.Background(), stored["Lön"].ID); err != nil {
+					t.Fatalf("DeleteTransaction() unexpected error: %v", err)
+				}
+			},
+			wantDiscrepancy: "25000",
+			wantMissing:     []string{"25000.00 gap in the running balance before \"Hyra\""},
+		},
+		{
+			name: "Successfully_find_duplicated_transaction",
+			change: func(t *testing.T, store *db.MockStore, account db.Account, stored map[string]db.Transaction) {
+				copied := stored["ICA Maxi"]
+				copied.ID = 0
+				copied.Fingerprint = ""
+				if err := store.CreateTransaction(context.Background(), &copied); err != nil {
+					t.Fatalf("CreateTransaction() unexpected error: %v", err)
+				}
+			},
+			wantDiscrepancy: "100",
+			wantMissing:     []string{"100.00 gap in the running balance before \"ICA Maxi\""},
+			wantDuplicated:  []string{"-100.00 same day, amount and description as transaction"},
+		},
+		{
+			name: "Successfully_find_transaction_without_account",
+			change: func(t *testing.T, store *db.MockStore, account db.Account, stored map[string]db.Transaction) {
+				salary := stored["Lön"]
+				if err := store.DeleteTransaction(context.Background(), salary.ID); err != nil {
+					t.Fatalf("DeleteTransaction() unexpected error: %v", err)
+				}
+				salary.ID = 0
+				salary.AccountID = nil
+				salary.Balance = decimal.NullDecimal{}
+				if err := store.CreateTransaction(context.Background(), &salary); err != nil {
+					t.Fatalf("CreateTransaction() unexpected error: %v", err)
+				}
+			},
+			wantDiscrepancy: "25000",
+			wantMissing: []string{
+				"25000.00 gap in the running balance before \"Hyra\"",
+				"25000.00 not bound to an account",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			ctx := context.Background()
+			store := db.NewMockStore()
+			account, stored := importStatement(t, ctx, store)
+			if tt.change != nil {
+				tt.change(t, store, account, stored)
+			}
+
+			// Execute
+			result, err := Reconcile(ctx, store, account, start, end, ReconcileOptions{})
+
+			// Verify
+			if err != nil {
+				t.Fatalf("Reconcile() unexpected error: %v", err)
+			}
+			if result.From.Day() != 3 || result.To.Day() != 29 {
+				t.Errorf("Reconcile() covered %s to %s, want the statement from 2026-09-03 until 2026-09-29",
+					result.From.Format("2006-01-02"), result.To.Format("2006-01-02"))
+			}
+			if !result.Opening.Equal(decimal.RequireFromString("1000")) || !result.Closing.Equal(decimal.RequireFromString("17900")) {
+				t.Errorf("Reconcile() balances = %s to %s, want 1000 to 17900", result.Opening, result.Closing)
+			}
+			if !result.Discrepancy.Equal(decimal.RequireFromString(tt.wantDiscrepancy)) {
+				t.Errorf("Discrepancy = %s, want %s", result.Discrepancy, tt.wantDiscrepancy)
+			}
+			assertCandidates(t, "missing", result.Missing, tt.wantMissing)
+			assertCandidates(t, "duplicated", result.Duplicated, tt.wantDuplicated)
+		})
+	}
+}
+
+// assertCandidates checks that each candidate starts with the wanted amount
+// and reason and resolves the discrepancy
+func assertCandidates(t *testing.T, kind string, got []ReconcileCandidate, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s candidates = %+v, want %d", kind, got, len(want))
+	}
+	for i, candidate := range got {
+		if text := candidate.Amount.StringFixed(2) + " " + candidate.Reason; !strings.HasPrefix(text, want[i]) {
+			t.Errorf("%s candidate %d = %q, want %q", kind, i, text, want[i])
+		}
+		if !candidate.ClosesGap {
+			t.Errorf("%s candidate %d does not close the gap", kind, i)
+		}
+	}
+}
+
+func TestReconcile_Successfully_use_given_balances(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMockStore()
+	account, _ := importStatement(t, ctx, store)
+	start, end, _ := ParsePeriod("2026-09")
+
+	result, err := Reconcile(ctx, store, account, start, end, ReconcileOptions{
+		Opening: decimal.NewNullDecimal(decimal.RequireFromString("1000")),
+		Closing: decimal.NewNullDecimal(decimal.RequireFromString("17800")),
+	})
+
+	if err != nil {
+		t.Fatalf("Reconcile() unexpected error: %v", err)
+	}
+	if !result.From.Equal(start) || !result.To.Equal(end) {
+		t.Errorf("Reconcile() covered %s to %s, want the whole period", result.From, result.To)
+	}
+	if !result.Discrepancy.Equal(decimal.RequireFromString("-100")) {
+		t.Errorf("Discrepancy = %s, want -100", result.Discrepancy)
+	}
+}
+
+func TestReconcile_Error_no_balance(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMockStore()
+	account, _ := importStatement(t, ctx, store)
+
+	for _, period := range []string{"2026-08", "2026-10"} {
+		start, end, _ := ParsePeriod(period)
+		_, err := Reconcile(ctx, store, account, start, end, ReconcileOptions{})
+		if !errors.Is(err, ErrNoBalance) {
+			t.Errorf("Reconcile(%s) error = %v, want %v", period, err, ErrNoBalance)
+		}
+	}
+}
+
+func TestStoreStatementBalances_Successfully_skip_recorded_balances(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMockStore()
+	account := db.Account{Name: "Sparkonto", Number: "SE45 5000 0000 0583 9825 7466", Type: db.AccountTypeSavings, Currency: db.CurrencySEK}
+	if err := store.CreateAccount(ctx, &account); err != nil {
+		t.Fatalf("CreateAccount() unexpected error: %v", err)
+	}
+	balances := []processor.StatementBalance{
+		{Date: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), Amount: decimal.RequireFromString("100"), Account: "SE4550000000058398257466", Type: processor.BalanceOpening},
+		{Date: time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC), Amount: decimal.RequireFromString("250"), Account: "SE4550000000058398257466", Type: processor.BalanceClosing},
+		{Date: time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC), Amount: decimal.RequireFromString("5"), Account: "SE0000000000000000000001", Type: processor.BalanceClosing},
+	}
+
+	for _, want := range []int{2, 0} {
+		stored, err := StoreStatementBalances(ctx, store, balances, nil, nil)
+		if err != nil {
+			t.Fatalf("StoreStatementBalances() unexpected error: %v", err)
+		}
+		if stored != want {
+			t.Errorf("StoreStatementBalances() stored %d balances, want %d", stored, want)
+		}
+	}
+	recorded, _ := store.ListStatementBalances(ctx, account.ID)
+	if len(recorded) != 2 || recorded[0].Currency != db.CurrencySEK {
+		t.Errorf("ListStatementBalances() = %+v, want the 2 balances in SEK", recorded)
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		value     string
+		wantStart string
+		wantEnd   string
+		wantErr   bool
+	}{
+		{value: "2026-09", wantStart: "2026-09-01", wantEnd: "2026-10-01"},
+		{value: "2026-12", wantStart: "2026-12-01", wantEnd: "2027-01-01"},
+		{value: "2026", wantStart: "2026-01-01", wantEnd: "2027-01-01"},
+		{value: "september", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			start, end, err := ParsePeriod(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePeriod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if start.Format("2006-01-02") != tt.wantStart || end.Format("2006-01-02") != tt.wantEnd {
+				t.Errorf("ParsePeriod() = %s, %s, want %s, %s", start.Format("2006-01-02"), end.Format("2006-01-02"), tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/shopspring/decimal"
+)
+
+// Uncategorized names the transactions without a category in summaries
+const Uncategorized = "Uncategorized"
+
+// SummaryOptions contains the options for summarizing a period
+type SummaryOptions struct {
+	// BaseCurrency is the currency the summary is in
+	BaseCurrency string
+	// AccountIDs limits the summary to transactions booked on the accounts; nil for all transactions
+	AccountIDs []uint
+}
+
+// CategorySummary is the income and expenses of one category
+type CategorySummary struct {
+	CategoryID   *uint
+	Category     string
+	Income       decimal.Decimal
+	Expenses     decimal.Decimal // Negative, as the amounts of expenses are
+	Transactions int
+}
+
+// Summary is the income and expenses of a period in the base currency.
+// Transfers between our own accounts and flagged duplicates are left out.
+type Summary struct {
+	From         time.Time // First day of the period
+	To           time.Time // Day after the period
+	BaseCurrency string
+	Income       decimal.Decimal
+	Expenses     decimal.Decimal
+	Net          decimal.Decimal
+	Transactions int
+	// Categories are ordered by expenses, largest first, then by income
+	Categories []CategorySummary
+	// Unconverted sums the amounts without an exchange rate to the base
+	// currency by currency; they are not part of the totals
+	Unconverted map[string]decimal.Decimal
+}
+
+// Summarize adds up the income and expenses booked from start until end by
+// category in the base currency. Amounts are converted with the base amount
+// stored for the transaction, or with the exchange rates when it has none in
+// the base currency.
+func Summarize(ctx context.Context, store db.Store, start, end time.Time, opts SummaryOptions) (*Summary, error) {
+	converter, err := NewCurrencyConverter(ctx, store, opts.BaseCurrency, &start, &end)
+	if err != nil {
+		return nil, err
+	}
+	// Dates are compared by day below, the store filter only narrows the search
+	from, to := start.AddDate(0, 0, -1), end.AddDate(0, 0, 1)
+	transactions, err := store.ListTransactions(ctx, &db.TransactionFilter{
+		StartDate:        &from,
+		EndDate:          &to,
+		AccountIDs:       opts.AccountIDs,
+		ExcludeTransfers: true,
+	})
+	if err != nil {
+		return nil, NewOperationError("summarize", err)
+	}
+	categories, err := store.ListCategories(ctx, nil)
+	if err != nil {
+		return nil, NewOperationError("summarize", err)
+	}
+	names := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+
+	summary := &Summary{
+		From:         start,
+		To:           end,
+		BaseCurrency: opts.BaseCurrency,
+		Income:       decimal.Zero,
+		Expenses:     decimal.Zero,
+		Unconverted:  make(map[string]decimal.Decimal),
+	}
+	byCategory := make(map[uint]*CategorySummary)
+	var uncategorized *CategorySummary
+	for _, tx := range transactions {
+		day := bookingDay(tx.Date)
+		if day.Before(start) || !day.Before(end) || tx.DuplicateOfID != nil {
+			continue
+		}
+		amount, ok := baseAmount(tx, converter)
+		if !ok {
+			summary.Unconverted[tx.Currency] = summary.Unconverted[tx.Currency].Add(tx.Amount)
+			continue
+		}
+
+		var category *CategorySummary
+		switch {
+		case tx.CategoryID == nil:
+			if uncategorized == nil {
+				uncategorized = &CategorySummary{Category: Uncategorized}
+			}
+			category = uncategorized
+		case byCategory[*tx.CategoryID] != nil:
+			category = byCategory[*tx.CategoryID]
+		default:
+			category = &CategorySummary{CategoryID: tx.CategoryID, Category: names[*tx.CategoryID]}
+			byCategory[*tx.CategoryID] = category
+		}
+		if amount.IsNegative() {
+			category.Expenses = category.Expenses.Add(amount)
+			summary.Expenses = summary.Expenses.Add(amount)
+		} else {
+			category.Income = category.Income.Add(amount)
+			summary.Income = summary.Income.Add(amount)
+		}
+		category.Transactions++
+		summary.Transactions++
+	}
+	summary.Net = summary.Income.Add(summary.Expenses)
+
+	for _, category := range byCategory {
+		summary.Categories = append(summary.Categories, *category)
+	}
+	if uncategorized != nil {
+		summary.Categories = append(summary.Categories, *uncategorized)
+	}
+	sort.Slice(summary.Categories, func(i, j int) bool {
+		a, b := summary.Categories[i], summary.Categories[j]
+		if !a.Expenses.Equal(b.Expenses) {
+			return a.Expenses.LessThan(b.Expenses)
+		}
+		if !a.Income.Equal(b.Income) {
+			return a.Income.GreaterThan(b.Income)
+		}
+		return a.Category < b.Category
+	})
+	return summary, nil
+}
+
+// baseAmount returns the amount of a transaction in the base currency of the converter
+func baseAmount(tx db.Transaction, converter *CurrencyConverter) (decimal.Decimal, bool) {
+	switch {
+	case tx.Currency == converter.Base():
+		return tx.Amount, true
+	case tx.BaseCurrency == converter.Base() && tx.BaseAmount.Valid:
+		return tx.BaseAmount.Decimal, true
+	default:
+		return converter.Convert(tx.Amount, tx.Currency, tx.Date)
+	}
+}
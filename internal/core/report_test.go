@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/shopspring/decimal"
+)
+
+func TestSummarize(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	store := db.NewMockStore()
+	createExchangeRates(t, ctx, store)
+	travel := &db.Category{Name: "Resor", TypeID: 1}
+	salary := &db.Category{Name: "Lön", TypeID: 1}
+	for _, category := range []*db.Category{travel, salary} {
+		if err := store.CreateCategory(ctx, category); err != nil {
+			t.Fatalf("CreateCategory() unexpected error: %v", err)
+		}
+	}
+	flagged := uint(1)
+	for _, tx := range []*db.Transaction{
+		{Description: "Lön", Date: time.Date(2026, 9, 25, 0, 0, 0, 0, time.UTC), Amount: decimal.RequireFromString("25000"), Currency: db.CurrencySEK, CategoryID: &salary.ID},
+		{Description: "Hotel", Date: time.Date(2026, 9, 4, 0, 0, 0, 0, time.UTC), Amount: decimal.RequireFromString("-100"), Currency: db.CurrencyEUR, CategoryID: &travel.ID},
+		{Description: "Taxi", Date: time.Date(2026, 9, 5, 0, 0, 0, 0, time.UTC), Amount: decimal.RequireFromString("-20"), Currency: db.CurrencyEUR, CategoryID: &travel.ID,
+			BaseAmount: decimal.NewNullDecimal(decimal.RequireFromString("-230")), BaseCurrency: db.CurrencySEK},
+		{Description: "ICA Maxi", Date: time.Date(2026, 9, 30, 23, 0, 0, 0, time.UTC), Amount: decimal.RequireFromString("-500"), Currency: db.CurrencySEK},
+		{Description: "ICA Maxi", Date: time.Date(2026, 9, 30, 23, 0, 0, 0, time.UTC), Amount: decimal.RequireFromString("-500"), Currency: db.CurrencySEK, DuplicateOfID: &flagged},
+		{Description: "Amazon", Date: time.Date(2026, 9, 20, 0, 0, 0, 0, time.UTC), Amount: decimal.RequireFromString("-30"), Currency: db.CurrencyUSD},
+		{Description: "Hyra", Date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Amount: decimal.RequireFromString("-8000"), Currency: db.CurrencySEK},
+	} {
+		if err := store.CreateTransaction(ctx, tx); err != nil {
+			t.Fatalf("CreateTransaction() unexpected error: %v", err)
+		}
+	}
+	ids := createTransferTestData(t, ctx, store)
+	if _, err := LinkTransfer(ctx, store, ids["Till sparkonto"], ids["Från lönekonto"]); err != nil {
+		t.Fatalf("LinkTransfer() unexpected error: %v", err)
+	}
+	start, end, _ := ParsePeriod("2026-09")
+
+	// Execute
+	summary, err := Summarize(ctx, store, start, end, SummaryOptions{BaseCurrency: db.CurrencySEK})
+
+	// Verify
+	if err != nil {
+		t.Fatalf("Summarize() unexpected error: %v", err)
+	}
+	// The transfer test data adds income of 200 + 1000 + 1000 + 5000 and expenses of 200 + 1000
+	for _, total := range []struct {
+		name string
+		got  decimal.Decimal
+		want string
+	}{
+		{"Income", summary.Income, "32200"},
+		{"Expenses", summary.Expenses, "-3050"},
+		{"Net", summary.Net, "29150"},
+	} {
+		if !total.got.Equal(decimal.RequireFromString(total.want)) {
+			t.Errorf("%s = %s, want %s", total.name, total.got, total.want)
+		}
+	}
+	if len(summary.Unconverted) != 1 || !summary.Unconverted[db.CurrencyUSD].Equal(decimal.RequireFromString("-30")) {
+		t.Errorf("Unconverted = %v, want -30 USD", summary.Unconverted)
+	}
+	if len(summary.Categories) != 3 {
+		t.Fatalf("Categories = %+v, want travel, uncategorized and salary", summary.Categories)
+	}
+	if first := summary.Categories[0]; first.Category != Uncategorized || first.Transactions != 7 {
+		t.Errorf("first category = %+v, want the 7 uncategorized transactions", first)
+	}
+	if second := summary.Categories[1]; second.Category != "Resor" || !second.Expenses.Equal(decimal.RequireFromString("-1350")) {
+		t.Errorf("second category = %+v, want travel expenses of -1350", second)
+	}
+}
+
+func TestSummarize_Successfully_limit_to_accounts(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMockStore()
+	createTransferTestData(t, ctx, store)
+	accounts, _ := store.ListAccounts(ctx)
+	var savings uint
+	for _, account := range accounts {
+		if account.Type == db.AccountTypeSavings {
+			savings = account.ID
+		}
+	}
+	start, end, _ := ParsePeriod("2026-09")
+
+	summary, err := Summarize(ctx, store, start, end, SummaryOptions{BaseCurrency: db.CurrencySEK, AccountIDs: []uint{savings}})
+
+	if err != nil {
+		t.Fatalf("Summarize() unexpected error: %v", err)
+	}
+	if summary.Transactions != 4 || !summary.Income.Equal(decimal.RequireFromString("7200")) {
+		t.Errorf("Summarize() = %d transactions with income %s, want 4 with 7200", summary.Transactions, summary.Income)
+	}
+}
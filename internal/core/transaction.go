@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/shopspring/decimal"
+)
+
+// TransactionUpdate contains the changes to a transaction; nil fields are left unchanged
+type TransactionUpdate struct {
+	Description *string
+	Amount      *decimal.Decimal
+	// SetCategory replaces the category and subcategory with CategoryID and
+	// SubcategoryID, removing them when those are nil
+	SetCategory   bool
+	CategoryID    *uint
+	SubcategoryID *uint
+}
+
+// ListTransactions returns the transactions matching the filter, newest first
+func ListTransactions(ctx context.Context, store db.Store, filter *db.TransactionFilter) ([]db.Transaction, error) {
+	transactions, err := store.ListTransactions(ctx, filter)
+	if err != nil {
+		return nil, NewOperationError("list_transactions", err)
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		if !transactions[i].Date.Equal(transactions[j].Date) {
+			return transactions[i].Date.After(transactions[j].Date)
+		}
+		return transactions[i].ID > transactions[j].ID
+	})
+	return transactions, nil
+}
+
+// EditTransaction changes the description, amount or category of a stored
+// transaction. A new amount is converted to the base currency the transaction
+// was converted to before. The amount of a transfer cannot be changed, as it
+// has to match the other side; unlink the transfer first.
+func EditTransaction(ctx context.Context, store db.Store, id uint, update TransactionUpdate) (*db.Transaction, error) {
+	tx, err := getTransaction(ctx, store, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Description != nil {
+		description := strings.TrimSpace(*update.Description)
+		if description == "" {
+			return nil, NewValidationError("description", *update.Description, "description cannot be empty")
+		}
+		tx.Description = description
+	}
+
+	if update.Amount != nil && !update.Amount.Equal(tx.Amount) {
+		if tx.IsTransfer() {
+			return nil, NewResourceOperationError("edit_transaction", fmt.Sprintf("transaction %d", id),
+				fmt.Errorf("%w: part of a transfer with transaction %d, unlink it to change the amount", ErrInvalidOperation, *tx.TransferOfID))
+		}
+		tx.Amount = *update.Amount
+		if tx.BaseCurrency != "" {
+			converter, err := NewCurrencyConverter(ctx, store, tx.BaseCurrency, &tx.Date, &tx.Date)
+			if err != nil {
+				return nil, err
+			}
+			converter.Apply(tx)
+		}
+	}
+
+	if update.SetCategory {
+		if update.CategoryID == nil && update.SubcategoryID != nil {
+			return nil, NewValidationError("subcategory", *update.SubcategoryID, "a subcategory needs a category")
+		}
+		if update.CategoryID != nil {
+			if _, err := store.GetCategoryByID(ctx, *update.CategoryID); err != nil {
+				return nil, lookupError("category", *update.CategoryID, err)
+			}
+		}
+		if update.SubcategoryID != nil {
+			if _, err := store.GetSubcategoryByID(ctx, *update.SubcategoryID); err != nil {
+				return nil, lookupError("subcategory", *update.SubcategoryID, err)
+			}
+		}
+		tx.CategoryID, tx.SubcategoryID = update.CategoryID, update.SubcategoryID
+	}
+
+	if err := store.UpdateTransaction(ctx, tx); err != nil {
+		return nil, NewResourceOperationError("edit_transaction", fmt.Sprintf("transaction %d", id), err)
+	}
+	return tx, nil
+}
+
+// lookupError wraps the error of looking up a resource by ID
+func lookupError(resource string, id uint, err error) error {
+	if errors.Is(err, db.ErrNotFound) {
+		return NewResourceOperationError("edit_transaction", fmt.Sprintf("%s %d", resource, id), ErrNotFound)
+	}
+	return NewOperationError("edit_transaction", err)
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/shopspring/decimal"
+)
+
+func TestEditTransaction(t *testing.T) {
+	description := func(value string) *string { return &value }
+	amount := func(value string) *decimal.Decimal {
+		d := decimal.RequireFromString(value)
+		return &d
+	}
+
+	tests := []struct {
+		name    string
+		edit    string // Description of the transaction to edit
+		update  func(categoryID, subcategoryID uint) TransactionUpdate
+		verify  func(t *testing.T, tx *db.Transaction, categoryID, subcategoryID uint)
+		wantErr bool
+		wantIs  error // Sentinel the error wraps, if any
+	}{
+		{
+			name: "Successfully_change_description",
+			edit: "ICA Maxi",
+			update: func(uint, uint) TransactionUpdate {
+				return TransactionUpdate{Description: description("  ICA Maxi Lindhagen ")}
+			},
+			verify: func(t *testing.T, tx *db.Transaction, _, _ uint) {
+				if tx.Description != "ICA Maxi Lindhagen" {
+					t.Errorf("Description = %q, want %q", tx.Description, "ICA Maxi Lindhagen")
+				}
+			},
+		},
+		{
+			name: "Successfully_change_amount_and_base_amount",
+			edit: "Hotel",
+			update: func(uint, uint) TransactionUpdate {
+				return TransactionUpdate{Amount: amount("-150")}
+			},
+			verify: func(t *testing.T, tx *db.Transaction, _, _ uint) {
+				if !tx.Amount.Equal(decimal.RequireFromString("-150")) || !tx.BaseAmount.Decimal.Equal(decimal.RequireFromString("-1680")) {
+					t.Errorf("amount = %s (%s %s), want -150 (-1680 SEK)", tx.Amount, tx.BaseAmount.Decimal, tx.BaseCurrency)
+				}
+			},
+		},
+		{
+			name: "Successfully_set_category",
+			edit: "ICA Maxi",
+			update: func(categoryID, subcategoryID uint) TransactionUpdate {
+				return TransactionUpdate{SetCategory: true, CategoryID: &categoryID, SubcategoryID: &subcategoryID}
+			},
+			verify: func(t *testing.T, tx *db.Transaction, categoryID, subcategoryID uint) {
+				if tx.CategoryID == nil || *tx.CategoryID != categoryID || tx.SubcategoryID == nil || *tx.SubcategoryID != subcategoryID {
+					t.Errorf("category = %v/%v, want %d/%d", tx.CategoryID, tx.SubcategoryID, categoryID, subcategoryID)
+				}
+			},
+		},
+		{
+			name: "Successfully_remove_category",
+			edit: "Hotel",
+			update: func(uint, uint) TransactionUpdate {
+				return TransactionUpdate{SetCategory: true}
+			},
+			verify: func(t *testing.T, tx *db.Transaction, _, _ uint) {
+				if tx.CategoryID != nil || tx.SubcategoryID != nil {
+					t.Errorf("category = %v/%v, want none", tx.CategoryID, tx.SubcategoryID)
+				}
+			},
+		},
+		{
+			name: "Error_empty_description",
+			edit: "ICA Maxi",
+			update: func(uint, uint) TransactionUpdate {
+				return TransactionUpdate{Description: description(" ")}
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error_change_transfer_amount",
+			edit: "Till sparkonto",
+			update: func(uint, uint) TransactionUpdate {
+				return TransactionUpdate{Amount: amount("-4000")}
+			},
+			wantErr: true,
+			wantIs:  ErrInvalidOperation,
+		},
+		{
+			name: "Error_unknown_category",
+			edit: "ICA Maxi",
+			update: func(uint, uint) TransactionUpdate {
+				unknown := uint(999)
+				return TransactionUpdate{SetCategory: true, CategoryID: &unknown}
+			},
+			wantErr: true,
+			wantIs:  ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			ctx := context.Background()
+			store := db.NewMockStore()
+			createExchangeRates(t, ctx, store)
+			ids := createTransferTestData(t, ctx, store)
+			if _, err := LinkTransfer(ctx, store, ids["Till sparkonto"], ids["Från lönekonto"]); err != nil {
+				t.Fatalf("LinkTransfer() unexpected error: %v", err)
+			}
+			category := &db.Category{Name: "Resor", TypeID: 1}
+			if err := store.CreateCategory(ctx, category); err != nil {
+				t.Fatalf("CreateCategory() unexpected error: %v", err)
+			}
+			subcategory := &db.Subcategory{Name: "Hotell"}
+			if err := store.CreateSubcategory(ctx, subcategory); err != nil {
+				t.Fatalf("CreateSubcategory() unexpected error: %v", err)
+			}
+			hotel := &db.Transaction{
+				Description:  "Hotel",
+				Date:         time.Date(2026, 9, 4, 0, 0, 0, 0, time.UTC),
+				Amount:       decimal.RequireFromString("-100"),
+				Currency:     db.CurrencyEUR,
+				CategoryID:   &category.ID,
+				BaseAmount:   decimal.NewNullDecimal(decimal.RequireFromString("-1120")),
+				BaseCurrency: db.CurrencySEK,
+			}
+			if err := store.CreateTransaction(ctx, hotel); err != nil {
+				t.Fatalf("CreateTransaction() unexpected error: %v", err)
+			}
+			ids["Hotel"] = hotel.ID
+
+			// Execute
+			_, err := EditTransaction(ctx, store, ids[tt.edit], tt.update(category.ID, subcategory.ID))
+
+			// Verify
+			if tt.wantErr {
+				if err == nil || (tt.wantIs != nil && !errors.Is(err, tt.wantIs)) {
+					t.Fatalf("EditTransaction() error = %v, want %v", err, tt.wantIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EditTransaction() unexpected error: %v", err)
+			}
+			stored, _ := store.GetTransactionByID(ctx, ids[tt.edit])
+			tt.verify(t, stored, category.ID, subcategory.ID)
+		})
+	}
+}
+
+func TestListTransactions_Successfully_order_newest_first(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMockStore()
+	createTransferTestData(t, ctx, store)
+
+	transactions, err := ListTransactions(ctx, store, &db.TransactionFilter{Text: "buffert"})
+
+	if err != nil {
+		t.Fatalf("ListTransactions() unexpected error: %v", err)
+	}
+	var got []string
+	for _, tx := range transactions {
+		got = append(got, tx.Description)
+	}
+	if len(got) != 3 || got[0] != "Buffert sen" || got[2] != "Buffert" {
+		t.Errorf("ListTransactions() = %v, want the Buffert transactions newest first", got)
+	}
+}
@@ -0,0 +1,272 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+)
+
+// DefaultTransferWindow is the number of days a transfer may take to reach the other account
+const DefaultTransferWindow = 3
+
+// TransferPair is a transfer between two of our own accounts
+type TransferPair struct {
+	From db.Transaction // Outgoing side, with a negative amount
+	To   db.Transaction // Incoming side, with a positive amount
+}
+
+// Status returns the transfer status of the pair
+func (p TransferPair) Status() string {
+	return p.From.TransferStatus
+}
+
+// TransferOptions contains the options for detecting transfers
+type TransferOptions struct {
+	// Window is the most days the incoming side may be booked before or after
+	// the outgoing side, DefaultTransferWindow when zero
+	Window int
+	// StartDate and EndDate limit the outgoing transactions considered; nil for no limit
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// DetectTransfers pairs outgoing transactions with incoming transactions of
+// the same amount and currency on another account booked within the window.
+// Each outgoing transaction is paired with the closest incoming one by date.
+// Transactions without an account, flagged duplicates, transactions that are
+// part of a transfer already and transfers unlinked by hand are left out.
+func DetectTransfers(ctx context.Context, store db.Store, opts TransferOptions) ([]TransferPair, error) {
+	window := opts.Window
+	if window <= 0 {
+		window = DefaultTransferWindow
+	}
+	filter := &db.TransactionFilter{ExcludeTransfers: true}
+	if opts.StartDate != nil {
+		from := opts.StartDate.AddDate(0, 0, -window)
+		filter.StartDate = &from
+	}
+	if opts.EndDate != nil {
+		to := opts.EndDate.AddDate(0, 0, window)
+		filter.EndDate = &to
+	}
+	transactions, err := store.ListTransactions(ctx, filter)
+	if err != nil {
+		return nil, NewOperationError("detect_transfers", err)
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		if !transactions[i].Date.Equal(transactions[j].Date) {
+			return transactions[i].Date.Before(transactions[j].Date)
+		}
+		return transactions[i].ID < transactions[j].ID
+	})
+
+	var outgoing, incoming []db.Transaction
+	for _, tx := range transactions {
+		if tx.AccountID == nil || tx.DuplicateOfID != nil || tx.TransferStatus == db.TransferRejected {
+			continue
+		}
+		switch {
+		case tx.Amount.IsNegative():
+			if (opts.StartDate == nil || !tx.Date.Before(*opts.StartDate)) && (opts.EndDate == nil || !tx.Date.After(*opts.EndDate)) {
+				outgoing = append(outgoing, tx)
+			}
+		case tx.Amount.IsPositive():
+			incoming = append(incoming, tx)
+		}
+	}
+
+	paired := make(map[uint]bool)
+	var pairs []TransferPair
+	for _, out := range outgoing {
+		best := -1
+		var bestDistance time.Duration
+		for i, in := range incoming {
+			if paired[in.ID] || *in.AccountID == *out.AccountID || in.Currency != out.Currency || !in.Amount.Equal(out.Amount.Neg()) {
+				continue
+			}
+			distance := in.Date.Sub(out.Date).Abs()
+			if distance > time.Duration(window)*24*time.Hour {
+				continue
+			}
+			if best < 0 || distance < bestDistance {
+				best, bestDistance = i, distance
+			}
+		}
+		if best >= 0 {
+			paired[incoming[best].ID] = true
+			pairs = append(pairs, TransferPair{From: out, To: incoming[best]})
+		}
+	}
+	return pairs, nil
+}
+
+// LinkTransfers marks the detected pairs as transfers
+func LinkTransfers(ctx context.Context, store db.Store, pairs []TransferPair) error {
+	return store.WithTransaction(ctx, func(store db.Store) error {
+		for i := range pairs {
+			if err := saveTransfer(ctx, store, &pairs[i].From, &pairs[i].To, db.TransferDetected); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LinkTransfer marks two transactions as the sides of a transfer confirmed by
+// hand. One must be outgoing and the other incoming, and neither may be part
+// of another transfer.
+func LinkTransfer(ctx context.Context, store db.Store, id, otherID uint) (*TransferPair, error) {
+	if id == otherID {
+		return nil, NewValidationError("transaction", otherID, "a transaction cannot be transferred to itself")
+	}
+	a, err := getTransaction(ctx, store, id)
+	if err != nil {
+		return nil, err
+	}
+	b, err := getTransaction(ctx, store, otherID)
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range []*db.Transaction{a, b} {
+		if tx.IsTransfer() {
+			return nil, NewResourceOperationError("link_transfer", fmt.Sprintf("transaction %d", tx.ID),
+				fmt.Errorf("%w: already part of a transfer with transaction %d", ErrInvalidOperation, *tx.TransferOfID))
+		}
+	}
+
+	pair := TransferPair{From: *a, To: *b}
+	if pair.From.Amount.IsPositive() {
+		pair.From, pair.To = pair.To, pair.From
+	}
+	if !pair.From.Amount.IsNegative() || !pair.To.Amount.IsPositive() {
+		return nil, NewValidationError("transaction", otherID, "a transfer needs an outgoing and an incoming transaction")
+	}
+	if pair.From.Currency == pair.To.Currency && !pair.To.Amount.Equal(pair.From.Amount.Neg()) {
+		return nil, NewValidationError("transaction", otherID,
+			fmt.Sprintf("amounts %s and %s do not match", pair.From.Amount.StringFixed(2), pair.To.Amount.StringFixed(2)))
+	}
+
+	err = store.WithTransaction(ctx, func(store db.Store) error {
+		return saveTransfer(ctx, store, &pair.From, &pair.To, db.TransferConfirmed)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pair, nil
+}
+
+// ConfirmTransfer confirms the detected transfer a transaction is part of
+func ConfirmTransfer(ctx context.Context, store db.Store, id uint) (*TransferPair, error) {
+	pair, err := GetTransfer(ctx, store, id)
+	if err != nil {
+		return nil, err
+	}
+	err = store.WithTransaction(ctx, func(store db.Store) error {
+		return saveTransfer(ctx, store, &pair.From, &pair.To, db.TransferConfirmed)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// UnlinkTransfer makes the sides of the transfer a transaction is part of
+// regular transactions again. They are marked as rejected, so that the
+// detector does not pair them again.
+func UnlinkTransfer(ctx context.Context, store db.Store, id uint) (*TransferPair, error) {
+	pair, err := GetTransfer(ctx, store, id)
+	if err != nil {
+		return nil, err
+	}
+	err = store.WithTransaction(ctx, func(store db.Store) error {
+		for _, tx := range []*db.Transaction{&pair.From, &pair.To} {
+			tx.TransferOfID = nil
+			tx.TransferStatus = db.TransferRejected
+			if err := store.UpdateTransaction(ctx, tx); err != nil {
+				return NewOperationError("unlink_transfer", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// GetTransfer returns the transfer a transaction is part of
+func GetTransfer(ctx context.Context, store db.Store, id uint) (*TransferPair, error) {
+	tx, err := getTransaction(ctx, store, id)
+	if err != nil {
+		return nil, err
+	}
+	if !tx.IsTransfer() {
+		return nil, NewResourceOperationError("get_transfer", fmt.Sprintf("transaction %d", id),
+			fmt.Errorf("%w: not part of a transfer", ErrNotFound))
+	}
+	other, err := getTransaction(ctx, store, *tx.TransferOfID)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Amount.IsNegative() {
+		return &TransferPair{From: *tx, To: *other}, nil
+	}
+	return &TransferPair{From: *other, To: *tx}, nil
+}
+
+// ListTransfers returns the transfers whose outgoing side matches the filter,
+// ordered by date
+func ListTransfers(ctx context.Context, store db.Store, filter *db.TransactionFilter) ([]TransferPair, error) {
+	transactions, err := store.ListTransactions(ctx, filter)
+	if err != nil {
+		return nil, NewOperationError("list_transfers", err)
+	}
+	var pairs []TransferPair
+	for _, tx := range transactions {
+		if !tx.IsTransfer() || !tx.Amount.IsNegative() {
+			continue
+		}
+		other, err := getTransaction(ctx, store, *tx.TransferOfID)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, TransferPair{From: tx, To: *other})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if !pairs[i].From.Date.Equal(pairs[j].From.Date) {
+			return pairs[i].From.Date.Before(pairs[j].From.Date)
+		}
+		return pairs[i].From.ID < pairs[j].From.ID
+	})
+	return pairs, nil
+}
+
+// saveTransfer links the two sides of a transfer to each other
+func saveTransfer(ctx context.Context, store db.Store, from, to *db.Transaction, status string) error {
+	from.TransferOfID, to.TransferOfID = &to.ID, &from.ID
+	from.TransferStatus, to.TransferStatus = status, status
+	for _, tx := range []*db.Transaction{from, to} {
+		if err := store.UpdateTransaction(ctx, tx); err != nil {
+			return NewResourceOperationError("link_transfer", fmt.Sprintf("transaction %d", tx.ID), err)
+		}
+	}
+	return nil
+}
+
+// getTransaction returns a copy of the stored transaction, so that changes are
+// only made through the store
+func getTransaction(ctx context.Context, store db.Store, id uint) (*db.Transaction, error) {
+	tx, err := store.GetTransactionByID(ctx, id)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, NewResourceOperationError("get_transaction", fmt.Sprintf("transaction %d", id), ErrNotFound)
+	}
+	if err != nil {
+		return nil, NewOperationError("get_transaction", err)
+	}
+	copied := *tx
+	return &copied, nil
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/shopspring/decimal"
+)
+
+// createTransferTestData stores transactions on a checking and a savings
+// account and returns their IDs by description
+func createTransferTestData(t *testing.T, ctx context.Context, store *db.MockStore) map[string]uint {
+	t.Helper()
+	checking := &db.Account{Name: "Lönekonto", Type: db.AccountTypeChecking, Currency: db.CurrencySEK}
+	savings := &db.Account{Name: "Sparkonto", Type: db.AccountTypeSavings, Currency: db.CurrencySEK}
+	for _, account := range []*db.Account{checking, savings} {
+		if err := store.CreateAccount(ctx, account); err != nil {
+			t.Fatalf("CreateAccount() unexpected error: %v", err)
+		}
+	}
+
+	ids := make(map[string]uint)
+	for _, tx := range []struct {
+		description string
+		account     *db.Account
+		day         int
+		amount      string
+	}{
+		{"Till sparkonto", checking, 1, "-5000"},
+		{"Från lönekonto", savings, 2, "5000"},
+		{"ICA Maxi", checking, 3, "-200"},
+		{"Ränta", savings, 20, "200"},
+		{"Buffert", checking, 10, "-1000"},
+		{"Buffert in", savings, 11, "1000"},
+		{"Buffert sen", savings, 13, "1000"},
+		{"Swish utan konto", nil, 1, "5000"},
+	} {
+		transaction := &db.Transaction{
+			Date:        time.Date(2026, 9, tx.day, 0, 0, 0, 0, time.UTC),
+			Description: tx.description,
+			Amount:      decimal.RequireFromString(tx.amount),
+			Currency:    db.CurrencySEK,
+		}
+		if tx.account != nil {
+			transaction.AccountID = &tx.account.ID
+		}
+		if err := store.CreateTransaction(ctx, transaction); err != nil {
+			t.Fatalf("CreateTransaction() unexpected error: %v", err)
+		}
+		ids[tx.description] = transaction.ID
+	}
+	return ids
+}
+
+func TestDetectTransfers(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	store := db.NewMockStore()
+	ids := createTransferTestData(t, ctx, store)
+
+	// Execute
+	pairs, err := DetectTransfers(ctx, store, TransferOptions{})
+
+	// Verify
+	if err != nil {
+		t.Fatalf("DetectTransfers() unexpected error: %v", err)
+	}
+	want := [][2]uint{
+		{ids["Till sparkonto"], ids["Från lönekonto"]},
+		{ids["Buffert"], ids["Buffert in"]},
+	}
+	if len(pairs) != len(want) {
+		t.Fatalf("DetectTransfers() found %d pairs, want %d: %+v", len(pairs), len(want), pairs)
+	}
+	for i, pair := range pairs {
+		if pair.From.ID != want[i][0] || pair.To.ID != want[i][1] {
+			t.Errorf("pair %d = %d -> %d, want %d -> %d", i, pair.From.ID, pair.To.ID, want[i][0], want[i][1])
+		}
+	}
+
+	if err := LinkTransfers(ctx, store, pairs); err != nil {
+		t.Fatalf("LinkTransfers() unexpected error: %v", err)
+	}
+	remaining, _ := store.ListTransactions(ctx, &db.TransactionFilter{ExcludeTransfers: true})
+	if len(remaining) != 4 {
+		t.Errorf("ListTransactions() without transfers returned %d transactions, want 4", len(remaining))
+	}
+	again, _ := DetectTransfers(ctx, store, TransferOptions{})
+	if len(again) != 0 {
+		t.Errorf("DetectTransfers() paired linked transactions again: %+v", again)
+	}
+
+	// Unlinked transfers are not detected again
+	if _, err := UnlinkTransfer(ctx, store, ids["Buffert in"]); err != nil {
+		t.Fatalf("UnlinkTransfer() unexpected error: %v", err)
+	}
+	again, _ = DetectTransfers(ctx, store, TransferOptions{})
+	if len(again) != 0 {
+		t.Errorf("DetectTransfers() paired unlinked transactions again: %+v", again)
+	}
+	transfers, _ := ListTransfers(ctx, store, nil)
+	if len(transfers) != 1 || transfers[0].Status() != db.TransferDetected {
+		t.Errorf("ListTransfers() = %+v, want the detected transfer to the savings account", transfers)
+	}
+}
+
+func TestDetectTransfers_Successfully_use_window(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMockStore()
+	createTransferTestData(t, ctx, store)
+
+	pairs, err := DetectTransfers(ctx, store, TransferOptions{Window: 17})
+
+	if err != nil {
+		t.Fatalf("DetectTransfers() unexpected error: %v", err)
+	}
+	if len(pairs) != 3 {
+		t.Errorf("DetectTransfers() found %d pairs within 17 days, want 3", len(pairs))
+	}
+}
+
+func TestLinkTransfer(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{name: "Successfully_link_by_hand", from: "Ränta", to: "ICA Maxi"},
+		{name: "Error_same_direction", from: "Ränta", to: "Buffert in", wantErr: true},
+		{name: "Error_amounts_differ", from: "ICA Maxi", to: "Buffert in", wantErr: true},
+		{name: "Error_already_linked", from: "ICA Maxi", to: "Från lönekonto", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := db.NewMockStore()
+			ids := createTransferTestData(t, ctx, store)
+			if _, err := LinkTransfer(ctx, store, ids["Till sparkonto"], ids["Från lönekonto"]); err != nil {
+				t.Fatalf("LinkTransfer() unexpected error: %v", err)
+			}
+
+			pair, err := LinkTransfer(ctx, store, ids[tt.from], ids[tt.to])
+
+			if tt.wantErr {
+				if err == nil {
+					t.Error("LinkTransfer() expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LinkTransfer() unexpected error: %v", err)
+			}
+			if pair.From.ID != ids["ICA Maxi"] || pair.Status() != db.TransferConfirmed {
+				t.Errorf("LinkTransfer() = %+v, want a confirmed transfer from ICA Maxi", pair)
+			}
+			stored, _ := store.GetTransactionByID(ctx, ids["Ränta"])
+			if stored.TransferOfID == nil || *stored.TransferOfID != ids["ICA Maxi"] {
+				t.Errorf("TransferOfID = %v, want %d", stored.TransferOfID, ids["ICA Maxi"])
+			}
+		})
+	}
+}
@@ -44,8 +44,9 @@ func Initialize(cfg *Config, logger *slog.Logger) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
 
-	// Open database connection
-	db, err := gorm.Open(sqlite.Open(cfg.DBPath), gormConfig)
+	// Open database connection, waiting for locks held by other connections
+	// instead of failing while documents are processed in parallel
+	db, err := gorm.Open(sqlite.Open(cfg.DBPath+"?_busy_timeout=5000"), gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
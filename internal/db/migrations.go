@@ -12,6 +12,12 @@ func runMigrations(db *gorm.DB) error {
 		&Category{},
 		&Subcategory{},
 		&CategorySubcategory{},
+		&Account{},
+		&StatementBalance{},
+		&ExchangeRate{},
+		&ImportBatch{},
+		&ProcessingJob{},
+		&JobFile{},
 		&Transaction{},
 		&Tag{},
 		&Budget{},
@@ -3,8 +3,11 @@ package db
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 // MockStore is a mock implementation of the Store interface for testing
@@ -14,6 +17,11 @@ type MockStore struct {
 	subcategories     map[uint]*Subcategory
 	categoryTypes     map[uint]*CategoryType
 	transactions      map[uint]*Transaction
+	importBatches     map[uint]*ImportBatch
+	accounts          map[uint]*Account
+	balances          map[uint]*StatementBalance
+	rates             map[string]*ExchangeRate
+	jobs              map[uint]*ProcessingJob
 	tags              map[string]*Tag
 	categoryTypeNames map[string]*CategoryType
 	nextID            uint
@@ -29,6 +37,11 @@ func NewMockStore() *MockStore {
 		subcategories:     make(map[uint]*Subcategory),
 		categoryTypes:     make(map[uint]*CategoryType),
 		transactions:      make(map[uint]*Transaction),
+		importBatches:     make(map[uint]*ImportBatch),
+		accounts:          make(map[uint]*Account),
+		balances:          make(map[uint]*StatementBalance),
+		rates:             make(map[string]*ExchangeRate),
+		jobs:              make(map[uint]*ProcessingJob),
 		tags:              make(map[string]*Tag),
 		categoryTypeNames: make(map[string]*CategoryType),
 		nextID:            1,
@@ -286,6 +299,23 @@ func (s *MockStore) GetTransactionByID(ctx context.Context, id uint) (*Transacti
 	return transaction, nil
 }
 
+// GetTransactionByFingerprint implements Store
+func (s *MockStore) GetTransactionByFingerprint(ctx context.Context, fingerprint string) (*Transaction, error) {
+	var found *Transaction
+	for _, transaction := range s.transactions {
+		if transaction.Fingerprint != fingerprint || transaction.DuplicateOfID != nil {
+			continue
+		}
+		if found == nil || transaction.ID < found.ID {
+			found = transaction
+		}
+	}
+	if found == nil {
+		return nil, ErrNotFound
+	}
+	return found, nil
+}
+
 // ListTransactions implements Store
 func (s *MockStore) ListTransactions(ctx context.Context, filter *TransactionFilter) ([]Transaction, error) {
 	s.mu.Lock()
@@ -302,12 +332,33 @@ func (s *MockStore) ListTransactions(ctx context.Context, filter *TransactionFil
 			if filter.SubcategoryID != nil && (tx.SubcategoryID == nil || *tx.SubcategoryID != *filter.SubcategoryID) {
 				matches = false
 			}
+			if len(filter.AccountIDs) > 0 && (tx.AccountID == nil || !slices.Contains(filter.AccountIDs, *tx.AccountID)) {
+				matches = false
+			}
+			if filter.Unbound && tx.AccountID != nil {
+				matches = false
+			}
 			if filter.StartDate != nil && tx.Date.Before(*filter.StartDate) {
 				matches = false
 			}
 			if filter.EndDate != nil && tx.Date.After(*filter.EndDate) {
 				matches = false
 			}
+			if filter.ExcludeTransfers && tx.IsTransfer() {
+				matches = false
+			}
+			if filter.MinAmount != nil && tx.Amount.LessThan(*filter.MinAmount) {
+				matches = false
+			}
+			if filter.MaxAmount != nil && tx.Amount.GreaterThan(*filter.MaxAmount) {
+				matches = false
+			}
+			if filter.Text != "" {
+				text := strings.ToLower(filter.Text)
+				if !strings.Contains(strings.ToLower(tx.Description), text) && !strings.Contains(strings.ToLower(tx.Reference), text) {
+					matches = false
+				}
+			}
 		}
 
 		if matches {
@@ -324,9 +375,325 @@ func (s *MockStore) DeleteTransaction(ctx context.Context, id uint) error {
 		return ErrNotFound
 	}
 	delete(s.transactions, id)
+	s.clearDuplicates()
+	s.clearTransfers()
+	return nil
+}
+
+// clearDuplicates removes the flag of duplicates whose original no longer exists
+func (s *MockStore) clearDuplicates() {
+	for _, transaction := range s.transactions {
+		if transaction.DuplicateOfID != nil {
+			if _, exists := s.transactions[*transaction.DuplicateOfID]; !exists {
+				transaction.DuplicateOfID = nil
+			}
+		}
+	}
+}
+
+// clearTransfers unlinks the transfers whose other side no longer exists
+func (s *MockStore) clearTransfers() {
+	for _, transaction := range s.transactions {
+		if transaction.TransferOfID != nil {
+			if _, exists := s.transactions[*transaction.TransferOfID]; !exists {
+				transaction.TransferOfID = nil
+				transaction.TransferStatus = ""
+			}
+		}
+	}
+}
+
+// CreateAccount implements Store
+func (s *MockStore) CreateAccount(ctx context.Context, account *Account) error {
+	if account == nil {
+		return fmt.Errorf("account cannot be nil")
+	}
+	for _, existing := range s.accounts {
+		if existing.Name == account.Name {
+			return fmt.Errorf("failed to create account: %w", ErrDuplicateEntry)
+		}
+	}
+	if err := account.BeforeSave(nil); err != nil {
+		return fmt.Errorf("failed to create account: %w", err)
+	}
+	account.ID = s.nextID
+	s.nextID++
+	s.accounts[account.ID] = account
+	return nil
+}
+
+// UpdateAccount implements Store
+func (s *MockStore) UpdateAccount(ctx context.Context, account *Account) error {
+	if account == nil {
+		return fmt.Errorf("account cannot be nil")
+	}
+	if _, exists := s.accounts[account.ID]; !exists {
+		return ErrNotFound
+	}
+	if err := account.BeforeSave(nil); err != nil {
+		return fmt.Errorf("failed to update account: %w", err)
+	}
+	s.accounts[account.ID] = account
+	return nil
+}
+
+// GetAccountByID implements Store
+func (s *MockStore) GetAccountByID(ctx context.Context, id uint) (*Account, error) {
+	account, exists := s.accounts[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return account, nil
+}
+
+// GetAccountByName implements Store
+func (s *MockStore) GetAccountByName(ctx context.Context, name string) (*Account, error) {
+	for _, account := range s.accounts {
+		if account.Name == name {
+			return account, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// ListAccounts implements Store
+func (s *MockStore) ListAccounts(ctx context.Context) ([]Account, error) {
+	accounts := make([]Account, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		accounts = append(accounts, *account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Name < accounts[j].Name
+	})
+	return accounts, nil
+}
+
+// DeleteAccount implements Store
+func (s *MockStore) DeleteAccount(ctx context.Context, id uint) (int64, error) {
+	if _, exists := s.accounts[id]; !exists {
+		return 0, ErrNotFound
+	}
+	var detached int64
+	for _, transaction := range s.transactions {
+		if transaction.AccountID != nil && *transaction.AccountID == id {
+			transaction.AccountID = nil
+			detached++
+		}
+	}
+	for balanceID, balance := range s.balances {
+		if balance.AccountID == id {
+			delete(s.balances, balanceID)
+		}
+	}
+	delete(s.accounts, id)
+	return detached, nil
+}
+
+// CreateStatementBalance implements Store
+func (s *MockStore) CreateStatementBalance(ctx context.Context, balance *StatementBalance) error {
+	if balance == nil {
+		return fmt.Errorf("statement balance cannot be nil")
+	}
+	balance.ID = s.nextID
+	s.nextID++
+	s.balances[balance.ID] = balance
+	return nil
+}
+
+// ListStatementBalances implements Store
+func (s *MockStore) ListStatementBalances(ctx context.Context, accountID uint) ([]StatementBalance, error) {
+	var balances []StatementBalance
+	for _, balance := range s.balances {
+		if balance.AccountID == accountID {
+			balances = append(balances, *balance)
+		}
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		if !balances[i].Date.Equal(balances[j].Date) {
+			return balances[i].Date.Before(balances[j].Date)
+		}
+		return balances[i].ID < balances[j].ID
+	})
+	return balances, nil
+}
+
+// SaveExchangeRates implements Store
+func (s *MockStore) SaveExchangeRates(ctx context.Context, rates []ExchangeRate) error {
+	for _, rate := range rates {
+		key := rate.Date.Format("2006-01-02") + rate.Currency + rate.QuoteCurrency
+		if stored, ok := s.rates[key]; ok {
+			stored.Rate, stored.Source = rate.Rate, rate.Source
+			continue
+		}
+		rate.ID = s.nextID
+		s.nextID++
+		s.rates[key] = &rate
+	}
+	return nil
+}
+
+// ListExchangeRates implements Store
+func (s *MockStore) ListExchangeRates(ctx context.Context, start, end *time.Time) ([]ExchangeRate, error) {
+	var rates []ExchangeRate
+	for _, rate := range s.rates {
+		if (start == nil || !rate.Date.Before(*start)) && (end == nil || !rate.Date.After(*end)) {
+			rates = append(rates, *rate)
+		}
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		if !rates[i].Date.Equal(rates[j].Date) {
+			return rates[i].Date.Before(rates[j].Date)
+		}
+		return rates[i].Currency+rates[i].QuoteCurrency < rates[j].Currency+rates[j].QuoteCurrency
+	})
+	return rates, nil
+}
+
+// CreateImportBatch implements Store
+func (s *MockStore) CreateImportBatch(ctx context.Context, batch *ImportBatch) error {
+	if batch == nil {
+		return fmt.Errorf("import batch cannot be nil")
+	}
+	batch.ID = s.nextID
+	s.nextID++
+	s.importBatches[batch.ID] = batch
+	return nil
+}
+
+// UpdateImportBatch implements Store
+func (s *MockStore) UpdateImportBatch(ctx context.Context, batch *ImportBatch) error {
+	if batch == nil {
+		return fmt.Errorf("import batch cannot be nil")
+	}
+	if _, exists := s.importBatches[batch.ID]; !exists {
+		return ErrNotFound
+	}
+	s.importBatches[batch.ID] = batch
+	return nil
+}
+
+// GetImportBatchByID implements Store
+func (s *MockStore) GetImportBatchByID(ctx context.Context, id uint) (*ImportBatch, error) {
+	batch, exists := s.importBatches[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return batch, nil
+}
+
+// ListImportBatches implements Store
+func (s *MockStore) ListImportBatches(ctx context.Context) ([]ImportBatch, error) {
+	batches := make([]ImportBatch, 0, len(s.importBatches))
+	for _, batch := range s.importBatches {
+		batches = append(batches, *batch)
+	}
+	sort.Slice(batches, func(i, j int) bool {
+		return batches[i].ID > batches[j].ID
+	})
+	return batches, nil
+}
+
+// CreateProcessingJob implements Store
+func (s *MockStore) CreateProcessingJob(ctx context.Context, job *ProcessingJob) error {
+	if job == nil {
+		return fmt.Errorf("processing job cannot be nil")
+	}
+	job.ID = s.nextID
+	s.nextID++
+	for i := range job.Files {
+		job.Files[i].ID = s.nextID
+		job.Files[i].JobID = job.ID
+		s.nextID++
+	}
+	s.jobs[job.ID] = copyProcessingJob(job)
+	return nil
+}
+
+// UpdateProcessingJob implements Store
+func (s *MockStore) UpdateProcessingJob(ctx context.Context, job *ProcessingJob) error {
+	if job == nil {
+		return fmt.Errorf("processing job cannot be nil")
+	}
+	stored, exists := s.jobs[job.ID]
+	if !exists {
+		return ErrNotFound
+	}
+	updated := *job
+	updated.Files = stored.Files
+	s.jobs[job.ID] = &updated
 	return nil
 }
 
+// GetProcessingJobByID implements Store
+func (s *MockStore) GetProcessingJobByID(ctx context.Context, id uint) (*ProcessingJob, error) {
+	job, exists := s.jobs[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return copyProcessingJob(job), nil
+}
+
+// ListProcessingJobs implements Store
+func (s *MockStore) ListProcessingJobs(ctx context.Context) ([]ProcessingJob, error) {
+	jobs := make([]ProcessingJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, *copyProcessingJob(job))
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].ID > jobs[j].ID
+	})
+	return jobs, nil
+}
+
+// UpdateJobFile implements Store
+func (s *MockStore) UpdateJobFile(ctx context.Context, file *JobFile) error {
+	if file == nil {
+		return fmt.Errorf("job file cannot be nil")
+	}
+	job, exists := s.jobs[file.JobID]
+	if !exists {
+		return ErrNotFound
+	}
+	for i := range job.Files {
+		if job.Files[i].ID == file.ID {
+			job.Files[i] = *file
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// copyProcessingJob copies a job and its files, so that callers cannot change the stored job
+func copyProcessingJob(job *ProcessingJob) *ProcessingJob {
+	copied := *job
+	copied.Files = append([]JobFile(nil), job.Files...)
+	return &copied
+}
+
+// DeleteImportBatch implements Store
+func (s *MockStore) DeleteImportBatch(ctx context.Context, id uint) (int64, error) {
+	if _, exists := s.importBatches[id]; !exists {
+		return 0, ErrNotFound
+	}
+
+	var deleted int64
+	for txID, transaction := range s.transactions {
+		if transaction.ImportBatchID != nil && *transaction.ImportBatchID == id {
+			delete(s.transactions, txID)
+			deleted++
+		}
+	}
+	s.clearDuplicates()
+	s.clearTransfers()
+	for balanceID, balance := range s.balances {
+		if balance.ImportBatchID != nil && *balance.ImportBatchID == id {
+			delete(s.balances, balanceID)
+		}
+	}
+	delete(s.importBatches, id)
+	return deleted, nil
+}
+
 // CreatePrompt implements Store
 func (s *MockStore) CreatePrompt(ctx context.Context, prompt *Prompt) error {
 	if prompt == nil {
@@ -440,6 +807,32 @@ func (s *MockStore) UnlinkSubcategoryTag(ctx context.Context, subcategoryID, tag
 	return ErrNotFound
 }
 
+// WithTransaction implements Store, restoring the transactions and import
+// batches when fn returns an error
+func (s *MockStore) WithTransaction(ctx context.Context, fn func(store Store) error) error {
+	transactions := make(map[uint]Transaction, len(s.transactions))
+	for id, transaction := range s.transactions {
+		transactions[id] = *transaction
+	}
+	batches := make(map[uint]ImportBatch, len(s.importBatches))
+	for id, batch := range s.importBatches {
+		batches[id] = *batch
+	}
+
+	if err := fn(s); err != nil {
+		s.transactions = make(map[uint]*Transaction, len(transactions))
+		for id, transaction := range transactions {
+			s.transactions[id] = &transaction
+		}
+		s.importBatches = make(map[uint]*ImportBatch, len(batches))
+		for id, batch := range batches {
+			s.importBatches[id] = &batch
+		}
+		return err
+	}
+	return nil
+}
+
 // Close implements Store
 func (s *MockStore) Close() error {
 	return nil
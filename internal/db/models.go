@@ -8,6 +8,7 @@ package db
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -65,21 +66,131 @@ type CategorySubcategory struct {
 
 // Transaction represents a financial transaction
 type Transaction struct {
-	ID              uint `gorm:"primarykey"`
-	Date            time.Time
+	ID              uint      `gorm:"primarykey"`
+	Date            time.Time // Booking date
 	TransactionDate time.Time
+	ValueDate       time.Time // Date interest is calculated from, zero when the bank does not state it
 	Amount          decimal.Decimal
+	Balance         decimal.NullDecimal // Account balance after the transaction, when the bank states it
+	BaseAmount      decimal.NullDecimal // Amount in BaseCurrency at the exchange rate of the booking date, null when no rate is known
+	BaseCurrency    string              `gorm:"size:3"`
 	Description     string
 	CategoryID      *uint
 	SubcategoryID   *uint
 	Category        *Category    `gorm:"foreignKey:CategoryID"`
 	Subcategory     *Subcategory `gorm:"foreignKey:SubcategoryID"`
 	Source          string
+	SourceContainer string `gorm:"size:1024"` // ZIP archive, email or mailbox the document was extracted from
+	SourceEntry     string `gorm:"size:1024"` // Document within the container, e.g. "message-2/statement.pdf"
+	MessageSubject  string `gorm:"size:500"`  // Subject of the email the document was attached to
+	MessageSender   string `gorm:"size:255"`  // Sender of the email the document was attached to
 	Reference       string
-	RawData         string `gorm:"type:text"`
-	AIAnalysis      string `gorm:"type:text"`
-	Metadata        string `gorm:"type:json"`
-	Currency        string `gorm:"not null;size:3;default:'SEK'"`
+	RawData         string       `gorm:"type:text"`
+	AIAnalysis      string       `gorm:"type:text"`
+	Metadata        string       `gorm:"type:json"`
+	Currency        string       `gorm:"not null;size:3;default:'SEK'"`
+	Fingerprint     string       `gorm:"index;size:64"` // Identifies the transaction across imports
+	DuplicateOfID   *uint        // Set on duplicates imported with --on-duplicate=flag
+	ImportBatchID   *uint        `gorm:"index"`
+	ImportBatch     *ImportBatch `gorm:"foreignKey:ImportBatchID"`
+	AccountID       *uint        `gorm:"index"` // Account the transaction was booked on, nil when unknown
+	Account         *Account     `gorm:"foreignKey:AccountID"`
+	TransferOfID    *uint        `gorm:"index"`   // Other side of an internal transfer between our own accounts
+	TransferStatus  string       `gorm:"size:10"` // TransferDetected, TransferConfirmed or TransferRejected
+}
+
+// Transfer statuses
+const (
+	TransferDetected  = "detected"  // Paired by the transfer detector
+	TransferConfirmed = "confirmed" // Paired or confirmed by hand
+	TransferRejected  = "rejected"  // Unlinked by hand, so that the detector leaves it alone
+)
+
+// IsTransfer reports whether the transaction moves money between our own
+// accounts, which makes it neither income nor expense
+func (t *Transaction) IsTransfer() bool {
+	return t.TransferOfID != nil
+}
+
+// Account types
+const (
+	AccountTypeChecking   = "checking"
+	AccountTypeSavings    = "savings"
+	AccountTypeCreditCard = "credit_card"
+	AccountTypeLoan       = "loan"
+)
+
+// AccountTypes lists the valid account types
+var AccountTypes = []string{AccountTypeChecking, AccountTypeSavings, AccountTypeCreditCard, AccountTypeLoan}
+
+// Account is a bank account or card that transactions are booked on
+type Account struct {
+	ID             uint   `gorm:"primarykey"`
+	Name           string `gorm:"not null;uniqueIndex;size:100"`
+	Bank           string `gorm:"size:100"`      // Registered parser id of the bank, e.g. "seb"
+	Number         string `gorm:"index;size:50"` // Account number, IBAN or card number, see NormalizeAccountNumber
+	Type           string `gorm:"not null;size:20;default:'checking'"`
+	Currency       string `gorm:"not null;size:3;default:'SEK'"`
+	OpeningBalance decimal.Decimal
+	OpeningDate    time.Time // Date the opening balance applies to, zero when the account is tracked from its start
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// BeforeSave hook to validate the account type and currency
+func (a *Account) BeforeSave(tx *gorm.DB) error {
+	if !isAccountType(a.Type) {
+		return fmt.Errorf("invalid account type: %s", a.Type)
+	}
+	if !IsCurrencyCode(a.Currency) {
+		return fmt.Errorf("invalid currency: %s", a.Currency)
+	}
+	a.Number = NormalizeAccountNumber(a.Number)
+	return nil
+}
+
+func isAccountType(accountType string) bool {
+	for _, t := range AccountTypes {
+		if t == accountType {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeAccountNumber removes the spaces and dashes banks format account
+// numbers with, so that "5490 99-000 01" and "54909900001" are the same account
+func NormalizeAccountNumber(number string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "", "\u00a0", "").Replace(strings.TrimSpace(number)))
+}
+
+// Statement balance types
+const (
+	BalanceOpening = "opening" // Balance before the transactions booked on the date
+	BalanceClosing = "closing" // Balance after the transactions booked on the date
+)
+
+// StatementBalance is an account balance stated by an imported statement,
+// which stored transactions are reconciled against
+type StatementBalance struct {
+	ID            uint      `gorm:"primarykey"`
+	AccountID     uint      `gorm:"not null;index"`
+	Date          time.Time `gorm:"not null"`
+	Type          string    `gorm:"not null;size:10"` // BalanceOpening or BalanceClosing
+	Amount        decimal.Decimal
+	Currency      string `gorm:"not null;size:3;default:'SEK'"`
+	ImportBatchID *uint  `gorm:"index"` // Import the balance was read from, nil when entered by hand
+	CreatedAt     time.Time
+}
+
+// Instant returns the start of the day, in UTC, the balance applies at, so
+// that a closing balance equals the opening balance of the following day
+func (b *StatementBalance) Instant() time.Time {
+	day := time.Date(b.Date.Year(), b.Date.Month(), b.Date.Day(), 0, 0, 0, 0, time.UTC)
+	if b.Type == BalanceClosing {
+		return day.AddDate(0, 0, 1)
+	}
+	return day
 }
 
 // FormatAmount returns the amount formatted with the currency
@@ -89,12 +200,83 @@ func (t *Transaction) FormatAmount() string {
 
 // BeforeCreate hook to validate the currency
 func (t *Transaction) BeforeCreate(tx *gorm.DB) error {
-	switch t.Currency {
-	case CurrencySEK, CurrencyEUR, CurrencyUSD:
-		return nil
-	default:
+	if !IsCurrencyCode(t.Currency) {
 		return fmt.Errorf("invalid currency: %s", t.Currency)
 	}
+	if t.BaseCurrency != "" && !IsCurrencyCode(t.BaseCurrency) {
+		return fmt.Errorf("invalid base currency: %s", t.BaseCurrency)
+	}
+	return nil
+}
+
+// ImportBatch records one imported file, so that its transactions can be traced back and undone
+type ImportBatch struct {
+	ID           uint       `gorm:"primarykey"`
+	FileName     string     `gorm:"not null;size:255"`
+	FilePath     string     `gorm:"index;size:1024"` // Absolute path the file was read from
+	FileHash     string     `gorm:"index;size:64"`   // SHA-256 of the file content
+	Parser       string     `gorm:"size:100"`        // Registered parser id, e.g. "seb"
+	RowsParsed   int        `gorm:"not null"`
+	RowsStored   int        `gorm:"not null"`
+	RowsSkipped  int        `gorm:"not null"` // Duplicates of stored transactions
+	RowsRejected int        `gorm:"not null"` // Rows the parser could not read
+	StartedAt    time.Time  `gorm:"not null"`
+	CompletedAt  *time.Time // Nil while the import is running or when it was interrupted
+}
+
+// JobStatus is the state of a processing job
+type JobStatus string
+
+const (
+	JobRunning     JobStatus = "running"
+	JobInterrupted JobStatus = "interrupted"
+	JobFailed      JobStatus = "failed" // Finished with failed files, which a resume retries
+	JobCompleted   JobStatus = "completed"
+)
+
+// JobFileStatus is the stage a document of a processing job has reached
+type JobFileStatus string
+
+const (
+	JobFilePending   JobFileStatus = "pending"
+	JobFileExtracted JobFileStatus = "extracted"
+	JobFileAnalysed  JobFileStatus = "analysed"
+	JobFileStored    JobFileStatus = "stored"
+	JobFileSkipped   JobFileStatus = "skipped" // Content processed before
+	JobFileFailed    JobFileStatus = "failed"
+)
+
+// ProcessingJob records a run of the process command, so that an interrupted run can be resumed
+type ProcessingJob struct {
+	ID          uint      `gorm:"primarykey"`
+	Path        string    `gorm:"not null;size:1024"`
+	Options     string    `gorm:"type:json"` // Processing options, reused when the job is resumed
+	Status      JobStatus `gorm:"not null;size:20"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt *time.Time
+	Files       []JobFile `gorm:"foreignKey:JobID"`
+}
+
+// JobFile tracks one document of a processing job through extraction, AI analysis and storage
+type JobFile struct {
+	ID                uint          `gorm:"primarykey"`
+	JobID             uint          `gorm:"index;not null"`
+	Position          int           `gorm:"not null"` // Walk order within the job
+	Path              string        `gorm:"not null;size:1024"`
+	Entry             string        `gorm:"size:1024"` // Document within the container at Path, if any
+	FileHash          string        `gorm:"size:64"`   // Content the document was extracted from
+	Status            JobFileStatus `gorm:"not null;size:20"`
+	RetryFrom         JobFileStatus `gorm:"size:20"`   // Stage a failed document is resumed from
+	DocumentStatus    string        `gorm:"size:20"`   // new, changed or unchanged
+	Document          string        `gorm:"type:text"` // Extracted or analysed transactions, kept until stored
+	TransactionsFound int
+	Stored            int
+	Skipped           int // Transactions left out as already stored
+	ImportBatchID     *uint
+	PreviousBatchID   *uint  // Batch that processed the path or content before
+	Error             string `gorm:"type:text"`
+	UpdatedAt         time.Time
 }
 
 // Budget represents a budget plan for a specific category
@@ -123,6 +305,31 @@ type Report struct {
 	Data        string    // JSON string of report data
 }
 
+// ExchangeRate is the price of one unit of a currency in another currency on a day
+type ExchangeRate struct {
+	ID            uint            `gorm:"primarykey"`
+	Date          time.Time       `gorm:"not null;uniqueIndex:idx_exchange_rate"`
+	Currency      string          `gorm:"not null;size:3;uniqueIndex:idx_exchange_rate"` // Currency priced
+	QuoteCurrency string          `gorm:"not null;size:3;uniqueIndex:idx_exchange_rate"` // Currency the price is in
+	Rate          decimal.Decimal `gorm:"not null"`                                      // One Currency costs Rate QuoteCurrency
+	Source        string          `gorm:"size:20"`                                       // Publisher of the rate, e.g. "ecb" or "riksbank"
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// BeforeSave hook to validate the currencies and the rate
+func (r *ExchangeRate) BeforeSave(tx *gorm.DB) error {
+	for _, code := range []string{r.Currency, r.QuoteCurrency} {
+		if !IsCurrencyCode(code) {
+			return fmt.Errorf("invalid currency: %s", code)
+		}
+	}
+	if !r.Rate.IsPositive() {
+		return fmt.Errorf("invalid exchange rate %s for %s/%s", r.Rate, r.Currency, r.QuoteCurrency)
+	}
+	return nil
+}
+
 // Currency constants
 const (
 	CurrencySEK = "SEK"
@@ -4,17 +4,32 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TransactionFilter defines filters for listing transactions
 type TransactionFilter struct {
 	CategoryID    *uint
 	SubcategoryID *uint
-	StartDate     *time.Time
-	EndDate       *time.Time
+	StartDate     *time.Time // Booked on or after the date
+	EndDate       *time.Time // Booked on or before the date
+	AccountIDs    []uint     // Transactions booked on any of the accounts
+	// Unbound keeps only transactions that are not bound to any account
+	Unbound bool
+	// ExcludeTransfers leaves out transfers between our own accounts, which
+	// are neither income nor expense
+	ExcludeTransfers bool
+	// MinAmount and MaxAmount limit the amount in the currency of the
+	// transaction, both inclusive; expenses are negative
+	MinAmount *decimal.Decimal
+	MaxAmount *decimal.Decimal
+	// Text matches transactions whose description or reference contains it, ignoring case
+	Text string
 }
 
 // Store defines the interface for database operations
@@ -45,13 +60,54 @@ type Store interface {
 	CreateCategorySubcategory(ctx context.Context, link *CategorySubcategory) error
 	DeleteCategorySubcategory(ctx context.Context, categoryID, subcategoryID uint) error
 
+	// Account operations
+	CreateAccount(ctx context.Context, account *Account) error
+	UpdateAccount(ctx context.Context, account *Account) error
+	GetAccountByID(ctx context.Context, id uint) (*Account, error)
+	GetAccountByName(ctx context.Context, name string) (*Account, error)
+	ListAccounts(ctx context.Context) ([]Account, error)
+	// DeleteAccount deletes the account and keeps its transactions without an
+	// account, returning how many transactions were detached
+	DeleteAccount(ctx context.Context, id uint) (int64, error)
+
+	// Statement balance operations
+	CreateStatementBalance(ctx context.Context, balance *StatementBalance) error
+	// ListStatementBalances returns the balances stated for the account, ordered by date
+	ListStatementBalances(ctx context.Context, accountID uint) ([]StatementBalance, error)
+
+	// Exchange rate operations
+	// SaveExchangeRates stores the rates, replacing the rate already stored for
+	// the same day and currencies
+	SaveExchangeRates(ctx context.Context, rates []ExchangeRate) error
+	// ListExchangeRates returns the rates of the days between start and end,
+	// both inclusive and nil for no limit, ordered by date
+	ListExchangeRates(ctx context.Context, start, end *time.Time) ([]ExchangeRate, error)
+
 	// Transaction operations
 	CreateTransaction(ctx context.Context, transaction *Transaction) error
 	UpdateTransaction(ctx context.Context, transaction *Transaction) error
 	GetTransactionByID(ctx context.Context, id uint) (*Transaction, error)
+	GetTransactionByFingerprint(ctx context.Context, fingerprint string) (*Transaction, error)
 	ListTransactions(ctx context.Context, filter *TransactionFilter) ([]Transaction, error)
 	DeleteTransaction(ctx context.Context, id uint) error
 
+	// Import batch operations
+	CreateImportBatch(ctx context.Context, batch *ImportBatch) error
+	UpdateImportBatch(ctx context.Context, batch *ImportBatch) error
+	GetImportBatchByID(ctx context.Context, id uint) (*ImportBatch, error)
+	ListImportBatches(ctx context.Context) ([]ImportBatch, error)
+	// DeleteImportBatch deletes the batch and the transactions it created, returning how many transactions were deleted
+	DeleteImportBatch(ctx context.Context, id uint) (int64, error)
+
+	// Processing job operations
+	CreateProcessingJob(ctx context.Context, job *ProcessingJob) error
+	UpdateProcessingJob(ctx context.Context, job *ProcessingJob) error
+	// GetProcessingJobByID returns the job with its files in walk order
+	GetProcessingJobByID(ctx context.Context, id uint) (*ProcessingJob, error)
+	// ListProcessingJobs returns the jobs with their files, most recent first
+	ListProcessingJobs(ctx context.Context) ([]ProcessingJob, error)
+	UpdateJobFile(ctx context.Context, file *JobFile) error
+
 	// Prompt operations
 	CreatePrompt(ctx context.Context, prompt *Prompt) error
 	UpdatePrompt(ctx context.Context, prompt *Prompt) error
@@ -66,6 +122,10 @@ type Store interface {
 	LinkSubcategoryTag(ctx context.Context, subcategoryID, tagID uint) error
 	UnlinkSubcategoryTag(ctx context.Context, subcategoryID, tagID uint) error
 
+	// WithTransaction runs fn with a store whose changes are committed when fn
+	// returns nil and rolled back when it returns an error
+	WithTransaction(ctx context.Context, fn func(store Store) error) error
+
 	// Close closes the database connection
 	Close() error
 }
@@ -85,6 +145,12 @@ func NewStore(db *gorm.DB, logger *slog.Logger) Store {
 		&Subcategory{},
 		&Tag{},
 		&CategorySubcategory{},
+		&Account{},
+		&StatementBalance{},
+		&ExchangeRate{},
+		&ImportBatch{},
+		&ProcessingJob{},
+		&JobFile{},
 		&Transaction{},
 		&Budget{},
 		&Report{},
@@ -357,6 +423,23 @@ func (s *SQLStore) GetTransactionByID(ctx context.Context, id uint) (*Transactio
 	return &transaction, nil
 }
 
+// GetTransactionByFingerprint retrieves the first transaction imported with the fingerprint,
+// ignoring transactions flagged as duplicates
+func (s *SQLStore) GetTransactionByFingerprint(ctx context.Context, fingerprint string) (*Transaction, error) {
+	var transaction Transaction
+	result := s.db.WithContext(ctx).
+		Where("fingerprint = ? AND duplicate_of_id IS NULL", fingerprint).
+		Order("id").
+		First(&transaction)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction by fingerprint: %w", result.Error)
+	}
+	return &transaction, nil
+}
+
 // ListTransactions retrieves all transactions, optionally filtered by filter
 func (s *SQLStore) ListTransactions(ctx context.Context, filter *TransactionFilter) ([]Transaction, error) {
 	var transactions []Transaction
@@ -368,11 +451,31 @@ func (s *SQLStore) ListTransactions(ctx context.Context, filter *TransactionFilt
 		if filter.SubcategoryID != nil {
 			query = query.Where("subcategory_id = ?", *filter.SubcategoryID)
 		}
+		if len(filter.AccountIDs) > 0 {
+			query = query.Where("account_id IN ?", filter.AccountIDs)
+		}
+		if filter.Unbound {
+			query = query.Where("account_id IS NULL")
+		}
 		if filter.StartDate != nil {
-			query = query.Where("created_at >= ?", *filter.StartDate)
+			query = query.Where("date >= ?", *filter.StartDate)
 		}
 		if filter.EndDate != nil {
-			query = query.Where("created_at <= ?", *filter.EndDate)
+			query = query.Where("date <= ?", *filter.EndDate)
+		}
+		if filter.ExcludeTransfers {
+			query = query.Where("transfer_of_id IS NULL")
+		}
+		// Amounts are stored as text, so that they keep their precision
+		if filter.MinAmount != nil {
+			query = query.Where("CAST(amount AS REAL) >= ?", filter.MinAmount.InexactFloat64())
+		}
+		if filter.MaxAmount != nil {
+			query = query.Where("CAST(amount AS REAL) <= ?", filter.MaxAmount.InexactFloat64())
+		}
+		if filter.Text != "" {
+			pattern := "%" + likeEscaper.Replace(strings.ToLower(filter.Text)) + "%"
+			query = query.Where(`(LOWER(description) LIKE ? ESCAPE '\' OR LOWER(reference) LIKE ? ESCAPE '\')`, pattern, pattern)
 		}
 	}
 	result := query.Find(&transactions)
@@ -382,15 +485,302 @@ func (s *SQLStore) ListTransactions(ctx context.Context, filter *TransactionFilt
 	return transactions, nil
 }
 
-// DeleteTransaction deletes a transaction from the database
+// likeEscaper escapes the wildcards of a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// DeleteTransaction deletes a transaction from the database. The other side
+// of a transfer it was part of is no longer a transfer.
 func (s *SQLStore) DeleteTransaction(ctx context.Context, id uint) error {
-	result := s.db.WithContext(ctx).Delete(&Transaction{}, id)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Transaction{}).Where("transfer_of_id = ?", id).
+			Updates(map[string]any{"transfer_of_id": nil, "transfer_status": ""}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&Transaction{}).Where("duplicate_of_id = ?", id).
+			Update("duplicate_of_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Transaction{}, id).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete transaction: %w", err)
+	}
+	return nil
+}
+
+// CreateAccount creates a new account in the database
+func (s *SQLStore) CreateAccount(ctx context.Context, account *Account) error {
+	if err := s.db.WithContext(ctx).Create(account).Error; err != nil {
+		return fmt.Errorf("failed to create account: %w", err)
+	}
+	return nil
+}
+
+// UpdateAccount updates an existing account in the database
+func (s *SQLStore) UpdateAccount(ctx context.Context, account *Account) error {
+	result := s.db.WithContext(ctx).Save(account)
 	if result.Error != nil {
-		return fmt.Errorf("failed to delete transaction: %w", result.Error)
+		return fmt.Errorf("failed to update account: %w", result.Error)
 	}
 	return nil
 }
 
+// GetAccountByID retrieves an account by its ID
+func (s *SQLStore) GetAccountByID(ctx context.Context, id uint) (*Account, error) {
+	var account Account
+	result := s.db.WithContext(ctx).First(&account, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get account: %w", result.Error)
+	}
+	return &account, nil
+}
+
+// GetAccountByName retrieves an account by its name
+func (s *SQLStore) GetAccountByName(ctx context.Context, name string) (*Account, error) {
+	var account Account
+	result := s.db.WithContext(ctx).Where("name = ?", name).First(&account)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get account by name: %w", result.Error)
+	}
+	return &account, nil
+}
+
+// ListAccounts returns all accounts ordered by name
+func (s *SQLStore) ListAccounts(ctx context.Context) ([]Account, error) {
+	var accounts []Account
+	result := s.db.WithContext(ctx).Order("name").Find(&accounts)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", result.Error)
+	}
+	return accounts, nil
+}
+
+// DeleteAccount deletes an account in a single database transaction, keeping
+// the transactions booked on it without an account
+func (s *SQLStore) DeleteAccount(ctx context.Context, id uint) (int64, error) {
+	var detached int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var account Account
+		if err := tx.First(&account, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		result := tx.Model(&Transaction{}).Where("account_id = ?", id).Update("account_id", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		detached = result.RowsAffected
+
+		if err := tx.Where("account_id = ?", id).Delete(&StatementBalance{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&account).Error
+	})
+	if err != nil {
+		if err == ErrNotFound {
+			return 0, err
+		}
+		return 0, fmt.Errorf("failed to delete account: %w", err)
+	}
+	return detached, nil
+}
+
+// CreateStatementBalance records a balance stated for an account
+func (s *SQLStore) CreateStatementBalance(ctx context.Context, balance *StatementBalance) error {
+	if err := s.db.WithContext(ctx).Create(balance).Error; err != nil {
+		return fmt.Errorf("failed to create statement balance: %w", err)
+	}
+	return nil
+}
+
+// ListStatementBalances returns the balances stated for an account, ordered by date
+func (s *SQLStore) ListStatementBalances(ctx context.Context, accountID uint) ([]StatementBalance, error) {
+	var balances []StatementBalance
+	if err := s.db.WithContext(ctx).Where("account_id = ?", accountID).Order("date, id").Find(&balances).Error; err != nil {
+		return nil, fmt.Errorf("failed to list statement balances: %w", err)
+	}
+	return balances, nil
+}
+
+// SaveExchangeRates stores the rates, replacing the rate already stored for the same day and currencies
+func (s *SQLStore) SaveExchangeRates(ctx context.Context, rates []ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}, {Name: "currency"}, {Name: "quote_currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+	}).CreateInBatches(rates, 500).Error
+	if err != nil {
+		return fmt.Errorf("failed to save exchange rates: %w", err)
+	}
+	return nil
+}
+
+// ListExchangeRates returns the rates of the days between start and end, ordered by date
+func (s *SQLStore) ListExchangeRates(ctx context.Context, start, end *time.Time) ([]ExchangeRate, error) {
+	query := s.db.WithContext(ctx)
+	if start != nil {
+		query = query.Where("date >= ?", *start)
+	}
+	if end != nil {
+		query = query.Where("date <= ?", *end)
+	}
+	var rates []ExchangeRate
+	if err := query.Order("date, currency, quote_currency").Find(&rates).Error; err != nil {
+		return nil, fmt.Errorf("failed to list exchange rates: %w", err)
+	}
+	return rates, nil
+}
+
+// CreateImportBatch records a new import batch
+func (s *SQLStore) CreateImportBatch(ctx context.Context, batch *ImportBatch) error {
+	if err := s.db.WithContext(ctx).Create(batch).Error; err != nil {
+		return fmt.Errorf("failed to create import batch: %w", err)
+	}
+	return nil
+}
+
+// UpdateImportBatch updates the counts and completion time of an import batch
+func (s *SQLStore) UpdateImportBatch(ctx context.Context, batch *ImportBatch) error {
+	result := s.db.WithContext(ctx).Save(batch)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update import batch: %w", result.Error)
+	}
+	return nil
+}
+
+// GetImportBatchByID retrieves an import batch by its ID
+func (s *SQLStore) GetImportBatchByID(ctx context.Context, id uint) (*ImportBatch, error) {
+	var batch ImportBatch
+	result := s.db.WithContext(ctx).First(&batch, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get import batch: %w", result.Error)
+	}
+	return &batch, nil
+}
+
+// ListImportBatches returns all import batches, most recent first
+func (s *SQLStore) ListImportBatches(ctx context.Context) ([]ImportBatch, error) {
+	var batches []ImportBatch
+	result := s.db.WithContext(ctx).Order("started_at DESC, id DESC").Find(&batches)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list import batches: %w", result.Error)
+	}
+	return batches, nil
+}
+
+// CreateProcessingJob creates a processing job together with its files
+func (s *SQLStore) CreateProcessingJob(ctx context.Context, job *ProcessingJob) error {
+	if err := s.db.WithContext(ctx).Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create processing job: %w", err)
+	}
+	return nil
+}
+
+// UpdateProcessingJob updates the job itself, leaving its files as they are
+func (s *SQLStore) UpdateProcessingJob(ctx context.Context, job *ProcessingJob) error {
+	if err := s.db.WithContext(ctx).Omit("Files").Save(job).Error; err != nil {
+		return fmt.Errorf("failed to update processing job: %w", err)
+	}
+	return nil
+}
+
+// GetProcessingJobByID retrieves a processing job and its files in walk order
+func (s *SQLStore) GetProcessingJobByID(ctx context.Context, id uint) (*ProcessingJob, error) {
+	var job ProcessingJob
+	result := s.db.WithContext(ctx).
+		Preload("Files", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		First(&job, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get processing job: %w", result.Error)
+	}
+	return &job, nil
+}
+
+// ListProcessingJobs returns all processing jobs with their files, most recent first
+func (s *SQLStore) ListProcessingJobs(ctx context.Context) ([]ProcessingJob, error) {
+	var jobs []ProcessingJob
+	result := s.db.WithContext(ctx).
+		Preload("Files", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Order("created_at DESC, id DESC").
+		Find(&jobs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list processing jobs: %w", result.Error)
+	}
+	return jobs, nil
+}
+
+// UpdateJobFile updates the state of a document in a processing job
+func (s *SQLStore) UpdateJobFile(ctx context.Context, file *JobFile) error {
+	if err := s.db.WithContext(ctx).Save(file).Error; err != nil {
+		return fmt.Errorf("failed to update job file: %w", err)
+	}
+	return nil
+}
+
+// DeleteImportBatch deletes the batch and every transaction it created in a
+// single database transaction. Duplicates flagged against the deleted
+// transactions by later imports become regular transactions, as do the other
+// sides of transfers.
+func (s *SQLStore) DeleteImportBatch(ctx context.Context, id uint) (int64, error) {
+	var deleted int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var batch ImportBatch
+		if err := tx.First(&batch, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		created := tx.Model(&Transaction{}).Select("id").Where("import_batch_id = ?", id)
+		if err := tx.Model(&Transaction{}).
+			Where("duplicate_of_id IN (?) AND (import_batch_id IS NULL OR import_batch_id <> ?)", created, id).
+			Update("duplicate_of_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&Transaction{}).
+			Where("transfer_of_id IN (?) AND (import_batch_id IS NULL OR import_batch_id <> ?)", created, id).
+			Updates(map[string]any{"transfer_of_id": nil, "transfer_status": ""}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("import_batch_id = ?", id).Delete(&Transaction{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+
+		if err := tx.Where("import_batch_id = ?", id).Delete(&StatementBalance{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&batch).Error
+	})
+	if err != nil {
+		if err == ErrNotFound {
+			return 0, err
+		}
+		return 0, fmt.Errorf("failed to delete import batch: %w", err)
+	}
+	return deleted, nil
+}
+
 // CreatePrompt creates a new prompt template in the database
 func (s *SQLStore) CreatePrompt(ctx context.Context, prompt *Prompt) error {
 	if err := s.db.WithContext(ctx).Create(prompt).Error; err != nil {
@@ -475,6 +865,13 @@ func (s *SQLStore) DeletePrompt(ctx context.Context, id uint) error {
 	return nil
 }
 
+// WithTransaction runs fn inside a database transaction
+func (s *SQLStore) WithTransaction(ctx context.Context, fn func(store Store) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&SQLStore{db: tx, logger: s.logger})
+	})
+}
+
 // Close closes the database connection
 func (s *SQLStore) Close() error {
 	sqlDB, err := s.db.DB()
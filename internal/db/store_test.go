@@ -3,8 +3,12 @@ package db
 import (
 	"context"
 	"errors"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -23,8 +27,14 @@ func createTestDB(t *testing.T) *gorm.DB {
 		&Subcategory{},
 		&CategorySubcategory{},
 		&Tag{},
+		&Account{},
+		&StatementBalance{},
+		&ExchangeRate{},
+		&ImportBatch{},
 		&Transaction{},
 		&Prompt{},
+		&ProcessingJob{},
+		&JobFile{},
 	); err != nil {
 		t.Fatalf("failed to migrate database: %v", err)
 	}
@@ -251,3 +261,458 @@ func TestSQLStore_DeleteCategory(t *testing.T) {
 		})
 	}
 }
+
+func TestSQLStore_DeleteImportBatch(t *testing.T) {
+	store, _ := createTestStore(t)
+	ctx := context.Background()
+
+	first := &ImportBatch{FileName: "januari.csv", Parser: "seb"}
+	second := &ImportBatch{FileName: "januari-igen.csv", Parser: "seb"}
+	for _, batch := range []*ImportBatch{first, second} {
+		if err := store.CreateImportBatch(ctx, batch); err != nil {
+			t.Fatalf("CreateImportBatch() unexpected error: %v", err)
+		}
+	}
+
+	original := &Transaction{Description: "ICA Maxi", Currency: CurrencySEK, ImportBatchID: &first.ID}
+	kept := &Transaction{Description: "Lön", Currency: CurrencySEK, ImportBatchID: &first.ID}
+	if err := store.CreateTransaction(ctx, original); err != nil {
+		t.Fatalf("CreateTransaction() unexpected error: %v", err)
+	}
+	if err := store.CreateTransaction(ctx, kept); err != nil {
+		t.Fatalf("CreateTransaction() unexpected error: %v", err)
+	}
+	duplicate := &Transaction{Description: "ICA Maxi", Currency: CurrencySEK, ImportBatchID: &second.ID, DuplicateOfID: &original.ID}
+	if err := store.CreateTransaction(ctx, duplicate); err != nil {
+		t.Fatalf("CreateTransaction() unexpected error: %v", err)
+	}
+
+	deleted, err := store.DeleteImportBatch(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("SQLStore.DeleteImportBatch() unexpected error: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("SQLStore.DeleteImportBatch() deleted = %d, want 2", deleted)
+	}
+
+	if _, err := store.GetImportBatchByID(ctx, first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SQLStore.DeleteImportBatch() batch still exists after deletion")
+	}
+	if _, err := store.GetTransactionByID(ctx, kept.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SQLStore.DeleteImportBatch() transaction %d still exists after deletion", kept.ID)
+	}
+
+	// The duplicate of a deleted transaction belongs to another batch and is kept, no longer flagged
+	got, err := store.GetTransactionByID(ctx, duplicate.ID)
+	if err != nil {
+		t.Fatalf("GetTransactionByID() unexpected error: %v", err)
+	}
+	if got.DuplicateOfID != nil {
+		t.Errorf("DuplicateOfID = %d, want nil", *got.DuplicateOfID)
+	}
+
+	if _, err := store.DeleteImportBatch(ctx, first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SQLStore.DeleteImportBatch() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestSQLStore_TransactionBalance(t *testing.T) {
+	store, _ := createTestStore(t)
+	ctx := context.Background()
+
+	withBalance := &Transaction{
+		Description: "ICA Maxi",
+		Currency:    CurrencySEK,
+		Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+		ValueDate:   time.Date(2025, 2, 22, 0, 0, 0, 0, time.UTC),
+		Amount:      decimal.RequireFromString("-1000.00"),
+		Balance:     decimal.NewNullDecimal(decimal.RequireFromString("2814.16")),
+	}
+	withoutBalance := &Transaction{Description: "Kortköp", Currency: CurrencySEK}
+	for _, tx := range []*Transaction{withBalance, withoutBalance} {
+		if err := store.CreateTransaction(ctx, tx); err != nil {
+			t.Fatalf("CreateTransaction() unexpected error: %v", err)
+		}
+	}
+
+	got, err := store.GetTransactionByID(ctx, withBalance.ID)
+	if err != nil {
+		t.Fatalf("GetTransactionByID() unexpected error: %v", err)
+	}
+	if !got.Balance.Valid || !got.Balance.Decimal.Equal(withBalance.Balance.Decimal) {
+		t.Errorf("Balance = %v, want %v", got.Balance, withBalance.Balance)
+	}
+	if !got.ValueDate.Equal(withBalance.ValueDate) {
+		t.Errorf("ValueDate = %v, want %v", got.ValueDate, withBalance.ValueDate)
+	}
+
+	got, err = store.GetTransactionByID(ctx, withoutBalance.ID)
+	if err != nil {
+		t.Fatalf("GetTransactionByID() unexpected error: %v", err)
+	}
+	if got.Balance.Valid {
+		t.Errorf("Balance = %v, want none", got.Balance)
+	}
+}
+
+func TestSQLStore_Account(t *testing.T) {
+	store, _ := createTestStore(t)
+	ctx := context.Background()
+
+	checking := &Account{Name: "SEB Lönekonto", Bank: "seb", Number: "5490 99-000 01", Type: AccountTypeChecking, Currency: CurrencySEK}
+	card := &Account{Name: "Eurocard", Type: AccountTypeCreditCard, Currency: CurrencySEK}
+	for _, account := range []*Account{checking, card} {
+		if err := store.CreateAccount(ctx, account); err != nil {
+			t.Fatalf("CreateAccount() unexpected error: %v", err)
+		}
+	}
+	if err := store.CreateAccount(ctx, &Account{Name: "Sparkonto", Type: "piggy_bank", Currency: CurrencySEK}); err == nil {
+		t.Error("CreateAccount() expected an error for an invalid account type")
+	}
+
+	got, err := store.GetAccountByName(ctx, "SEB Lönekonto")
+	if err != nil {
+		t.Fatalf("GetAccountByName() unexpected error: %v", err)
+	}
+	if got.Number != "54909900001" {
+		t.Errorf("Number = %q, want the normalized number 54909900001", got.Number)
+	}
+
+	for _, tx := range []*Transaction{
+		{Description: "ICA Maxi", Currency: CurrencySEK, AccountID: &checking.ID},
+		{Description: "Lön", Currency: CurrencySEK, AccountID: &checking.ID},
+		{Description: "SAS", Currency: CurrencySEK, AccountID: &card.ID},
+	} {
+		if err := store.CreateTransaction(ctx, tx); err != nil {
+			t.Fatalf("CreateTransaction() unexpected error: %v", err)
+		}
+	}
+	transactions, err := store.ListTransactions(ctx, &TransactionFilter{AccountIDs: []uint{checking.ID}})
+	if err != nil {
+		t.Fatalf("ListTransactions() unexpected error: %v", err)
+	}
+	if len(transactions) != 2 {
+		t.Errorf("ListTransactions() returned %d transactions for the account, want 2", len(transactions))
+	}
+
+	detached, err := store.DeleteAccount(ctx, checking.ID)
+	if err != nil {
+		t.Fatalf("DeleteAccount() unexpected error: %v", err)
+	}
+	if detached != 2 {
+		t.Errorf("DeleteAccount() detached = %d, want 2", detached)
+	}
+	if _, err := store.GetAccountByID(ctx, checking.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetAccountByID() error = %v, want %v", err, ErrNotFound)
+	}
+	unbound, err := store.ListTransactions(ctx, &TransactionFilter{Unbound: true})
+	if err != nil {
+		t.Fatalf("ListTransactions() unexpected error: %v", err)
+	}
+	if len(unbound) != 2 {
+		t.Errorf("ListTransactions() returned %d unbound transactions, want 2", len(unbound))
+	}
+	all, _ := store.ListTransactions(ctx, nil)
+	if len(all) != 3 {
+		t.Errorf("DeleteAccount() kept %d transactions, want 3", len(all))
+	}
+	accounts, _ := store.ListAccounts(ctx)
+	if len(accounts) != 1 || accounts[0].Name != "Eurocard" {
+		t.Errorf("ListAccounts() = %v, want only Eurocard", accounts)
+	}
+}
+
+func TestSQLStore_StatementBalance(t *testing.T) {
+	store, _ := createTestStore(t)
+	ctx := context.Background()
+
+	account := &Account{Name: "SEB Lönekonto", Type: AccountTypeChecking, Currency: CurrencySEK}
+	if err := store.CreateAccount(ctx, account); err != nil {
+		t.Fatalf("CreateAccount() unexpected error: %v", err)
+	}
+	batch := &ImportBatch{FileName: "september.csv"}
+	if err := store.CreateImportBatch(ctx, batch); err != nil {
+		t.Fatalf("CreateImportBatch() unexpected error: %v", err)
+	}
+	for _, balance := range []*StatementBalance{
+		{AccountID: account.ID, Date: time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC), Type: BalanceClosing, Amount: decimal.NewFromInt(900), Currency: CurrencySEK, ImportBatchID: &batch.ID},
+		{AccountID: account.ID, Date: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), Type: BalanceOpening, Amount: decimal.NewFromInt(1000), Currency: CurrencySEK},
+	} {
+		if err := store.CreateStatementBalance(ctx, balance); err != nil {
+			t.Fatalf("CreateStatementBalance() unexpected error: %v", err)
+		}
+	}
+
+	balances, err := store.ListStatementBalances(ctx, account.ID)
+	if err != nil {
+		t.Fatalf("ListStatementBalances() unexpected error: %v", err)
+	}
+	if len(balances) != 2 || balances[0].Type != BalanceOpening || !balances[1].Amount.Equal(decimal.NewFromInt(900)) {
+		t.Errorf("ListStatementBalances() = %+v, want the opening and closing balance by date", balances)
+	}
+	if got := balances[1].Instant().Format("2006-01-02"); got != "2026-10-01" {
+		t.Errorf("Instant() of the closing balance = %s, want 2026-10-01", got)
+	}
+
+	// Undoing the import removes the balances it recorded
+	if _, err := store.DeleteImportBatch(ctx, batch.ID); err != nil {
+		t.Fatalf("DeleteImportBatch() unexpected error: %v", err)
+	}
+	balances, _ = store.ListStatementBalances(ctx, account.ID)
+	if len(balances) != 1 || balances[0].ImportBatchID != nil {
+		t.Errorf("ListStatementBalances() after undo = %+v, want the balance entered by hand", balances)
+	}
+}
+
+func TestSQLStore_ListTransactions_filter(t *testing.T) {
+	store, _ := createTestStore(t)
+	ctx := context.Background()
+	for _, tx := range []*Transaction{
+		{Description: "ICA Maxi", Amount: decimal.RequireFromString("-249.90"), Currency: CurrencySEK},
+		{Description: "Lön", Amount: decimal.RequireFromString("25000"), Currency: CurrencySEK},
+		{Description: "Swish", Reference: "ica 100%", Amount: decimal.RequireFromString("-100"), Currency: CurrencySEK},
+		{Description: "Hyra", Amount: decimal.RequireFromString("-8000"), Currency: CurrencySEK},
+	} {
+		if err := store.CreateTransaction(ctx, tx); err != nil {
+			t.Fatalf("CreateTransaction() unexpected error: %v", err)
+		}
+	}
+	amount := func(value string) *decimal.Decimal {
+		d := decimal.RequireFromString(value)
+		return &d
+	}
+
+	tests := []struct {
+		name   string
+		filter TransactionFilter
+		want   []string
+	}{
+		{name: "Successfully_filter_by_amount_range", filter: TransactionFilter{MinAmount: amount("-1000"), MaxAmount: amount("-100")}, want: []string{"ICA Maxi", "Swish"}},
+		{name: "Successfully_filter_by_minimum_amount", filter: TransactionFilter{MinAmount: amount("-100")}, want: []string{"Lön", "Swish"}},
+		{name: "Successfully_search_description_and_reference", filter: TransactionFilter{Text: "ICA"}, want: []string{"ICA Maxi", "Swish"}},
+		{name: "Successfully_search_wildcard_literally", filter: TransactionFilter{Text: "100%"}, want: []string{"Swish"}},
+		{name: "Successfully_combine_text_and_amount", filter: TransactionFilter{Text: "ica", MaxAmount: amount("-200")}, want: []string{"ICA Maxi"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions, err := store.ListTransactions(ctx, &tt.filter)
+			if err != nil {
+				t.Fatalf("ListTransactions() unexpected error: %v", err)
+			}
+			var got []string
+			for _, tx := range transactions {
+				got = append(got, tx.Description)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("ListTransactions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLStore_ExchangeRate(t *testing.T) {
+	store, _ := createTestStore(t)
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2026, 9, d, 0, 0, 0, 0, time.UTC) }
+
+	rates := []ExchangeRate{
+		{Date: day(2), Currency: CurrencyEUR, QuoteCurrency: CurrencySEK, Rate: decimal.RequireFromString("11.05"), Source: "riksbank"},
+		{Date: day(1), Currency: CurrencyEUR, QuoteCurrency: CurrencySEK, Rate: decimal.RequireFromString("11.10"), Source: "riksbank"},
+		{Date: day(1), Currency: CurrencyEUR, QuoteCurrency: CurrencyUSD, Rate: decimal.RequireFromString("1.17"), Source: "ecb"},
+	}
+	if err := store.SaveExchangeRates(ctx, rates); err != nil {
+		t.Fatalf("SaveExchangeRates() unexpected error: %v", err)
+	}
+	// Importing a day again replaces its rate
+	if err := store.SaveExchangeRates(ctx, []ExchangeRate{
+		{Date: day(2), Currency: CurrencyEUR, QuoteCurrency: CurrencySEK, Rate: decimal.RequireFromString("11.07"), Source: "ecb"},
+	}); err != nil {
+		t.Fatalf("SaveExchangeRates() unexpected error: %v", err)
+	}
+
+	start := day(2)
+	stored, err := store.ListExchangeRates(ctx, &start, nil)
+	if err != nil {
+		t.Fatalf("ListExchangeRates() unexpected error: %v", err)
+	}
+	if len(stored) != 1 || !stored[0].Rate.Equal(decimal.RequireFromString("11.07")) || stored[0].Source != "ecb" {
+		t.Errorf("ListExchangeRates() = %+v, want the replaced rate of 2026-09-02", stored)
+	}
+	all, _ := store.ListExchangeRates(ctx, nil, nil)
+	if len(all) != 3 || !all[0].Date.Equal(day(1)) {
+		t.Errorf("ListExchangeRates() = %+v, want 3 rates by date", all)
+	}
+
+	invalid := []ExchangeRate{{Date: day(3), Currency: "XYZ", QuoteCurrency: CurrencySEK, Rate: decimal.NewFromInt(1)}}
+	if err := store.SaveExchangeRates(ctx, invalid); err == nil {
+		t.Error("SaveExchangeRates() expected an error for an unknown currency")
+	}
+}
+
+func TestSQLStore_Transfer(t *testing.T) {
+	store, _ := createTestStore(t)
+	ctx := context.Background()
+
+	out := &Transaction{Description: "Till sparkonto", Amount: decimal.NewFromInt(-5000), Currency: CurrencySEK}
+	in := &Transaction{Description: "Från lönekonto", Amount: decimal.NewFromInt(5000), Currency: CurrencySEK}
+	for _, tx := range []*Transaction{out, in} {
+		if err := store.CreateTransaction(ctx, tx); err != nil {
+			t.Fatalf("CreateTransaction() unexpected error: %v", err)
+		}
+	}
+	out.TransferOfID, out.TransferStatus = &in.ID, TransferDetected
+	in.TransferOfID, in.TransferStatus = &out.ID, TransferDetected
+	for _, tx := range []*Transaction{out, in} {
+		if err := store.UpdateTransaction(ctx, tx); err != nil {
+			t.Fatalf("UpdateTransaction() unexpected error: %v", err)
+		}
+	}
+
+	transactions, err := store.ListTransactions(ctx, &TransactionFilter{ExcludeTransfers: true})
+	if err != nil {
+		t.Fatalf("ListTransactions() unexpected error: %v", err)
+	}
+	if len(transactions) != 0 {
+		t.Errorf("ListTransactions() returned %d transfers, want none", len(transactions))
+	}
+
+	// Deleting one side leaves the other as a regular transaction
+	if err := store.DeleteTransaction(ctx, out.ID); err != nil {
+		t.Fatalf("DeleteTransaction() unexpected error: %v", err)
+	}
+	got, err := store.GetTransactionByID(ctx, in.ID)
+	if err != nil {
+		t.Fatalf("GetTransactionByID() unexpected error: %v", err)
+	}
+	if got.IsTransfer() || got.TransferStatus != "" {
+		t.Errorf("transfer = %v %q, want the link removed", got.TransferOfID, got.TransferStatus)
+	}
+}
+
+func TestSQLStore_DeleteTransaction_Successfully_clear_duplicate_flags(t *testing.T) {
+	store, _ := createTestStore(t)
+	ctx := context.Background()
+	original := &Transaction{Description: "ICA Maxi", Amount: decimal.NewFromInt(-200), Currency: CurrencySEK}
+	if err := store.CreateTransaction(ctx, original); err != nil {
+		t.Fatalf("CreateTransaction() unexpected error: %v", err)
+	}
+	duplicate := &Transaction{Description: "ICA Maxi", Amount: decimal.NewFromInt(-200), Currency: CurrencySEK, DuplicateOfID: &original.ID}
+	if err := store.CreateTransaction(ctx, duplicate); err != nil {
+		t.Fatalf("CreateTransaction() unexpected error: %v", err)
+	}
+
+	if err := store.DeleteTransaction(ctx, original.ID); err != nil {
+		t.Fatalf("DeleteTransaction() unexpected error: %v", err)
+	}
+
+	got, err := store.GetTransactionByID(ctx, duplicate.ID)
+	if err != nil {
+		t.Fatalf("GetTransactionByID() unexpected error: %v", err)
+	}
+	if got.DuplicateOfID != nil {
+		t.Errorf("DuplicateOfID = %d, want nil after deleting the original", *got.DuplicateOfID)
+	}
+}
+
+func TestSQLStore_WithTransaction(t *testing.T) {
+	tests := []struct {
+		name      string
+		fnErr     error
+		wantCount int
+	}{
+		{
+			name:      "Successfully_commit_changes",
+			wantCount: 1,
+		},
+		{
+			name:      "Error_roll_back_changes",
+			fnErr:     errors.New("row failed"),
+			wantCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, _ := createTestStore(t)
+			ctx := context.Background()
+
+			err := store.WithTransaction(ctx, func(txStore Store) error {
+				batch := &ImportBatch{FileName: "statement.csv"}
+				if err := txStore.CreateImportBatch(ctx, batch); err != nil {
+					return err
+				}
+				if err := txStore.CreateTransaction(ctx, &Transaction{Description: "ICA Maxi", Currency: CurrencySEK, ImportBatchID: &batch.ID}); err != nil {
+					return err
+				}
+				return tt.fnErr
+			})
+			if !errors.Is(err, tt.fnErr) {
+				t.Errorf("SQLStore.WithTransaction() error = %v, wantErr %v", err, tt.fnErr)
+			}
+
+			transactions, err := store.ListTransactions(ctx, nil)
+			if err != nil {
+				t.Fatalf("ListTransactions() unexpected error: %v", err)
+			}
+			batches, err := store.ListImportBatches(ctx)
+			if err != nil {
+				t.Fatalf("ListImportBatches() unexpected error: %v", err)
+			}
+			if len(transactions) != tt.wantCount || len(batches) != tt.wantCount {
+				t.Errorf("store contains %d transactions and %d batches, want %d of each", len(transactions), len(batches), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestSQLStore_ProcessingJob(t *testing.T) {
+	store, _ := createTestStore(t)
+	ctx := context.Background()
+
+	job := &ProcessingJob{
+		Path:   "/statements",
+		Status: JobRunning,
+		Files: []JobFile{
+			{Position: 1, Path: "/statements/februari.csv", Status: JobFilePending},
+			{Position: 0, Path: "/statements/januari.csv", Status: JobFilePending},
+		},
+	}
+	if err := store.CreateProcessingJob(ctx, job); err != nil {
+		t.Fatalf("CreateProcessingJob() unexpected error: %v", err)
+	}
+
+	file := job.Files[1]
+	file.Status = JobFileStored
+	file.Stored = 3
+	if err := store.UpdateJobFile(ctx, &file); err != nil {
+		t.Fatalf("UpdateJobFile() unexpected error: %v", err)
+	}
+	// Saving the job leaves the files as they are
+	job.Status = JobInterrupted
+	if err := store.UpdateProcessingJob(ctx, job); err != nil {
+		t.Fatalf("UpdateProcessingJob() unexpected error: %v", err)
+	}
+
+	got, err := store.GetProcessingJobByID(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetProcessingJobByID() unexpected error: %v", err)
+	}
+	if got.Status != JobInterrupted {
+		t.Errorf("Status = %s, want %s", got.Status, JobInterrupted)
+	}
+	if len(got.Files) != 2 || got.Files[0].Path != "/statements/januari.csv" {
+		t.Fatalf("Files = %+v, want januari.csv first", got.Files)
+	}
+	if got.Files[0].Status != JobFileStored || got.Files[0].Stored != 3 {
+		t.Errorf("Files[0] = %s with %d stored, want stored with 3", got.Files[0].Status, got.Files[0].Stored)
+	}
+
+	jobs, err := store.ListProcessingJobs(ctx)
+	if err != nil || len(jobs) != 1 || len(jobs[0].Files) != 2 {
+		t.Errorf("ListProcessingJobs() = %+v, %v, want one job with 2 files", jobs, err)
+	}
+	if _, err := store.GetProcessingJobByID(ctx, job.ID+1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetProcessingJobByID() error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,339 @@
+package docprocess
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxContainerDepth limits how deep containers inside containers, such as a
+// ZIP archive attached to an email, are opened
+const maxContainerDepth = 3
+
+// ContainerDocument is a document extracted from a ZIP archive or an email
+type ContainerDocument struct {
+	Entry   string // Location within the container, e.g. "2025/januari.csv" or "message-2/statement.pdf"
+	Name    string // File name of the document
+	Subject string // Subject of the email the document was attached to
+	Sender  string // Sender of the email the document was attached to
+	Data    []byte
+}
+
+// IsContainer reports whether the file is a ZIP archive, email or mailbox
+// whose documents are processed instead of the file itself
+func IsContainer(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".zip", ".eml", ".mbox":
+		return true
+	default:
+		return false
+	}
+}
+
+// ReadContainer extracts the documents of a ZIP archive, email (.eml) or
+// mailbox (.mbox). Archives and emails found inside are opened as well, and
+// the documents of a mailbox are located by message, starting at message-1.
+func ReadContainer(filename string) ([]ContainerDocument, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, &ProcessingError{
+			Stage:    StageExtraction,
+			Document: filename,
+			Err:      fmt.Errorf("failed to read container: %w", err),
+		}
+	}
+	docs, err := readContainer(filepath.Base(filename), data, 1)
+	if err != nil {
+		return nil, &ProcessingError{
+			Stage:    StageExtraction,
+			Document: filename,
+			Err:      err,
+		}
+	}
+	return docs, nil
+}
+
+// Email is an email with the documents attached to it
+type Email struct {
+	Subject   string
+	Sender    string
+	Documents []ContainerDocument
+}
+
+// ReadEmail reads an email in RFC 822 format and extracts its attachments,
+// opening attached archives and emails as well
+func ReadEmail(data []byte) (*Email, error) {
+	return parseEmail(data, 1)
+}
+
+// readContainer extracts the documents of container data by its file extension
+func readContainer(name string, data []byte, depth int) ([]ContainerDocument, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".zip":
+		return readZip(data, depth)
+	case ".eml":
+		return readEmail(data, depth)
+	case ".mbox":
+		return readMailbox(data, depth)
+	default:
+		return nil, fmt.Errorf("unsupported container type: %s", filepath.Ext(name))
+	}
+}
+
+// expand returns the document, or the documents inside it when it is a container itself
+func expand(doc ContainerDocument, depth int) ([]ContainerDocument, error) {
+	if !IsContainer(doc.Name) || depth >= maxContainerDepth {
+		return []ContainerDocument{doc}, nil
+	}
+	inner, err := readContainer(doc.Name, doc.Data, depth+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", doc.Entry, err)
+	}
+	for i := range inner {
+		inner[i].Entry = path.Join(doc.Entry, inner[i].Entry)
+		if inner[i].Subject == "" && inner[i].Sender == "" {
+			inner[i].Subject, inner[i].Sender = doc.Subject, doc.Sender
+		}
+	}
+	return inner, nil
+}
+
+// readZip extracts the files of a ZIP archive, leaving out directories and
+// hidden files such as the __MACOSX resource forks
+func readZip(data []byte, depth int) ([]ContainerDocument, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ZIP archive: %w", err)
+	}
+
+	var docs []ContainerDocument
+	for _, file := range archive.File {
+		entry := path.Clean(strings.ReplaceAll(file.Name, "\\", "/"))
+		if file.FileInfo().IsDir() || isHiddenEntry(entry) {
+			continue
+		}
+		content, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+		expanded, err := expand(ContainerDocument{Entry: entry, Name: path.Base(entry), Data: content}, depth)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, expanded...)
+	}
+	return docs, nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract %s: %w", file.Name, err)
+	}
+	return content, nil
+}
+
+// isHiddenEntry reports whether any element of the entry starts with a dot or is __MACOSX
+func isHiddenEntry(entry string) bool {
+	for _, element := range strings.Split(entry, "/") {
+		if strings.HasPrefix(element, ".") || element == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+// readEmail extracts the attachments of an email
+func readEmail(data []byte, depth int) ([]ContainerDocument, error) {
+	email, err := parseEmail(data, depth)
+	if err != nil {
+		return nil, err
+	}
+	return email.Documents, nil
+}
+
+// parseEmail reads the subject and sender of an email and extracts its attachments
+func parseEmail(data []byte, depth int) (*Email, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid email: %w", err)
+	}
+
+	var decoder mime.WordDecoder
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	sender, err := decoder.DecodeHeader(msg.Header.Get("From"))
+	if err != nil {
+		sender = msg.Header.Get("From")
+	}
+
+	attachments, err := readParts(msg.Header, msg.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []ContainerDocument
+	seen := make(map[string]int)
+	for _, attachment := range attachments {
+		// Attachments with the same name are told apart by a number
+		entry := attachment.name
+		seen[entry]++
+		if seen[entry] > 1 {
+			ext := path.Ext(entry)
+			entry = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(entry, ext), seen[attachment.name], ext)
+		}
+		expanded, err := expand(ContainerDocument{
+			Entry:   entry,
+			Name:    attachment.name,
+			Subject: subject,
+			Sender:  sender,
+			Data:    attachment.data,
+		}, depth)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, expanded...)
+	}
+	return &Email{Subject: subject, Sender: sender, Documents: docs}, nil
+}
+
+// attachment is a named part of an email
+type attachment struct {
+	name string
+	data []byte
+}
+
+// partHeader gives access to the headers of a message or MIME part
+type partHeader interface {
+	Get(key string) string
+}
+
+// readParts collects the named parts of a message body, descending into multipart parts
+func readParts(header partHeader, body io.Reader) ([]attachment, error) {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		var attachments []attachment
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid multipart email: %w", err)
+			}
+			found, err := readParts(part.Header, part)
+			if err != nil {
+				return nil, err
+			}
+			attachments = append(attachments, found...)
+		}
+		return attachments, nil
+	}
+
+	name := partFileName(header, params)
+	if name == "" {
+		return nil, nil
+	}
+	data, err := io.ReadAll(decodeTransfer(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode attachment %s: %w", name, err)
+	}
+	return []attachment{{name: name, data: data}}, nil
+}
+
+// partFileName returns the attachment file name from the Content-Disposition
+// or, for older mailers, the Content-Type name
+func partFileName(header partHeader, contentParams map[string]string) string {
+	name := contentParams["name"]
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		name = params["filename"]
+	}
+	var decoder mime.WordDecoder
+	if decoded, err := decoder.DecodeHeader(name); err == nil {
+		name = decoded
+	}
+	name = strings.ReplaceAll(strings.TrimSpace(name), "\\", "/")
+	if name == "" {
+		return ""
+	}
+	return path.Base(name)
+}
+
+// decodeTransfer decodes base64 parts. Quoted-printable parts are decoded by
+// the multipart reader already.
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	if strings.EqualFold(strings.TrimSpace(encoding), "base64") {
+		return base64.NewDecoder(base64.StdEncoding, body)
+	}
+	return body
+}
+
+// readMailbox extracts the attachments of the emails in an mbox mailbox
+func readMailbox(data []byte, depth int) ([]ContainerDocument, error) {
+	var docs []ContainerDocument
+	for i, message := range splitMailbox(data) {
+		attachments, err := readEmail(message, depth)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i+1, err)
+		}
+		for _, doc := range attachments {
+			doc.Entry = path.Join(fmt.Sprintf("message-%d", i+1), doc.Entry)
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+// splitMailbox splits an mbox mailbox on its "From " separator lines and
+// unquotes the ">From " lines of the messages
+func splitMailbox(data []byte) [][]byte {
+	var messages [][]byte
+	var current *bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "From ") {
+			if current != nil {
+				messages = append(messages, current.Bytes())
+			}
+			current = &bytes.Buffer{}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if unquoted := strings.TrimLeft(line, ">"); len(unquoted) < len(line) && strings.HasPrefix(unquoted, "From ") {
+			line = line[1:]
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+	if current != nil {
+		messages = append(messages, current.Bytes())
+	}
+	return messages
+}
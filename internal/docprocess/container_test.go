@@ -0,0 +1,165 @@
+package docprocess
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// createTestZip creates a ZIP archive holding the given files in order
+func createTestZip(t *testing.T, files ...[2]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := archive.Create(file[0])
+		if err != nil {
+			t.Fatalf("Create() unexpected error: %v", err)
+		}
+		if _, err := w.Write([]byte(file[1])); err != nil {
+			t.Fatalf("Write() unexpected error: %v", err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Close() unexpected error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// createTestEmail creates a multipart email with a text body and base64 encoded attachments
+func createTestEmail(subject, from string, attachments ...[2]string) string {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("Subject: " + subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: multipart/mixed; boundary=\"grans-42\"\r\n\r\n")
+	b.WriteString("--grans-42\r\nContent-Type: text/plain; charset=utf-8\r\n\r\nHär kommer ditt kontoutdrag.\r\n")
+	for _, attachment := range attachments {
+		b.WriteString("--grans-42\r\n")
+		b.WriteString("Content-Type: application/octet-stream\r\n")
+		b.WriteString("Content-Disposition: attachment; filename=\"" + attachment[0] + "\"\r\n")
+		b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+		encoded := base64.StdEncoding.EncodeToString([]byte(attachment[1]))
+		for len(encoded) > 76 {
+			b.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		b.WriteString(encoded + "\r\n")
+	}
+	b.WriteString("--grans-42--\r\n")
+	return b.String()
+}
+
+func writeContainer(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("WriteFile() unexpected error: %v", err)
+	}
+	return path
+}
+
+func TestReadContainer(t *testing.T) {
+	statement := strings.Repeat("Bokföringsdatum;Text;Belopp\n2025-02-24;ICA Kvantum;-1000.00\n", 3)
+	email := createTestEmail("=?UTF-8?Q?Kontoutdrag_f=C3=B6r_februari?=", "SEB <info@seb.se>",
+		[2]string{"februari.csv", statement}, [2]string{"februari.csv", "second"})
+
+	tests := []struct {
+		name        string
+		file        string
+		data        []byte
+		wantEntries []string
+		wantSubject string
+		wantSender  string
+		wantErr     bool
+	}{
+		{
+			name: "Successfully_read_zip_archive",
+			file: "utdrag.zip",
+			data: createTestZip(t,
+				[2]string{"2025/januari.csv", statement},
+				[2]string{"__MACOSX/2025/._januari.csv", "resource fork"},
+				[2]string{"2025/.DS_Store", "finder"},
+				[2]string{"faktura.pdf", "%PDF-1.4"}),
+			wantEntries: []string{"2025/januari.csv", "faktura.pdf"},
+		},
+		{
+			name:        "Successfully_read_email_attachments",
+			file:        "utdrag.eml",
+			data:        []byte(email),
+			wantEntries: []string{"februari.csv", "februari-2.csv"},
+			wantSubject: "Kontoutdrag för februari",
+			wantSender:  "SEB <info@seb.se>",
+		},
+		{
+			name: "Successfully_read_mailbox_messages",
+			file: "inkorg.mbox",
+			data: []byte("From info@seb.se Mon Feb 24 10:00:00 2025\n" +
+				createTestEmail("Februari", "SEB <info@seb.se>", [2]string{"februari.csv", statement}) +
+				"\nFrom faktura@ellevio.se Tue Feb 25 10:00:00 2025\n" +
+				createTestEmail("Faktura", "Ellevio <faktura@ellevio.se>", [2]string{"faktura.pdf", "%PDF-1.4"})),
+			wantEntries: []string{"message-1/februari.csv", "message-2/faktura.pdf"},
+			wantSubject: "Februari",
+			wantSender:  "SEB <info@seb.se>",
+		},
+		{
+			name: "Successfully_open_archive_attached_to_email",
+			file: "bunt.eml",
+			data: []byte(createTestEmail("Utdrag", "Nordea <noreply@nordea.se>",
+				[2]string{"utdrag.zip", string(createTestZip(t, [2]string{"mars.csv", statement}))})),
+			wantEntries: []string{"utdrag.zip/mars.csv"},
+			wantSubject: "Utdrag",
+			wantSender:  "Nordea <noreply@nordea.se>",
+		},
+		{
+			name:    "Error_invalid_zip_archive",
+			file:    "trasig.zip",
+			data:    []byte("not a zip archive"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := ReadContainer(writeContainer(t, tt.file, tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadContainer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var entries []string
+			for _, doc := range docs {
+				entries = append(entries, doc.Entry)
+			}
+			if strings.Join(entries, ",") != strings.Join(tt.wantEntries, ",") {
+				t.Fatalf("ReadContainer() entries = %v, want %v", entries, tt.wantEntries)
+			}
+			if docs[0].Subject != tt.wantSubject || docs[0].Sender != tt.wantSender {
+				t.Errorf("ReadContainer() provenance = %q from %q, want %q from %q",
+					docs[0].Subject, docs[0].Sender, tt.wantSubject, tt.wantSender)
+			}
+			if strings.HasSuffix(docs[0].Name, ".csv") && string(docs[0].Data) != statement {
+				t.Errorf("ReadContainer() data = %q, want %q", docs[0].Data, statement)
+			}
+		})
+	}
+}
+
+func TestSplitMailbox_Successfully_unquote_from_lines(t *testing.T) {
+	mailbox := "From a@example.com Mon Feb 24 10:00:00 2025\nSubject: Ett\n\n>From the bank\n>>From quoted\n\n" +
+		"From b@example.com Mon Feb 24 11:00:00 2025\nSubject: Två\n\nBody\n"
+
+	messages := splitMailbox([]byte(mailbox))
+	if len(messages) != 2 {
+		t.Fatalf("splitMailbox() returned %d messages, want 2", len(messages))
+	}
+	if want := "Subject: Ett\n\nFrom the bank\n>From quoted\n\n"; string(messages[0]) != want {
+		t.Errorf("splitMailbox() first message = %q, want %q", messages[0], want)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/lindehoff/Budget-Assist/internal/ai"
+	"github.com/lindehoff/Budget-Assist/internal/processor"
 	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 	"github.com/shopspring/decimal"
@@ -272,13 +273,21 @@ func (p *PDFProcessor) CanProcess(filePath string) bool {
 type DefaultProcessorFactory struct {
 	logger    *slog.Logger
 	aiService ai.Service
+	parsers   *processor.Registry
 }
 
-// NewDefaultProcessorFactory creates a new processor factory
+// NewDefaultProcessorFactory creates a new processor factory using the built-in parsers
 func NewDefaultProcessorFactory(logger *slog.Logger, aiService ai.Service) *DefaultProcessorFactory {
+	return NewProcessorFactory(logger, aiService, processor.NewDefaultRegistry())
+}
+
+// NewProcessorFactory creates a new processor factory. XLSX workbooks are
+// mapped with the XLSX profiles registered in parsers.
+func NewProcessorFactory(logger *slog.Logger, aiService ai.Service, parsers *processor.Registry) *DefaultProcessorFactory {
 	return &DefaultProcessorFactory{
 		logger:    logger,
 		aiService: aiService,
+		parsers:   parsers,
 	}
 }
 
@@ -287,6 +296,8 @@ func (f *DefaultProcessorFactory) CreateProcessor(docType DocumentType) (Documen
 	switch docType {
 	case TypePDF:
 		return NewPDFProcessor(f.logger, f.aiService), nil
+	case TypeXLSX:
+		return NewXLSXProcessor(f.logger, f.parsers), nil
 	default:
 		return nil, fmt.Errorf("unsupported document type: %s", docType)
 	}
@@ -294,5 +305,5 @@ func (f *DefaultProcessorFactory) CreateProcessor(docType DocumentType) (Documen
 
 // SupportedTypes returns a list of supported document types
 func (f *DefaultProcessorFactory) SupportedTypes() []DocumentType {
-	return []DocumentType{TypePDF}
+	return []DocumentType{TypePDF, TypeXLSX}
 }
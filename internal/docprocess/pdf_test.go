@@ -377,12 +377,15 @@ func TestDefaultProcessorFactory_SupportedTypes(t *testing.T) {
 	factory := NewDefaultProcessorFactory(logger, aiService)
 
 	types := factory.SupportedTypes()
-	if len(types) != 1 {
-		t.Errorf("DefaultProcessorFactory.SupportedTypes() length = %d, want 1", len(types))
+	if len(types) != 2 {
+		t.Errorf("DefaultProcessorFactory.SupportedTypes() length = %d, want 2", len(types))
 	}
 	if len(types) > 0 && types[0] != TypePDF {
 		t.Errorf("DefaultProcessorFactory.SupportedTypes()[0] = %v, want %v", types[0], TypePDF)
 	}
+	if len(types) > 1 && types[1] != TypeXLSX {
+		t.Errorf("DefaultProcessorFactory.SupportedTypes()[1] = %v, want %v", types[1], TypeXLSX)
+	}
 }
 
 func TestPDFProcessor_extractDocumentWithAI(t *testing.T) {
@@ -0,0 +1,132 @@
+package docprocess
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/processor"
+	"github.com/xuri/excelize/v2"
+)
+
+// XLSXProcessor processes Excel workbooks using the XLSX mapping profiles of a parser registry
+type XLSXProcessor struct {
+	logger  *slog.Logger
+	parsers *processor.Registry
+}
+
+// NewXLSXProcessor creates a new XLSX processor
+func NewXLSXProcessor(logger *slog.Logger, parsers *processor.Registry) *XLSXProcessor {
+	return &XLSXProcessor{
+		logger:  logger,
+		parsers: parsers,
+	}
+}
+
+// Type returns the document type this processor handles
+func (p *XLSXProcessor) Type() DocumentType {
+	return TypeXLSX
+}
+
+// Validate checks if the provided file is a readable XLSX workbook
+func (p *XLSXProcessor) Validate(file io.Reader) error {
+	f, err := excelize.OpenReader(file)
+	if err != nil {
+		return &ProcessingError{
+			Stage:    StageValidation,
+			Document: "unknown",
+			Err:      fmt.Errorf("invalid XLSX format: %w", err),
+		}
+	}
+	return f.Close()
+}
+
+// Process selects the mapping profile matching the workbook and extracts its transactions
+func (p *XLSXProcessor) Process(ctx context.Context, file io.Reader, filename string) (*ProcessingResult, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, &ProcessingError{
+			Stage:    StageExtraction,
+			Document: filename,
+			Err:      fmt.Errorf("failed to read XLSX: %w", err),
+		}
+	}
+
+	parser, info, err := p.detectParser(data)
+	if err != nil {
+		return nil, &ProcessingError{
+			Stage:    StageExtraction,
+			Document: filename,
+			Err:      err,
+		}
+	}
+
+	parsed, err := parser.ProcessDocument(ctx, bytes.NewReader(data))
+	if err != nil {
+		return nil, &ProcessingError{
+			Stage:    StageExtraction,
+			Document: filename,
+			Err:      err,
+		}
+	}
+
+	transactions := make([]Transaction, 0, len(parsed))
+	for _, tx := range parsed {
+		transactions = append(transactions, Transaction{
+			Date:        tx.Date,
+			Amount:      tx.Amount,
+			RawData:     tx.RawData,
+			Description: tx.Description,
+			Category:    tx.Category,
+			SubCategory: tx.SubCategory,
+			Source:      tx.Source,
+		})
+	}
+
+	p.logger.Info("extracted workbook transactions",
+		"filename", filename,
+		"profile", info.ID,
+		"transactions", len(transactions))
+
+	return &ProcessingResult{
+		Transactions: transactions,
+		Metadata: map[string]any{
+			"filename":     filename,
+			"content_type": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			"profile":      info.ID,
+		},
+		Warnings:    make([]string, 0),
+		ProcessedAt: time.Now(),
+	}, nil
+}
+
+// detectParser returns the first XLSX parser whose mapping matches the workbook
+func (p *XLSXProcessor) detectParser(data []byte) (processor.Parser, processor.ParserInfo, error) {
+	if p.parsers == nil {
+		return nil, processor.ParserInfo{}, fmt.Errorf("no parser registry configured")
+	}
+	for _, info := range p.parsers.List() {
+		if info.Format != processor.ProfileFormatXLSX {
+			continue
+		}
+		parser, _, err := p.parsers.Get(info.ID, p.logger)
+		if err != nil {
+			return nil, processor.ParserInfo{}, err
+		}
+		if parser.Detect(data) {
+			return parser, info, nil
+		}
+	}
+	return nil, processor.ParserInfo{}, fmt.Errorf("%w: no XLSX mapping profile matches the workbook", processor.ErrFormatNotDetected)
+}
+
+// CanProcess returns true if the file is an XLSX workbook
+func (p *XLSXProcessor) CanProcess(filePath string) bool {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	return ext == ".xlsx"
+}
@@ -0,0 +1,108 @@
+package docprocess
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/lindehoff/Budget-Assist/internal/processor"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+// createTestXLSX creates a workbook with a preamble row above the transaction table
+func createTestXLSX(t *testing.T) []byte {
+	t.Helper()
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	rows := [][]any{
+		{"Kontoutdrag"},
+		{"Datum", "Text", "Belopp"},
+		{"2025-02-24", "Kafé Åre", -249.5},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatalf("SetSheetRow() unexpected error: %v", err)
+		}
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("WriteToBuffer() unexpected error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestXLSXProcessor_Process(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	parsers := processor.NewDefaultRegistry()
+	if err := processor.RegisterProfile(parsers, processor.CSVProfile{
+		ID:     "xlsxbank",
+		Format: processor.ProfileFormatXLSX,
+		Columns: processor.CSVColumns{
+			Date:        "Datum",
+			Description: "Text",
+			Amount:      "Belopp",
+		},
+	}); err != nil {
+		t.Fatalf("RegisterProfile() unexpected error: %v", err)
+	}
+
+	factory := NewProcessorFactory(logger, &mockAIService{}, parsers)
+	docProcessor, err := factory.CreateProcessor(TypeXLSX)
+	if err != nil {
+		t.Fatalf("CreateProcessor() unexpected error: %v", err)
+	}
+	if docProcessor.Type() != TypeXLSX {
+		t.Errorf("Type() = %v, want %v", docProcessor.Type(), TypeXLSX)
+	}
+
+	data := createTestXLSX(t)
+	if err := docProcessor.Validate(bytes.NewReader(data)); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	result, err := docProcessor.Process(context.Background(), bytes.NewReader(data), "statement.xlsx")
+	if err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if len(result.Transactions) != 1 {
+		t.Fatalf("Process() transactions = %d, want 1", len(result.Transactions))
+	}
+	tx := result.Transactions[0]
+	if tx.Description != "Kafé Åre" {
+		t.Errorf("transaction.Description = %q, want %q", tx.Description, "Kafé Åre")
+	}
+	if !tx.Amount.Equal(decimal.RequireFromString("-249.5")) {
+		t.Errorf("transaction.Amount = %v, want -249.5", tx.Amount)
+	}
+	if result.Metadata["profile"] != "xlsxbank" {
+		t.Errorf("Metadata[profile] = %v, want xlsxbank", result.Metadata["profile"])
+	}
+}
+
+func TestXLSXProcessor_error_validation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	xlsxProcessor := NewXLSXProcessor(logger, processor.NewDefaultRegistry())
+
+	t.Run("Validate_error_invalid_content", func(t *testing.T) {
+		err := xlsxProcessor.Validate(bytes.NewReader([]byte("not a workbook")))
+		var procErr *ProcessingError
+		if !errors.As(err, &procErr) || procErr.Stage != StageValidation {
+			t.Errorf("Validate() error = %v, want validation ProcessingError", err)
+		}
+	})
+
+	t.Run("Process_error_no_matching_profile", func(t *testing.T) {
+		_, err := xlsxProcessor.Process(context.Background(), bytes.NewReader(createTestXLSX(t)), "statement.xlsx")
+		if !errors.Is(err, processor.ErrFormatNotDetected) {
+			t.Errorf("Process() error = %v, want %v", err, processor.ErrFormatNotDetected)
+		}
+	})
+}
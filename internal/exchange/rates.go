@@ -0,0 +1,284 @@
+// Package exchange reads the exchange rates central banks publish, so that
+// amounts in foreign currencies can be converted to the base currency.
+package exchange
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/lindehoff/Budget-Assist/internal/processor"
+	"github.com/shopspring/decimal"
+)
+
+// Publishers of exchange rate files. SourceAuto tells them apart by the file.
+const (
+	SourceAuto     = "auto"
+	SourceECB      = "ecb"
+	SourceRiksbank = "riksbank"
+)
+
+// dateLayouts are the date formats of the published files, e.g. "2026-09-30"
+// and "30 September 2026" in the ECB's daily reference rates
+var dateLayouts = []string{"2006-01-02", "2 January 2006", "02 January 2006"}
+
+// dateHeaders name the date column of a rate file
+var dateHeaders = map[string]bool{"date": true, "datum": true, "period": true, "time period": true, "time_period": true}
+
+// seriesHeaders and valueHeaders name the columns of Riksbank files with one
+// row per day and series
+var (
+	seriesHeaders = map[string]bool{"serie": true, "series": true, "serienamn": true, "series name": true}
+	valueHeaders  = map[string]bool{"värde": true, "value": true}
+)
+
+// missingValues mark days without a rate, such as bank holidays
+var missingValues = map[string]bool{"": true, "n/a": true, "na": true, "-": true, "–": true}
+
+var (
+	// riksbankSeries matches Riksbank series IDs such as "SEKEURPMI"
+	riksbankSeries = regexp.MustCompile(`^SEK([A-Z]{3})PMI$`)
+	// ecbSeries matches ECB series keys such as "EXR.D.USD.EUR.SP00.A"
+	ecbSeries = regexp.MustCompile(`EXR\.[A-Z]\.([A-Z]{3})\.EUR\.`)
+)
+
+// ParseRates reads the exchange rates of a CSV file published by the source.
+//
+// ECB files have a date column and a column per currency, priced in euro:
+// "Date,USD,SEK" followed by "2026-09-30,1.1650,11.0520" means one euro costs
+// 1.1650 US dollars and 11.0520 Swedish kronor. Columns may also be named by
+// their series key, e.g. "EXR.D.USD.EUR.SP00.A".
+//
+// Riksbank files are separated by semicolons and use decimal commas. Each
+// value is the price in Swedish kronor of the units named by its series,
+// "EUR", "100 JPY" or a series ID such as "SEKEURPMI". Series are columns, or
+// rows of a file with date, series and value columns.
+func ParseRates(reader io.Reader, source string) ([]db.ExchangeRate, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exchange rates: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		data = latin1ToUTF8(data)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(source)) {
+	case "", SourceAuto:
+		source = DetectSource(data)
+	case SourceECB:
+		source = SourceECB
+	case SourceRiksbank:
+		source = SourceRiksbank
+	default:
+		return nil, fmt.Errorf("unsupported exchange rate source: %s", source)
+	}
+
+	parser := rateParser{source: source, quote: db.CurrencyEUR, format: processor.NumberFormat{DecimalSeparator: "."}}
+	csvReader := csv.NewReader(bytes.NewReader(data))
+	if source == SourceRiksbank {
+		parser.quote, parser.format = db.CurrencySEK, processor.NumberFormat{DecimalSeparator: ","}
+		csvReader.Comma = ';'
+	}
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	csvReader.TrimLeadingSpace = true
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s exchange rates: %w", source, err)
+	}
+	return parser.parse(records)
+}
+
+// DetectSource tells ECB and Riksbank files apart by the separator of their
+// first line with separators, skipping title lines
+func DetectSource(data []byte) string {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		semicolons, commas := bytes.Count(line, []byte(";")), bytes.Count(line, []byte(","))
+		switch {
+		case semicolons > commas:
+			return SourceRiksbank
+		case commas > 0:
+			return SourceECB
+		}
+	}
+	return SourceECB
+}
+
+// rateParser reads the records of one rate file
+type rateParser struct {
+	source string
+	quote  string // Currency the file prices in: EUR for the ECB, SEK for the Riksbank
+	format processor.NumberFormat
+}
+
+// parse finds the header row, skipping any title lines before it, and reads the rates below it
+func (p rateParser) parse(records [][]string) ([]db.ExchangeRate, error) {
+	for i, record := range records {
+		if len(record) < 2 || !dateHeaders[strings.ToLower(strings.TrimSpace(record[0]))] {
+			continue
+		}
+		series, value := -1, -1
+		for j, cell := range record {
+			switch name := strings.ToLower(strings.TrimSpace(cell)); {
+			case seriesHeaders[name]:
+				series = j
+			case valueHeaders[name]:
+				value = j
+			}
+		}
+		if p.source == SourceRiksbank && series > 0 && value > 0 {
+			return p.parseRows(records[i+1:], i+2, series, value)
+		}
+		return p.parseColumns(record, records[i+1:], i+2)
+	}
+	return nil, fmt.Errorf("no %s exchange rates found: missing a header row starting with Date", p.source)
+}
+
+// parseColumns reads files with a column per currency
+func (p rateParser) parseColumns(header []string, records [][]string, line int) ([]db.ExchangeRate, error) {
+	type column struct {
+		index    int
+		currency string
+		units    decimal.Decimal
+	}
+	var columns []column
+	for j, cell := range header {
+		if j == 0 {
+			continue
+		}
+		if currency, units, ok := p.series(cell); ok {
+			columns = append(columns, column{j, currency, units})
+		}
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no currencies found in the %s exchange rate header", p.source)
+	}
+
+	var rates []db.ExchangeRate
+	for i, record := range records {
+		if isBlank(record) {
+			continue
+		}
+		date, err := p.date(record[0], line+i)
+		if err != nil {
+			return nil, err
+		}
+		for _, col := range columns {
+			if col.index >= len(record) {
+				continue
+			}
+			rate, ok, err := p.rate(date, col.currency, col.units, record[col.index], line+i)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				rates = append(rates, rate)
+			}
+		}
+	}
+	return rates, nil
+}
+
+// parseRows reads Riksbank files with a row per day and series
+func (p rateParser) parseRows(records [][]string, line, series, value int) ([]db.ExchangeRate, error) {
+	var rates []db.ExchangeRate
+	for i, record := range records {
+		if isBlank(record) || series >= len(record) || value >= len(record) {
+			continue
+		}
+		currency, units, ok := p.series(record[series])
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown exchange rate series '%s'", line+i, record[series])
+		}
+		date, err := p.date(record[0], line+i)
+		if err != nil {
+			return nil, err
+		}
+		rate, ok, err := p.rate(date, currency, units, record[value], line+i)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rates = append(rates, rate)
+		}
+	}
+	return rates, nil
+}
+
+// series returns the currency a column or series name prices and the number
+// of units the value is for, e.g. "JPY" and 100 for "100 JPY"
+func (p rateParser) series(name string) (string, decimal.Decimal, bool) {
+	name = strings.TrimSpace(name)
+	units := decimal.NewFromInt(1)
+	if count, rest, found := strings.Cut(name, " "); found {
+		if n, err := strconv.Atoi(count); err == nil && n > 0 {
+			units, name = decimal.NewFromInt(int64(n)), strings.TrimSpace(rest)
+		}
+	}
+	code := strings.ToUpper(name)
+	if match := riksbankSeries.FindStringSubmatch(code); match != nil && p.source == SourceRiksbank {
+		code = match[1]
+	}
+	if match := ecbSeries.FindStringSubmatch(code); match != nil && p.source == SourceECB {
+		code = match[1]
+	}
+	if !db.IsCurrencyCode(code) || code == p.quote {
+		return "", decimal.Decimal{}, false
+	}
+	return code, units, true
+}
+
+// date parses the date of a row as a day in UTC
+func (p rateParser) date(value string, line int) (time.Time, error) {
+	date, err := processor.ParseDate(value, dateLayouts...)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("line %d: %w", line, err)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// rate returns the rate of one value, or false for days without a rate
+func (p rateParser) rate(date time.Time, currency string, units decimal.Decimal, value string, line int) (db.ExchangeRate, bool, error) {
+	value = strings.TrimSpace(value)
+	if missingValues[strings.ToLower(value)] {
+		return db.ExchangeRate{}, false, nil
+	}
+	amount, err := processor.ParseAmount(value, p.format)
+	if err != nil || !amount.IsPositive() {
+		return db.ExchangeRate{}, false, fmt.Errorf("line %d: invalid %s rate '%s'", line, currency, value)
+	}
+	if p.source == SourceECB {
+		// One euro costs amount of the currency
+		return db.ExchangeRate{Date: date, Currency: p.quote, QuoteCurrency: currency, Rate: amount.Div(units), Source: p.source}, true, nil
+	}
+	// The units of the currency cost amount kronor
+	return db.ExchangeRate{Date: date, Currency: currency, QuoteCurrency: p.quote, Rate: amount.Div(units), Source: p.source}, true, nil
+}
+
+// isBlank reports whether a record has no values, as trailing lines do
+func isBlank(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// latin1ToUTF8 converts ISO-8859-1 data, as older Riksbank exports are
+// encoded, to UTF-8
+func latin1ToUTF8(data []byte) []byte {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return []byte(string(runes))
+}
@@ -0,0 +1,130 @@
+package exchange
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+)
+
+func TestParseRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		input   string
+		want    []string // "date currency/quote rate"
+		wantErr bool
+	}{
+		{
+			name:   "Successfully_parse_ECB_history",
+			source: SourceAuto,
+			input: "Date,USD,JPY,SEK,\n" +
+				"2026-09-30,1.1650,172.50,11.0520,\n" +
+				"2026-09-29,1.1600,N/A,11.0400,\n",
+			want: []string{
+				"2026-09-30 EUR/USD 1.165",
+				"2026-09-30 EUR/JPY 172.5",
+				"2026-09-30 EUR/SEK 11.052",
+				"2026-09-29 EUR/USD 1.16",
+				"2026-09-29 EUR/SEK 11.04",
+			},
+		},
+		{
+			name:   "Successfully_parse_ECB_daily_rates",
+			source: SourceECB,
+			input:  "Date, USD, SEK, \n30 September 2026, 1.1650, 11.0520, \n",
+			want:   []string{"2026-09-30 EUR/USD 1.165", "2026-09-30 EUR/SEK 11.052"},
+		},
+		{
+			name:   "Successfully_parse_ECB_series",
+			source: SourceECB,
+			input:  "\"DATE\",\"TIME PERIOD\",\"US dollar/Euro (EXR.D.USD.EUR.SP00.A)\"\n\"2026-09-30\",\"2026-09-30\",\"1.1650\"\n",
+			want:   []string{"2026-09-30 EUR/USD 1.165"},
+		},
+		{
+			name:   "Successfully_parse_Riksbank_columns",
+			source: SourceAuto,
+			input: "Valutakurser mot svenska kronor\n\n" +
+				"Period;SEKEURPMI;100 JPY;USD\n" +
+				"2026-09-30;11,0520;6,4100;9,4870\n" +
+				"2026-09-29;11,0400;;9,5100\n",
+			want: []string{
+				"2026-09-30 EUR/SEK 11.052",
+				"2026-09-30 JPY/SEK 0.0641",
+				"2026-09-30 USD/SEK 9.487",
+				"2026-09-29 EUR/SEK 11.04",
+				"2026-09-29 USD/SEK 9.51",
+			},
+		},
+		{
+			name:   "Successfully_parse_Riksbank_rows",
+			source: SourceRiksbank,
+			input:  "Datum;Serie;Värde\n2026-09-30;SEKEURPMI;11,0520\n2026-09-30;1 USD;9,4870\n",
+			want:   []string{"2026-09-30 EUR/SEK 11.052", "2026-09-30 USD/SEK 9.487"},
+		},
+		{
+			name:   "Successfully_parse_Latin1_Riksbank_rows",
+			source: SourceRiksbank,
+			input:  "Datum;Serie;V\xe4rde\n2026-09-30;SEKEURPMI;11,0520\n",
+			want:   []string{"2026-09-30 EUR/SEK 11.052"},
+		},
+		{
+			name:    "Error_unknown_series",
+			source:  SourceRiksbank,
+			input:   "Datum;Serie;Värde\n2026-09-30;SEKXYZPMI;1,00\n",
+			wantErr: true,
+		},
+		{
+			name:    "Error_invalid_rate",
+			source:  SourceECB,
+			input:   "Date,USD\n2026-09-30,-1.2\n",
+			wantErr: true,
+		},
+		{
+			name:    "Error_no_header",
+			source:  SourceECB,
+			input:   "2026-09-30,1.1650\n",
+			wantErr: true,
+		},
+		{
+			name:    "Error_unsupported_source",
+			source:  "fed",
+			input:   "Date,USD\n2026-09-30,1.1650\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Execute
+			rates, err := ParseRates(strings.NewReader(tt.input), tt.source)
+
+			// Verify
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var got []string
+			for _, rate := range rates {
+				got = append(got, rate.Date.Format("2006-01-02")+" "+rate.Currency+"/"+rate.QuoteCurrency+" "+rate.Rate.String())
+				if rate.Date.Location().String() != "UTC" {
+					t.Errorf("rate date %s is not in UTC", rate.Date)
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("ParseRates() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestParseRates_Successfully_set_source(t *testing.T) {
+	rates, err := ParseRates(strings.NewReader("Period;EUR\n2026-09-30;11,05\n"), "")
+	if err != nil {
+		t.Fatalf("ParseRates() unexpected error: %v", err)
+	}
+	if len(rates) != 1 || rates[0].Source != SourceRiksbank || rates[0].QuoteCurrency != db.CurrencySEK {
+		t.Errorf("ParseRates() = %+v, want a Riksbank rate in SEK", rates)
+	}
+}
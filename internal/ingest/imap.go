@@ -0,0 +1,231 @@
+// Package ingest provides the sources documents are ingested from besides the
+// local file system, such as email folders.
+package ingest
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+)
+
+const (
+	// DefaultIMAPFolder is the folder read when none is configured
+	DefaultIMAPFolder = "INBOX"
+	// DefaultProcessedFlag is the keyword processed messages are marked with
+	DefaultProcessedFlag = "$BudgetAssistProcessed"
+
+	dialTimeout = 30 * time.Second
+)
+
+// IMAPConfig holds the settings for reading messages from an IMAP folder
+type IMAPConfig struct {
+	Address       string // host:port of the server
+	Username      string
+	Password      string
+	Folder        string    // Folder to read, DefaultIMAPFolder when empty
+	TLS           bool      // Connect with TLS, otherwise STARTTLS is used when the server offers it
+	ProcessedFlag string    // Keyword processed messages are marked with, DefaultProcessedFlag when empty
+	Since         time.Time // Ignore messages received before this date when set
+}
+
+// IMAPMailbox reads messages from a folder on an IMAP server. Messages are
+// identified by their UID validity and UID, so that identifiers from before the
+// server renumbered the folder are never mistaken for other messages.
+type IMAPMailbox struct {
+	client      *client.Client
+	config      IMAPConfig
+	uidValidity uint32
+}
+
+// DialIMAP connects and logs in to the IMAP server and selects the configured folder
+func DialIMAP(config IMAPConfig, logger *slog.Logger) (*IMAPMailbox, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("no IMAP server address configured")
+	}
+	if config.Folder == "" {
+		config.Folder = DefaultIMAPFolder
+	}
+	if config.ProcessedFlag == "" {
+		config.ProcessedFlag = DefaultProcessedFlag
+	}
+
+	host, _, err := net.SplitHostPort(config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IMAP server address %q: %w", config.Address, err)
+	}
+	tlsConfig := &tls.Config{ServerName: host}
+	dialer := &net.Dialer{Timeout: dialTimeout}
+
+	var c *client.Client
+	if config.TLS {
+		c, err = client.DialWithDialerTLS(dialer, config.Address, tlsConfig)
+	} else {
+		c, err = client.DialWithDialer(dialer, config.Address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", config.Address, err)
+	}
+
+	if !config.TLS {
+		supported, err := c.SupportStartTLS()
+		if err != nil {
+			c.Terminate()
+			return nil, fmt.Errorf("failed to query server capabilities: %w", err)
+		}
+		if supported {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				c.Terminate()
+				return nil, fmt.Errorf("failed to start TLS: %w", err)
+			}
+		} else {
+			logger.Warn("IMAP server does not support TLS, credentials are sent unencrypted", "address", config.Address)
+		}
+	}
+
+	if err := c.Login(config.Username, config.Password); err != nil {
+		c.Terminate()
+		return nil, fmt.Errorf("failed to log in as %s: %w", config.Username, err)
+	}
+	status, err := c.Select(config.Folder, false)
+	if err != nil {
+		c.Logout()
+		return nil, fmt.Errorf("failed to select folder %s: %w", config.Folder, err)
+	}
+	logger.Debug("selected IMAP folder", "folder", config.Folder, "messages", status.Messages)
+
+	return &IMAPMailbox{
+		client:      c,
+		config:      config,
+		uidValidity: status.UidValidity,
+	}, nil
+}
+
+// Unprocessed returns the identifiers of the messages without the processed flag
+func (m *IMAPMailbox) Unprocessed(ctx context.Context) ([]string, error) {
+	criteria := imap.NewSearchCriteria()
+	criteria.WithoutFlags = []string{m.config.ProcessedFlag, imap.DeletedFlag}
+	if !m.config.Since.IsZero() {
+		criteria.Since = m.config.Since
+	}
+
+	var uids []uint32
+	err := m.withContext(ctx, func() error {
+		var err error
+		uids, err = m.client.UidSearch(criteria)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search folder %s: %w", m.config.Folder, err)
+	}
+
+	ids := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		ids = append(ids, fmt.Sprintf("%d-%d", m.uidValidity, uid))
+	}
+	return ids, nil
+}
+
+// Fetch downloads a message without marking it as seen
+func (m *IMAPMailbox) Fetch(ctx context.Context, id string) ([]byte, error) {
+	uid, err := m.parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	seqset := new(imap.SeqSet)
+	seqset.AddNum(uid)
+	section := &imap.BodySectionName{Peek: true}
+	messages := make(chan *imap.Message, 1)
+
+	var data []byte
+	err = m.withContext(ctx, func() error {
+		done := make(chan error, 1)
+		go func() {
+			done <- m.client.UidFetch(seqset, []imap.FetchItem{section.FetchItem()}, messages)
+		}()
+		for msg := range messages {
+			body := msg.GetBody(section)
+			if body == nil {
+				continue
+			}
+			var err error
+			if data, err = io.ReadAll(body); err != nil {
+				return err
+			}
+		}
+		return <-done
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch message %s: %w", id, err)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("message %s not found in folder %s", id, m.config.Folder)
+	}
+	return data, nil
+}
+
+// MarkProcessed adds the processed flag to a message
+func (m *IMAPMailbox) MarkProcessed(ctx context.Context, id string) error {
+	uid, err := m.parseID(id)
+	if err != nil {
+		return err
+	}
+
+	seqset := new(imap.SeqSet)
+	seqset.AddNum(uid)
+	item := imap.FormatFlagsOp(imap.AddFlags, true)
+	err = m.withContext(ctx, func() error {
+		return m.client.UidStore(seqset, item, []interface{}{m.config.ProcessedFlag}, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to flag message %s: %w", id, err)
+	}
+	return nil
+}
+
+// Close logs out from the server
+func (m *IMAPMailbox) Close() error {
+	return m.client.Logout()
+}
+
+// parseID returns the UID of a message identifier, rejecting identifiers from
+// before the folder was renumbered
+func (m *IMAPMailbox) parseID(id string) (uint32, error) {
+	validity, uid, ok := strings.Cut(id, "-")
+	if !ok {
+		return 0, fmt.Errorf("invalid message id %q", id)
+	}
+	if validity != strconv.FormatUint(uint64(m.uidValidity), 10) {
+		return 0, fmt.Errorf("message id %q is from before folder %s was renumbered", id, m.config.Folder)
+	}
+	value, err := strconv.ParseUint(uid, 10, 32)
+	if err != nil || value == 0 {
+		return 0, fmt.Errorf("invalid message id %q", id)
+	}
+	return uint32(value), nil
+}
+
+// withContext runs a command, dropping the connection when ctx is cancelled
+// since the IMAP client cannot abort a command otherwise
+func (m *IMAPMailbox) withContext(ctx context.Context, command func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() {
+		m.client.Terminate()
+	})
+	err := command()
+	if !stop() && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
@@ -0,0 +1,193 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap/backend/memory"
+	"github.com/emersion/go-imap/server"
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/lindehoff/Budget-Assist/internal/docprocess"
+	"github.com/lindehoff/Budget-Assist/internal/pipeline"
+	"github.com/lindehoff/Budget-Assist/internal/processor"
+)
+
+// startTestServer starts an IMAP server on a local port that holds the given
+// messages in its INBOX besides the one message the memory backend starts with
+func startTestServer(t *testing.T, messages map[time.Time]string) (string, *memory.Mailbox) {
+	t.Helper()
+	be := memory.New()
+	user, err := be.Login(nil, "username", "password")
+	if err != nil {
+		t.Fatalf("Failed to log in to backend: %v", err)
+	}
+	mbox, err := user.GetMailbox(DefaultIMAPFolder)
+	if err != nil {
+		t.Fatalf("Failed to get INBOX: %v", err)
+	}
+	dates := make([]time.Time, 0, len(messages))
+	for date := range messages {
+		dates = append(dates, date)
+	}
+	slices.SortFunc(dates, func(a, b time.Time) int { return a.Compare(b) })
+	for _, date := range dates {
+		if err := mbox.CreateMessage(nil, date, bytes.NewBufferString(messages[date])); err != nil {
+			t.Fatalf("Failed to add message: %v", err)
+		}
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	srv := server.New(be)
+	srv.AllowInsecureAuth = true
+	go srv.Serve(listener)
+	t.Cleanup(func() { srv.Close() })
+
+	return listener.Addr().String(), mbox.(*memory.Mailbox)
+}
+
+// createTestEmail creates an email with a base64 encoded attachment
+func createTestEmail(subject, from, filename, content string) string {
+	return "From: " + from + "\r\nSubject: " + subject + "\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"b1\"\r\n\r\n" +
+		"--b1\r\nContent-Type: text/plain\r\n\r\nSe bifogad fil.\r\n" +
+		"--b1\r\nContent-Type: text/csv\r\nContent-Disposition: attachment; filename=\"" + filename + "\"\r\n" +
+		"Content-Transfer-Encoding: base64\r\n\r\n" + base64.StdEncoding.EncodeToString([]byte(content)) + "\r\n--b1--\r\n"
+}
+
+func TestIngestMailbox_Successfully_process_imap_attachments(t *testing.T) {
+	// Setup
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := db.NewMockStore()
+	p := pipeline.NewPipeline(&docprocess.PDFProcessor{}, processor.NewDefaultRegistry(), nil, store, logger)
+
+	header := "Bokföringsdatum;Valutadatum;Verifikationsnummer;Text;Belopp;Saldo\n"
+	now := time.Now()
+	addr, mbox := startTestServer(t, map[time.Time]string{
+		now.Add(-time.Hour): createTestEmail("Kontoutdrag februari", "SEB <info@seb.se>", "februari.csv",
+			header+"2025-02-25;2025-02-25;5490990005;Lön;25000.000;27814.160\n"),
+		now.Add(-30 * time.Minute): createTestEmail("Trasig fil", "SEB <info@seb.se>", "trasig.csv",
+			"not;a;known;format\n1;2;3;4\n"),
+		now.AddDate(0, -2, 0): createTestEmail("Gammalt utdrag", "SEB <info@seb.se>", "december.csv",
+			header+"2024-12-24;2024-12-24;5490990001;ICA Kvantum;-1000.000;2814.160\n"),
+	})
+
+	config := IMAPConfig{
+		Address:  addr,
+		Username: "username",
+		Password: "password",
+		Since:    now.AddDate(0, 0, -7),
+	}
+	opts := pipeline.MailOptions{ArchiveDir: t.TempDir()}
+	ingest := func() []pipeline.MailResult {
+		t.Helper()
+		mailbox, err := DialIMAP(config, logger)
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		defer mailbox.Close()
+		results, err := p.IngestMailbox(context.Background(), mailbox, opts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return results
+	}
+
+	// Execute
+	results := ingest()
+
+	// Verify
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 messages newer than a week, got %d", len(results))
+	}
+	bySubject := make(map[string]pipeline.MailResult)
+	for _, result := range results {
+		bySubject[result.Subject] = result
+	}
+	tests := []struct {
+		name        string
+		subject     string
+		wantError   bool
+		wantResults int
+	}{
+		{name: "Successfully_store_attachment", subject: "Kontoutdrag februari", wantResults: 1},
+		{name: "Successfully_mark_message_without_attachments", subject: "A little message, just for you"},
+		{name: "Error_keep_failed_message_unmarked", subject: "Trasig fil", wantError: true, wantResults: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := bySubject[tt.subject]
+			if !ok {
+				t.Fatalf("Expected a result for %q", tt.subject)
+			}
+			if (result.Error != nil) != tt.wantError {
+				t.Errorf("Expected error %v, got %v", tt.wantError, result.Error)
+			}
+			if len(result.Results) != tt.wantResults {
+				t.Errorf("Expected %d attachment results, got %d", tt.wantResults, len(result.Results))
+			}
+		})
+	}
+
+	for _, msg := range mbox.Messages {
+		// Servers may change the case of keywords, which are case-insensitive
+		flagged := slices.ContainsFunc(msg.Flags, func(flag string) bool { return strings.EqualFold(flag, DefaultProcessedFlag) })
+		failed := bytes.Contains(msg.Body, []byte("Trasig fil"))
+		old := msg.Date.Before(config.Since)
+		if flagged == (failed || old) {
+			t.Errorf("Message %d: expected processed flag %v, got flags %v", msg.Uid, !(failed || old), msg.Flags)
+		}
+	}
+
+	stored, _ := store.ListTransactions(context.Background(), nil)
+	if len(stored) != 1 {
+		t.Fatalf("Expected 1 stored transaction, got %d", len(stored))
+	}
+	if tx := stored[0]; tx.MessageSubject != "Kontoutdrag februari" || tx.MessageSender != "SEB <info@seb.se>" ||
+		tx.SourceContainer != bySubject["Kontoutdrag februari"].Path || tx.SourceEntry != "februari.csv" {
+		t.Errorf("Expected email provenance, got %q %q %q %q", tx.MessageSubject, tx.MessageSender, tx.SourceContainer, tx.SourceEntry)
+	}
+
+	// Only the failed message is retried on the next run
+	again := ingest()
+	if len(again) != 1 || again[0].Subject != "Trasig fil" || again[0].Error == nil {
+		t.Errorf("Expected only the failed message to be retried, got %+v", again)
+	}
+}
+
+func TestIMAPMailbox_Error_invalid_message_id(t *testing.T) {
+	addr, _ := startTestServer(t, nil)
+	mailbox, err := DialIMAP(IMAPConfig{Address: addr, Username: "username", Password: "password"},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer mailbox.Close()
+
+	for _, id := range []string{"6", "0-6", "1-abc"} {
+		if _, err := mailbox.Fetch(context.Background(), id); err == nil {
+			t.Errorf("Expected an error fetching message %q", id)
+		}
+	}
+}
+
+func TestDialIMAP_Error_wrong_password(t *testing.T) {
+	addr, _ := startTestServer(t, nil)
+
+	_, err := DialIMAP(IMAPConfig{Address: addr, Username: "username", Password: "wrong"},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err == nil {
+		t.Fatal("Expected an error logging in with a wrong password")
+	}
+}
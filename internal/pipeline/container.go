@@ -0,0 +1,132 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lindehoff/Budget-Assist/internal/core"
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/lindehoff/Budget-Assist/internal/docprocess"
+)
+
+// documentSource is the file a document is read from. A document in a ZIP
+// archive or email is extracted to a temporary file, while its import batch and
+// transactions record the container and entry it came from.
+type documentSource struct {
+	path      string // File the document is read from
+	location  string // Path recorded for the document, the container path joined with the entry
+	container string
+	document  *docprocess.ContainerDocument
+	tempDir   string
+}
+
+// fileSource returns the source of a document on disk
+func fileSource(path string) *documentSource {
+	return &documentSource{path: path, location: path}
+}
+
+// containerJobFiles returns a job file for each supported document in a
+// container. A container that cannot be read or holds no supported documents
+// gets a single job file without entry, which fails with the reason.
+func containerJobFiles(path string) []db.JobFile {
+	docs, err := docprocess.ReadContainer(path)
+	if err != nil {
+		return []db.JobFile{{Path: path}}
+	}
+	var files []db.JobFile
+	for _, doc := range docs {
+		if isSupportedDocument(doc.Name) && !docprocess.IsContainer(doc.Name) {
+			files = append(files, db.JobFile{Path: path, Entry: doc.Entry})
+		}
+	}
+	if len(files) == 0 {
+		return []db.JobFile{{Path: path}}
+	}
+	return files
+}
+
+// openJobFile returns the source of a job document, extracting it from its container
+func openJobFile(file *db.JobFile) (*documentSource, error) {
+	if !docprocess.IsContainer(file.Path) {
+		return fileSource(file.Path), nil
+	}
+
+	docs, err := docprocess.ReadContainer(file.Path)
+	if err != nil {
+		return nil, err
+	}
+	if file.Entry == "" {
+		return nil, fmt.Errorf("no supported documents in %s", filepath.Base(file.Path))
+	}
+	var doc *docprocess.ContainerDocument
+	for i := range docs {
+		if docs[i].Entry == file.Entry {
+			doc = &docs[i]
+			break
+		}
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("document %s not found in %s", file.Entry, filepath.Base(file.Path))
+	}
+
+	// The temporary file keeps the document name, as its extension selects the processor
+	tempDir, err := os.MkdirTemp("", "budgetassist-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	path := filepath.Join(tempDir, doc.Name)
+	if err := os.WriteFile(path, doc.Data, 0600); err != nil {
+		os.RemoveAll(tempDir)
+		return nil, fmt.Errorf("failed to extract %s: %w", file.Entry, err)
+	}
+	return &documentSource{
+		path:      path,
+		location:  jobFileLocation(file),
+		container: file.Path,
+		document:  doc,
+		tempDir:   tempDir,
+	}, nil
+}
+
+// Close removes the temporary file of an extracted document
+func (s *documentSource) Close() {
+	if s.tempDir != "" {
+		os.RemoveAll(s.tempDir)
+	}
+}
+
+// importBatch describes the import of the document by the given parser
+func (s *documentSource) importBatch(parser string) (*db.ImportBatch, error) {
+	batch, err := core.NewImportBatch(s.path, parser)
+	if err != nil {
+		return nil, err
+	}
+	if s.document != nil {
+		batch.FileName = s.document.Name
+		batch.FilePath = s.location
+	}
+	return batch, nil
+}
+
+// annotate records on the transactions the container and email the document came from
+func (s *documentSource) annotate(doc *document) {
+	if s.document == nil {
+		return
+	}
+	for i := range doc.Transactions {
+		tx := &doc.Transactions[i]
+		tx.SourceContainer = s.container
+		tx.SourceEntry = s.document.Entry
+		tx.MessageSubject = s.document.Subject
+		tx.MessageSender = s.document.Sender
+	}
+}
+
+// jobFileLocation returns the path recorded for a job document
+func jobFileLocation(file *db.JobFile) string {
+	if file.Entry == "" {
+		return file.Path
+	}
+	return filepath.Join(file.Path, filepath.FromSlash(file.Entry))
+}
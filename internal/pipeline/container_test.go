@@ -0,0 +1,112 @@
+package pipeline
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/lindehoff/Budget-Assist/internal/docprocess"
+	"github.com/lindehoff/Budget-Assist/internal/processor"
+)
+
+func TestProcessDocuments_Successfully_process_container_documents(t *testing.T) {
+	// Setup
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := db.NewMockStore()
+	pipeline := NewPipeline(&docprocess.PDFProcessor{}, processor.NewDefaultRegistry(), nil, store, logger)
+
+	header := "Bokföringsdatum;Valutadatum;Verifikationsnummer;Text;Belopp;Saldo\n"
+	januari := header + "2025-01-24;2025-01-24;5490990001;ICA Kvantum;-1000.000;2814.160\n"
+	februari := header + "2025-02-25;2025-02-25;5490990005;Lön;25000.000;27814.160\n"
+	email := "From: SEB <info@seb.se>\r\nSubject: Kontoutdrag februari\r\nMIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"b1\"\r\n\r\n" +
+		"--b1\r\nContent-Type: text/plain\r\n\r\nSe bifogad fil.\r\n" +
+		"--b1\r\nContent-Type: text/csv\r\nContent-Disposition: attachment; filename=\"februari.csv\"\r\n" +
+		"Content-Transfer-Encoding: base64\r\n\r\n" + base64.StdEncoding.EncodeToString([]byte(februari)) + "\r\n--b1--\r\n"
+
+	path := filepath.Join(t.TempDir(), "utdrag.zip")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	archive := zip.NewWriter(file)
+	for name, content := range map[string]string{"januari.csv": januari, "mail/februari.eml": email, "readme.txt": "Kontoutdrag"} {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to close archive: %v", err)
+	}
+	file.Close()
+
+	// Execute
+	results, err := pipeline.ProcessDocuments(context.Background(), path, ProcessOptions{})
+
+	// Verify
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if result.Error != nil || result.Stored != 1 || result.Container != path {
+			t.Errorf("Expected %s to store 1 transaction from %s, got %d (%v)", result.FilePath, path, result.Stored, result.Error)
+		}
+	}
+
+	stored, _ := store.ListTransactions(context.Background(), nil)
+	provenance := make(map[string]db.Transaction)
+	for _, tx := range stored {
+		provenance[tx.Description] = tx
+	}
+	if tx := provenance["ICA Kvantum"]; tx.SourceContainer != path || tx.SourceEntry != "januari.csv" || tx.MessageSubject != "" {
+		t.Errorf("Expected archive provenance, got %q %q %q", tx.SourceContainer, tx.SourceEntry, tx.MessageSubject)
+	}
+	if tx := provenance["Lön"]; tx.SourceEntry != "mail/februari.eml/februari.csv" ||
+		tx.MessageSubject != "Kontoutdrag februari" || tx.MessageSender != "SEB <info@seb.se>" {
+		t.Errorf("Expected email provenance, got %q %q %q", tx.SourceEntry, tx.MessageSubject, tx.MessageSender)
+	}
+
+	batches, _ := store.ListImportBatches(context.Background())
+	for _, batch := range batches {
+		if !strings.HasPrefix(batch.FilePath, path+string(filepath.Separator)) {
+			t.Errorf("Expected batch path within the archive, got %s", batch.FilePath)
+		}
+	}
+
+	// Processing the archive again skips its documents
+	again, err := pipeline.ProcessDocuments(context.Background(), path, ProcessOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, result := range again {
+		if !result.Skipped {
+			t.Errorf("Expected %s to be skipped", result.FilePath)
+		}
+	}
+}
+
+func TestProcessDocuments_Error_container_without_documents(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pipeline := NewPipeline(&docprocess.PDFProcessor{}, processor.NewDefaultRegistry(), nil, db.NewMockStore(), logger)
+	path := createTempFile(t, ".eml", []byte("From: SEB <info@seb.se>\r\nSubject: Hej\r\n\r\nInga bilagor.\r\n"))
+
+	_, err := pipeline.ProcessDocuments(context.Background(), path, ProcessOptions{})
+
+	if err == nil {
+		t.Fatal("Expected an error for an email without documents")
+	}
+}
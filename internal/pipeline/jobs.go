@@ -0,0 +1,341 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/core"
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/lindehoff/Budget-Assist/internal/docprocess"
+)
+
+// CreateJob records a processing job for the documents in path, in walk order
+func (p *Pipeline) CreateJob(ctx context.Context, path string, opts ProcessOptions) (*db.ProcessingJob, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access path: %w", err)
+	}
+
+	paths := []string{path}
+	if fileInfo.IsDir() {
+		paths = nil
+		if err := filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				paths = append(paths, path)
+			}
+			return ctx.Err()
+		}); err != nil {
+			return nil, fmt.Errorf("failed to walk directory: %w", err)
+		}
+	}
+
+	// The documents of ZIP archives and emails are processed one by one
+	var files []db.JobFile
+	for _, path := range paths {
+		if docprocess.IsContainer(path) {
+			files = append(files, containerJobFiles(path)...)
+		} else {
+			files = append(files, db.JobFile{Path: path})
+		}
+	}
+
+	options, err := json.Marshal(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal processing options: %w", err)
+	}
+
+	job := &db.ProcessingJob{
+		Path:    path,
+		Options: string(options),
+		Status:  db.JobRunning,
+	}
+	for i, file := range files {
+		file.Position = i
+		file.Status = db.JobFilePending
+		job.Files = append(job.Files, file)
+	}
+	if err := p.store.CreateProcessingJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create processing job: %w", err)
+	}
+	return job, nil
+}
+
+// LoadJob returns a job that can be resumed together with the options it was started with
+func (p *Pipeline) LoadJob(ctx context.Context, id uint) (*db.ProcessingJob, ProcessOptions, error) {
+	job, err := p.store.GetProcessingJobByID(ctx, id)
+	if err != nil {
+		return nil, ProcessOptions{}, fmt.Errorf("failed to load processing job %d: %w", id, err)
+	}
+	if job.Status == db.JobCompleted {
+		return nil, ProcessOptions{}, fmt.Errorf("processing job %d is already completed", id)
+	}
+
+	var opts ProcessOptions
+	if job.Options != "" {
+		if err := json.Unmarshal([]byte(job.Options), &opts); err != nil {
+			return nil, ProcessOptions{}, fmt.Errorf("failed to read options of processing job %d: %w", id, err)
+		}
+	}
+	if opts.MapCategories {
+		if opts.Categories, err = core.NewCategoryMapper(ctx, p.store, opts.CategoryMap); err != nil {
+			return nil, ProcessOptions{}, fmt.Errorf("failed to load categories for mapping: %w", err)
+		}
+	}
+	return job, opts, nil
+}
+
+// RunJob processes the documents of a job, continuing each one from the stage an
+// earlier run reached. Documents are processed by opts.Workers workers and the
+// results are returned in walk order. When ctx is cancelled no further documents
+// are started, the job is marked interrupted and the results so far are returned
+// together with the context error; documents that were not processed carry the
+// context error as well.
+func (p *Pipeline) RunJob(ctx context.Context, job *db.ProcessingJob, opts ProcessOptions) ([]ProcessingResult, error) {
+	history, err := p.loadDocumentHistory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load processed documents: %w", err)
+	}
+
+	job.Status = db.JobRunning
+	if err := p.store.UpdateProcessingJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to start processing job: %w", err)
+	}
+
+	results := make([]ProcessingResult, len(job.Files))
+	started := make([]bool, len(job.Files))
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(job.Files) {
+		workers = len(job.Files)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				started[i] = true
+				results[i] = p.processJobFile(ctx, &job.Files[i], opts, history)
+			}
+		}()
+	}
+
+dispatch:
+	for i := range job.Files {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	job.Status = db.JobCompleted
+	for i := range job.Files {
+		if job.Files[i].Status == db.JobFileFailed {
+			job.Status = db.JobFailed
+		}
+	}
+	if ctx.Err() != nil {
+		job.Status = db.JobInterrupted
+		for i := range results {
+			if !started[i] {
+				results[i] = ProcessingResult{FilePath: jobFileLocation(&job.Files[i]), Error: ctx.Err()}
+			}
+		}
+	}
+	if job.Status == db.JobCompleted {
+		completed := time.Now()
+		job.CompletedAt = &completed
+	}
+
+	// The job state is saved even when the run was cancelled, so that it can be resumed
+	if err := p.store.UpdateProcessingJob(context.WithoutCancel(ctx), job); err != nil {
+		p.logger.Error("failed to update processing job", "job", job.ID, "error", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return results, fmt.Errorf("processing cancelled: %w", err)
+	}
+	return results, nil
+}
+
+// processJobFile takes a document of a job through extraction, AI analysis and
+// storage, saving its stage after each step. Unchanged documents are skipped
+// unless opts.Force is set.
+func (p *Pipeline) processJobFile(ctx context.Context, file *db.JobFile, opts ProcessOptions, history *documentHistory) ProcessingResult {
+	path := jobFileLocation(file)
+	stage := file.Status
+	if stage == db.JobFileFailed {
+		stage = file.RetryFrom
+	}
+	if stage == db.JobFileStored || stage == db.JobFileSkipped {
+		result := jobFileResult(file)
+		result.EarlierRun = true
+		return result
+	}
+
+	result := ProcessingResult{FilePath: path}
+	if file.Entry != "" {
+		result.Container = file.Path
+	}
+	if !isSupportedDocument(path) {
+		return p.failJobFile(ctx, file, db.JobFilePending, result, fmt.Errorf("unsupported file type: %s", filepath.Ext(path)))
+	}
+	src, err := openJobFile(file)
+	if err != nil {
+		return p.failJobFile(ctx, file, db.JobFilePending, result, err)
+	}
+	defer src.Close()
+	identity, err := src.importBatch("")
+	if err != nil {
+		return p.failJobFile(ctx, file, db.JobFilePending, result, err)
+	}
+
+	// A document whose content changed since an earlier run is extracted again
+	if stage != db.JobFilePending && file.FileHash != identity.FileHash {
+		stage = db.JobFilePending
+	}
+
+	doc := &document{}
+	if stage == db.JobFilePending {
+		status, previous := history.lookup(identity)
+		file.FileHash = identity.FileHash
+		file.DocumentStatus = string(status)
+		file.PreviousBatchID = nil
+		if previous != 0 {
+			file.PreviousBatchID = &previous
+		}
+
+		if status == DocumentUnchanged && !opts.Force {
+			p.logger.Info("skipping processed document", "path", path, "batch", previous)
+			p.saveJobFile(ctx, file, db.JobFileSkipped, nil)
+			return jobFileResult(file)
+		}
+
+		if doc, err = p.extractDocument(ctx, src.path, opts); err != nil {
+			return p.failJobFile(ctx, file, db.JobFilePending, result, err)
+		}
+		src.annotate(doc)
+		p.saveJobFile(ctx, file, db.JobFileExtracted, doc)
+	} else if err := json.Unmarshal([]byte(file.Document), doc); err != nil {
+		return p.failJobFile(ctx, file, db.JobFilePending, result, fmt.Errorf("failed to read saved document: %w", err))
+	}
+	result.Status, result.PreviousBatchID = jobFileStatus(file)
+	result.TransactionsFound = len(doc.Transactions)
+
+	if stage != db.JobFileAnalysed {
+		if err := p.analyseDocument(ctx, doc, opts); err != nil {
+			return p.failJobFile(ctx, file, db.JobFileExtracted, result, err)
+		}
+		p.saveJobFile(ctx, file, db.JobFileAnalysed, doc)
+	}
+
+	stored, err := p.storeDocument(ctx, src, doc, opts)
+	stored.Status, stored.PreviousBatchID = result.Status, result.PreviousBatchID
+	if err != nil {
+		return p.failJobFile(ctx, file, db.JobFileAnalysed, stored, err)
+	}
+
+	file.TransactionsFound = stored.TransactionsFound
+	file.Stored = stored.Stored
+	file.Skipped = stored.SkippedTransactions
+	file.ImportBatchID = &stored.BatchID
+	p.saveJobFile(ctx, file, db.JobFileStored, nil)
+
+	// Later files in the same run with this content are skipped as well
+	identity.ID = stored.BatchID
+	history.record(identity)
+	return stored
+}
+
+// failJobFile marks the document as failed, to be retried from the given stage.
+// A document the run was cancelled on keeps the stage it reached instead.
+func (p *Pipeline) failJobFile(ctx context.Context, file *db.JobFile, retryFrom db.JobFileStatus, result ProcessingResult, err error) ProcessingResult {
+	result.Error = err
+	if ctx.Err() != nil {
+		return result
+	}
+
+	p.logger.Error("failed to process file", "path", jobFileLocation(file), "error", err)
+	file.RetryFrom = retryFrom
+	file.Error = err.Error()
+	p.saveJobFile(ctx, file, db.JobFileFailed, nil)
+	return result
+}
+
+// saveJobFile records the stage a document reached, together with its
+// transactions until they are stored
+func (p *Pipeline) saveJobFile(ctx context.Context, file *db.JobFile, status db.JobFileStatus, doc *document) {
+	if status != db.JobFileFailed {
+		file.RetryFrom = ""
+		file.Error = ""
+	}
+	if doc != nil {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			p.logger.Error("failed to marshal document", "path", file.Path, "error", err)
+		} else {
+			file.Document = string(data)
+		}
+	} else if status == db.JobFileStored || status == db.JobFileSkipped {
+		file.Document = ""
+	}
+	file.Status = status
+
+	p.storeMu.Lock()
+	defer p.storeMu.Unlock()
+	if err := p.store.UpdateJobFile(context.WithoutCancel(ctx), file); err != nil {
+		p.logger.Error("failed to update job file", "path", file.Path, "status", status, "error", err)
+	}
+}
+
+// jobFileResult returns the result of a document that a run has finished with
+func jobFileResult(file *db.JobFile) ProcessingResult {
+	result := ProcessingResult{
+		FilePath:            jobFileLocation(file),
+		TransactionsFound:   file.TransactionsFound,
+		Stored:              file.Stored,
+		SkippedTransactions: file.Skipped,
+		Skipped:             file.Status == db.JobFileSkipped,
+	}
+	result.Status, result.PreviousBatchID = jobFileStatus(file)
+	if file.Entry != "" {
+		result.Container = file.Path
+	}
+	if file.ImportBatchID != nil {
+		result.BatchID = *file.ImportBatchID
+	}
+	return result
+}
+
+// jobFileStatus returns how the document compared to the documents processed before it
+func jobFileStatus(file *db.JobFile) (DocumentStatus, uint) {
+	var previous uint
+	if file.PreviousBatchID != nil {
+		previous = *file.PreviousBatchID
+	}
+	return DocumentStatus(file.DocumentStatus), previous
+}
@@ -0,0 +1,148 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/lindehoff/Budget-Assist/internal/core"
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/lindehoff/Budget-Assist/internal/docprocess"
+	"github.com/lindehoff/Budget-Assist/internal/processor"
+)
+
+func TestRunJob_Successfully_resume_interrupted_job(t *testing.T) {
+	// Setup
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mock := db.NewMockStore()
+	store := &cancellingStore{MockStore: mock, cancel: cancel}
+	pipeline := NewPipeline(&docprocess.PDFProcessor{}, processor.NewDefaultRegistry(), nil, store, logger)
+	dir := createStatementDir(t, 3)
+
+	job, err := pipeline.CreateJob(ctx, dir[0], ProcessOptions{Workers: 1})
+	if err != nil {
+		t.Fatalf("Failed to create job: %v", err)
+	}
+	if _, err := pipeline.RunJob(ctx, job, ProcessOptions{Workers: 1}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	interrupted, err := mock.GetProcessingJobByID(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	if interrupted.Status != db.JobInterrupted {
+		t.Fatalf("Expected job to be interrupted, got %s", interrupted.Status)
+	}
+
+	// Execute
+	resumer := NewPipeline(&docprocess.PDFProcessor{}, processor.NewDefaultRegistry(), nil, mock, logger)
+	loaded, opts, err := resumer.LoadJob(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("Failed to load job: %v", err)
+	}
+	results, err := resumer.RunJob(context.Background(), loaded, opts)
+
+	// Verify
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if !results[0].EarlierRun || results[0].Stored != 1 {
+		t.Errorf("Expected the first document to be stored by the earlier run, got %+v", results[0])
+	}
+	for _, result := range results[1:] {
+		if result.EarlierRun || result.Error != nil || result.Stored != 1 {
+			t.Errorf("Expected the resumed run to store 1 transaction, got %d (%v)", result.Stored, result.Error)
+		}
+	}
+
+	completed, _ := mock.GetProcessingJobByID(context.Background(), job.ID)
+	if completed.Status != db.JobCompleted || completed.CompletedAt == nil {
+		t.Errorf("Expected job to be completed, got %s", completed.Status)
+	}
+	for _, file := range completed.Files {
+		if file.Status != db.JobFileStored || file.Document != "" {
+			t.Errorf("Expected %s to be stored without saved document, got %s", file.Path, file.Status)
+		}
+	}
+	stored, _ := mock.ListTransactions(context.Background(), nil)
+	if len(stored) != 3 {
+		t.Errorf("Expected 3 stored transactions, got %d", len(stored))
+	}
+	if _, _, err := resumer.LoadJob(context.Background(), job.ID); err == nil {
+		t.Error("Expected an error when loading a completed job")
+	}
+}
+
+func TestRunJob_Successfully_continue_from_analysed_stage(t *testing.T) {
+	// Setup
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := db.NewMockStore()
+	pipeline := NewPipeline(&docprocess.PDFProcessor{}, processor.NewDefaultRegistry(), nil, store, logger)
+	dir := createStatementDir(t, 1)
+
+	job, err := pipeline.CreateJob(context.Background(), dir[0], ProcessOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create job: %v", err)
+	}
+	file := &job.Files[0]
+	doc, err := pipeline.extractDocument(context.Background(), file.Path, ProcessOptions{})
+	if err != nil {
+		t.Fatalf("Failed to extract document: %v", err)
+	}
+	// The saved stage is used, so a description changed after extraction is stored as saved
+	doc.Transactions[0].Description = "Saved before interruption"
+	hash, err := core.FileSHA256(file.Path)
+	if err != nil {
+		t.Fatalf("Failed to hash file: %v", err)
+	}
+	file.FileHash = hash
+	file.DocumentStatus = string(DocumentNew)
+	pipeline.saveJobFile(context.Background(), file, db.JobFileAnalysed, doc)
+
+	// Execute
+	results, err := pipeline.RunJob(context.Background(), job, ProcessOptions{})
+
+	// Verify
+	if err != nil || results[0].Error != nil {
+		t.Fatalf("Unexpected error: %v %v", err, results[0].Error)
+	}
+	stored, _ := store.ListTransactions(context.Background(), nil)
+	if len(stored) != 1 || stored[0].Description != "Saved before interruption" {
+		t.Errorf("Expected the saved transaction to be stored, got %v", stored)
+	}
+}
+
+func TestLoadJob_Successfully_restore_category_mapping(t *testing.T) {
+	// Setup
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := db.NewMockStore()
+	category := &db.Category{Name: "Mat", TypeID: 1, IsActive: true}
+	if err := store.CreateCategory(context.Background(), category); err != nil {
+		t.Fatalf("Failed to create category: %v", err)
+	}
+	pipeline := NewPipeline(&docprocess.PDFProcessor{}, processor.NewDefaultRegistry(), nil, store, logger)
+	dir := createStatementDir(t, 1)
+	job, err := pipeline.CreateJob(context.Background(), dir[0], ProcessOptions{MapCategories: true, CategoryMap: map[string]string{"Food": "Mat"}})
+	if err != nil {
+		t.Fatalf("Failed to create job: %v", err)
+	}
+
+	// Execute
+	_, opts, err := pipeline.LoadJob(context.Background(), job.ID)
+
+	// Verify
+	if err != nil {
+		t.Fatalf("Failed to load job: %v", err)
+	}
+	categoryID, _, ok := opts.Categories.Map(processor.Transaction{Category: "Food"})
+	if !ok || *categoryID != category.ID {
+		t.Errorf("Expected the resumed job to map Food onto category %d, got %v", category.ID, categoryID)
+	}
+}
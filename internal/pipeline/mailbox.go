@@ -0,0 +1,137 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lindehoff/Budget-Assist/internal/docprocess"
+)
+
+// Mailbox is a source of emails whose attachments are processed
+type Mailbox interface {
+	// Unprocessed returns the identifiers of the messages not marked as processed
+	Unprocessed(ctx context.Context) ([]string, error)
+	// Fetch downloads a message in RFC 822 format
+	Fetch(ctx context.Context, id string) ([]byte, error)
+	// MarkProcessed marks a message so that Unprocessed no longer returns it
+	MarkProcessed(ctx context.Context, id string) error
+}
+
+// MailOptions configures how the messages of a mailbox are processed
+type MailOptions struct {
+	Process    ProcessOptions
+	ArchiveDir string // Directory messages with attachments are saved to before they are processed
+}
+
+// MailResult is the outcome of a message of a mailbox
+type MailResult struct {
+	ID      string
+	Subject string
+	Sender  string
+	Path    string             // Where the message was saved, empty for messages without attachments
+	Results []ProcessingResult // One for each supported attachment
+	Error   error              // Set when the message or any of its attachments failed
+}
+
+// IngestMailbox processes the supported attachments of the unprocessed messages
+// in the mailbox. Each message with attachments is saved to opts.ArchiveDir and
+// processed as a job of its own, so that its transactions record the saved
+// message as their source. A message is marked as processed once all its
+// attachments are stored or skipped as processed before; messages without
+// supported attachments are marked right away. Failed messages stay unmarked
+// and are retried on the next run. When ctx is cancelled the results so far
+// are returned together with the context error.
+func (p *Pipeline) IngestMailbox(ctx context.Context, mailbox Mailbox, opts MailOptions) ([]MailResult, error) {
+	if err := os.MkdirAll(opts.ArchiveDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create mail archive: %w", err)
+	}
+
+	ids, err := mailbox.Unprocessed(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+	p.logger.Info("found unprocessed messages", "count", len(ids))
+
+	var results []MailResult
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return results, fmt.Errorf("ingestion cancelled: %w", err)
+		}
+		result := p.ingestMessage(ctx, mailbox, id, opts)
+		if result.Error != nil {
+			p.logger.Error("failed to ingest message", "id", id, "subject", result.Subject, "error", result.Error)
+		} else {
+			p.logger.Info("ingested message", "id", id, "subject", result.Subject, "attachments", len(result.Results))
+		}
+		results = append(results, result)
+	}
+	if err := ctx.Err(); err != nil {
+		return results, fmt.Errorf("ingestion cancelled: %w", err)
+	}
+	return results, nil
+}
+
+// ingestMessage downloads, saves and processes a single message
+func (p *Pipeline) ingestMessage(ctx context.Context, mailbox Mailbox, id string, opts MailOptions) MailResult {
+	result := MailResult{ID: id}
+	data, err := mailbox.Fetch(ctx, id)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to fetch message: %w", err)
+		return result
+	}
+
+	email, err := docprocess.ReadEmail(data)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.Subject, result.Sender = email.Subject, email.Sender
+	supported := 0
+	for _, attachment := range email.Documents {
+		if isSupportedDocument(attachment.Name) && !docprocess.IsContainer(attachment.Name) {
+			supported++
+		}
+	}
+	if supported == 0 {
+		p.logger.Debug("message has no supported attachments", "id", id)
+		if err := mailbox.MarkProcessed(ctx, id); err != nil {
+			result.Error = fmt.Errorf("failed to mark message as processed: %w", err)
+		}
+		return result
+	}
+
+	result.Path = filepath.Join(opts.ArchiveDir, messageFileName(id))
+	if err := os.WriteFile(result.Path, data, 0600); err != nil {
+		result.Error = fmt.Errorf("failed to save message: %w", err)
+		return result
+	}
+
+	job, err := p.CreateJob(ctx, result.Path, opts.Process)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.Results, err = p.RunJob(ctx, job, opts.Process)
+	for _, processed := range result.Results {
+		if err == nil && processed.Error != nil {
+			err = processed.Error
+		}
+	}
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	if err := mailbox.MarkProcessed(ctx, id); err != nil {
+		result.Error = fmt.Errorf("failed to mark message as processed: %w", err)
+	}
+	return result
+}
+
+// messageFileName returns the file name a message is saved under
+func messageFileName(id string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(id) + ".eml"
+}
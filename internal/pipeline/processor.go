@@ -4,13 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io/fs"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/lindehoff/Budget-Assist/internal/ai"
+	"github.com/lindehoff/Budget-Assist/internal/core"
 	"github.com/lindehoff/Budget-Assist/internal/db"
 	"github.com/lindehoff/Budget-Assist/internal/docprocess"
 	"github.com/lindehoff/Budget-Assist/internal/processor"
@@ -24,29 +26,159 @@ type ProcessOptions struct {
 	TransactionInsights string
 	// CategoryInsights provides hints or context for transaction categorization
 	CategoryInsights string
+	// Bank forces a registered parser for statement files instead of detecting it from the header
+	Bank string
+	// AccountID binds the transactions to an account; when nil they are bound by
+	// the account number the document states, if it matches an account
+	AccountID *uint
+	// Currency is the ISO currency code of transactions whose document states none, SEK when empty
+	Currency string
+	// BaseCurrency is the currency the stored transactions are converted to with
+	// the stored exchange rates; empty disables conversion
+	BaseCurrency string
+	// Categories maps categories stated in the document onto existing categories, skipping AI analysis for those rows
+	Categories *core.CategoryMapper `json:"-"`
+	// MapCategories records that Categories is used, so that a resumed job
+	// builds it again from the stored categories and CategoryMap
+	MapCategories bool
+	// CategoryMap overrides the category a stated category name maps onto
+	CategoryMap map[string]string
+	// OnDuplicate decides what happens to transactions stored by an earlier run, default skip
+	OnDuplicate core.DuplicatePolicy
+	// Force reprocesses documents whose content was already processed
+	Force bool
+	// Workers is the number of documents processed in parallel, default 1
+	Workers int `json:"-"`
 }
 
+// currency returns the currency of transactions whose document states none
+func (o ProcessOptions) currency() string {
+	if o.Currency == "" {
+		return db.CurrencySEK
+	}
+	return o.Currency
+}
+
+// DocumentStatus tells how a document compares to the documents processed before
+type DocumentStatus string
+
+// Document statuses
+const (
+	DocumentNew       DocumentStatus = "new"       // Neither the path nor the content was processed before
+	DocumentChanged   DocumentStatus = "changed"   // The path was processed before with other content
+	DocumentUnchanged DocumentStatus = "unchanged" // The content was processed before
+)
+
 // ProcessingResult represents the result of processing a document
 type ProcessingResult struct {
-	FilePath          string
+	FilePath string
+	// Container is the ZIP archive, email or mailbox the document was extracted from
+	Container         string
 	TransactionsFound int
-	Error             error
+	// Stored is the number of transactions written to the store
+	Stored int
+	// Skipped is the number of transactions left out as already stored
+	SkippedTransactions int
+	// Failed is the number of transactions that could not be stored. Any
+	// failure rolls back the whole document, so Stored is then zero.
+	Failed int
+	// Rejected lists the rows the parser could not read
+	Rejected []processor.RowError
+	// BalanceGaps lists the rows whose running balance does not follow from the
+	// rows before them, which means rows are missing or corrupted
+	BalanceGaps []processor.BalanceGap
+	// StoreErrors lists the transactions that could not be stored
+	StoreErrors []StoreError
+	// Duplicates counts the transactions that were new and those already stored
+	Duplicates core.DedupSummary
+	// BatchID identifies the import batch that can be undone with "import undo"
+	BatchID uint
+	// Status compares the document to the documents processed before
+	Status DocumentStatus
+	// Skipped is set for unchanged documents that were not processed again
+	Skipped bool
+	// PreviousBatchID is the latest batch that processed the path or content before
+	PreviousBatchID uint
+	// EarlierRun is set for documents an earlier run of the job finished
+	EarlierRun bool
+	Error      error
+}
+
+// statementExtensions lists the file types read by the statement parsers
+var statementExtensions = map[string]bool{
+	".csv": true, ".xlsx": true, ".ofx": true, ".qfx": true, ".qif": true,
+	".xml": true, ".sta": true, ".mt940": true,
+}
+
+// documentHistory holds the latest completed import batch for each file
+// content and path, to recognize documents that were already processed
+type documentHistory struct {
+	mu     sync.Mutex
+	byHash map[string]db.ImportBatch
+	byPath map[string]db.ImportBatch
+}
+
+// lookup compares a document to the documents processed before
+func (h *documentHistory) lookup(document *db.ImportBatch) (DocumentStatus, uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if batch, exists := h.byHash[document.FileHash]; exists {
+		return DocumentUnchanged, batch.ID
+	}
+	if batch, exists := h.byPath[document.FilePath]; exists {
+		return DocumentChanged, batch.ID
+	}
+	return DocumentNew, 0
+}
+
+// record adds a processed document
+func (h *documentHistory) record(document *db.ImportBatch) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.byHash[document.FileHash] = *document
+	h.byPath[document.FilePath] = *document
+}
+
+// document holds the transactions extracted from a file. It is kept with the
+// job file between the stages, so that a resumed job continues where it stopped.
+type document struct {
+	Parser       string
+	Parsed       int
+	Rejected     []processor.RowError
+	BalanceGaps  []processor.BalanceGap
+	Balances     []processor.StatementBalance
+	Transactions []db.Transaction
+}
+
+// StoreError describes a transaction of a document that could not be stored
+type StoreError struct {
+	Row         int // Position of the transaction in the document, starting at 1
+	Description string
+	Err         error
+}
+
+func (e StoreError) String() string {
+	return fmt.Sprintf("row %d (%s): %v", e.Row, e.Description, e.Err)
 }
 
 // Pipeline handles the document processing workflow
 type Pipeline struct {
 	docProcessor *docprocess.PDFProcessor
-	csvProcessor *processor.SEBProcessor
+	parsers      *processor.Registry
 	aiService    ai.Service
 	store        db.Store
 	logger       *slog.Logger
+
+	// storeMu lets one document at a time write to the store, so that
+	// parallel workers neither race on duplicates nor lock SQLite
+	storeMu sync.Mutex
 }
 
 // NewPipeline creates a new processing pipeline
-func NewPipeline(dp *docprocess.PDFProcessor, cp *processor.SEBProcessor, ai ai.Service, store db.Store, logger *slog.Logger) *Pipeline {
+func NewPipeline(dp *docprocess.PDFProcessor, parsers *processor.Registry, ai ai.Service, store db.Store, logger *slog.Logger) *Pipeline {
 	return &Pipeline{
 		docProcessor: dp,
-		csvProcessor: cp,
+		parsers:      parsers,
 		aiService:    ai,
 		store:        store,
 		logger:       logger,
@@ -54,85 +186,170 @@ func NewPipeline(dp *docprocess.PDFProcessor, cp *processor.SEBProcessor, ai ai.
 }
 
 // ProcessDocuments processes all documents in the given path with the specified options
+// as a new processing job. A single file that fails is returned as an error, see RunJob
+// for directories.
 func (p *Pipeline) ProcessDocuments(ctx context.Context, path string, opts ProcessOptions) ([]ProcessingResult, error) {
-	var results []ProcessingResult
-
 	// Check if path is a file or directory
 	fileInfo, err := os.Stat(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to access path: %w", err)
 	}
 
-	if fileInfo.IsDir() {
-		// Process directory
-		if err := filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
-			if !d.IsDir() {
-				result, err := p.processFile(ctx, path, opts)
-				if err != nil {
-					p.logger.Error("failed to process file", "path", path, "error", err)
-					results = append(results, ProcessingResult{
-						FilePath: path,
-						Error:    err,
-					})
-				} else {
-					results = append(results, result)
-				}
+	job, err := p.CreateJob(ctx, path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := p.RunJob(ctx, job, opts)
+	if !fileInfo.IsDir() && err == nil {
+		// A single file fails when any of its documents, such as those of a ZIP archive, failed
+		for _, result := range results {
+			if result.Error != nil {
+				return nil, fmt.Errorf("failed to process file: %w", result.Error)
 			}
-			return nil
-		}); err != nil {
-			return nil, fmt.Errorf("failed to walk directory: %w", err)
 		}
-	} else {
-		// Process single file
-		result, err := p.processFile(ctx, path, opts)
-		if err != nil {
-			return nil, fmt.Errorf("failed to process file: %w", err)
-		}
-		results = append(results, result)
+	}
+	return results, err
+}
+
+// loadDocumentHistory collects the completed import batches, most recent first
+func (p *Pipeline) loadDocumentHistory(ctx context.Context) (*documentHistory, error) {
+	batches, err := p.store.ListImportBatches(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return results, nil
+	history := &documentHistory{
+		byHash: make(map[string]db.ImportBatch),
+		byPath: make(map[string]db.ImportBatch),
+	}
+	for _, batch := range batches {
+		if batch.CompletedAt == nil {
+			continue
+		}
+		if _, exists := history.byHash[batch.FileHash]; !exists && batch.FileHash != "" {
+			history.byHash[batch.FileHash] = batch
+		}
+		if _, exists := history.byPath[batch.FilePath]; !exists && batch.FilePath != "" {
+			history.byPath[batch.FilePath] = batch
+		}
+	}
+	return history, nil
 }
 
-// processFile handles processing of a single file
-func (p *Pipeline) processFile(ctx context.Context, path string, opts ProcessOptions) (ProcessingResult, error) {
+// isSupportedDocument reports whether the file type can be processed, either
+// as a document or as a container of documents
+func isSupportedDocument(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".pdf" || statementExtensions[ext] || docprocess.IsContainer(path)
+}
 
-	var transactions []db.Transaction
-	var err error
+// processFile handles processing of a single file outside a job
+func (p *Pipeline) processFile(ctx context.Context, path string, opts ProcessOptions) (ProcessingResult, error) {
+	doc, err := p.extractDocument(ctx, path, opts)
+	if err != nil {
+		return ProcessingResult{FilePath: path}, err
+	}
+	if err := p.analyseDocument(ctx, doc, opts); err != nil {
+		return ProcessingResult{FilePath: path, TransactionsFound: len(doc.Transactions)}, err
+	}
+	return p.storeDocument(ctx, fileSource(path), doc, opts)
+}
 
-	switch ext {
-	case ".pdf":
-		transactions, err = p.processPDF(ctx, path, opts)
-	case ".csv":
-		transactions, err = p.processCSV(ctx, path, opts)
+// extractDocument reads the transactions of a file with the processor for its type
+func (p *Pipeline) extractDocument(ctx context.Context, path string, opts ProcessOptions) (*document, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	switch {
+	case ext == ".pdf":
+		return p.extractPDF(ctx, path, opts)
+	case statementExtensions[ext]:
+		return p.extractStatement(ctx, path, opts)
 	default:
-		return ProcessingResult{FilePath: path}, fmt.Errorf("unsupported file type: %s", ext)
+		return nil, fmt.Errorf("unsupported file type: %s", ext)
+	}
+}
+
+// storeDocument stores the transactions of a document as one import batch
+func (p *Pipeline) storeDocument(ctx context.Context, src *documentSource, doc *document, opts ProcessOptions) (ProcessingResult, error) {
+	path := src.location
+	transactions := doc.Transactions
+	result := ProcessingResult{
+		FilePath:          path,
+		Container:         src.container,
+		TransactionsFound: len(transactions),
+		Rejected:          doc.Rejected,
+		BalanceGaps:       doc.BalanceGaps,
 	}
 
+	// Record the file as an import batch so that its transactions can be undone
+	batch, err := src.importBatch(doc.Parser)
 	if err != nil {
-		return ProcessingResult{FilePath: path}, err
+		return result, err
 	}
 
-	// Store transactions in database
-	for _, tx := range transactions {
-		if err := p.store.CreateTransaction(ctx, &tx); err != nil {
-			p.logger.Error("failed to store transaction", "error", err)
-			continue
+	// Bind, convert and store the batch and its transactions together, leaving
+	// out those an earlier run stored, so that a failure leaves no part of the
+	// document behind. Accounts and exchange rates are read under the same lock,
+	// so that concurrent documents cannot change them in between.
+	var summary core.DedupSummary
+	p.storeMu.Lock()
+	defer p.storeMu.Unlock()
+	err = p.store.WithTransaction(ctx, func(store db.Store) error {
+		if err := core.BindAccounts(ctx, store, transactions, opts.AccountID); err != nil {
+			return err
+		}
+		if opts.BaseCurrency != "" {
+			missing, err := core.ConvertTransactions(ctx, store, opts.BaseCurrency, transactions)
+			if err != nil {
+				return err
+			}
+			if missing > 0 {
+				p.logger.Warn("no exchange rate for transactions, import rates and run 'budgetassist exchange convert'",
+					"path", path,
+					"transactions", missing,
+					"base_currency", opts.BaseCurrency)
+			}
+		}
+
+		if err := core.BeginImportBatch(ctx, store, batch); err != nil {
+			return err
+		}
+
+		dedup := core.NewDeduplicator(store, opts.OnDuplicate)
+		for i, tx := range transactions {
+			tx.ImportBatchID = &batch.ID
+			if err := dedup.Store(ctx, &tx); err != nil {
+				p.logger.Error("failed to store transaction", "path", path, "row", i+1, "error", err)
+				result.StoreErrors = append(result.StoreErrors, StoreError{Row: i + 1, Description: tx.Description, Err: err})
+			}
 		}
+		if len(result.StoreErrors) > 0 {
+			return fmt.Errorf("failed to store %d of %d transactions", len(result.StoreErrors), len(transactions))
+		}
+
+		if _, err := core.StoreStatementBalances(ctx, store, doc.Balances, opts.AccountID, &batch.ID); err != nil {
+			return err
+		}
+
+		summary = dedup.Summary()
+		return core.CompleteImportBatch(ctx, store, batch, doc.Parsed, len(doc.Rejected), summary)
+	})
+	if err != nil {
+		result.Failed = len(result.StoreErrors)
+		p.logger.Error("rolled back document", "path", path, "error", err)
+		return result, fmt.Errorf("document rolled back: %w", err)
 	}
 
-	return ProcessingResult{
-		FilePath:          path,
-		TransactionsFound: len(transactions),
-	}, nil
+	p.logger.Info("stored transactions", "path", path, "batch", batch.ID, "new", summary.New, "known", summary.Known())
+	result.Stored = summary.Stored()
+	result.SkippedTransactions = summary.Skipped
+	result.Duplicates = summary
+	result.BatchID = batch.ID
+	return result, nil
 }
 
-// processPDF handles PDF document processing
-func (p *Pipeline) processPDF(ctx context.Context, path string, opts ProcessOptions) ([]db.Transaction, error) {
+// extractPDF handles PDF document processing
+func (p *Pipeline) extractPDF(ctx context.Context, path string, opts ProcessOptions) (*document, error) {
 	// Extract text from PDF
 	file, err := os.Open(path)
 	if err != nil {
@@ -147,7 +364,7 @@ func (p *Pipeline) processPDF(ctx context.Context, path string, opts ProcessOpti
 	}
 
 	// Convert to database transactions
-	transactions := make([]db.Transaction, 0, len(result.Transactions))
+	doc := &document{Parser: "pdf", Parsed: len(result.Transactions)}
 	for _, tx := range result.Transactions {
 		// Convert raw data to JSON string
 		rawData, err := json.Marshal(tx.RawData)
@@ -156,55 +373,52 @@ func (p *Pipeline) processPDF(ctx context.Context, path string, opts ProcessOpti
 			continue
 		}
 
-		// Create transaction record with insights
-		dbTx := db.Transaction{
+		doc.Transactions = append(doc.Transactions, db.Transaction{
 			Description:     tx.Description,
 			Amount:          tx.Amount,
+			Date:            tx.Date,
 			TransactionDate: tx.Date,
 			RawData:         string(rawData),
-			Currency:        db.CurrencySEK, // Default to SEK
-		}
-
-		// Analyze transaction for categorization with insights
-		analysis, err := p.aiService.AnalyzeTransaction(ctx, &dbTx, ai.AnalysisOptions{
-			DocumentType:    opts.DocumentType,
-			RuntimeInsights: opts.TransactionInsights + "\n" + opts.CategoryInsights,
+			Currency:        opts.currency(),
 		})
-		if err != nil {
-			p.logger.Error("failed to analyze transaction", "error", err)
-			continue
-		}
-
-		// Convert analysis to JSON string
-		aiAnalysis, err := json.Marshal(analysis)
-		if err != nil {
-			p.logger.Error("failed to marshal AI analysis", "error", err)
-			continue
-		}
-
-		dbTx.AIAnalysis = string(aiAnalysis)
-		transactions = append(transactions, dbTx)
 	}
 
-	return transactions, nil
+	return doc, nil
 }
 
-// processCSV handles CSV document processing
-func (p *Pipeline) processCSV(ctx context.Context, path string, opts ProcessOptions) ([]db.Transaction, error) {
-	// Process CSV using SEB processor
+// extractStatement handles bank statement files (CSV, XLSX, OFX, QIF, camt, MT940) using the registered parsers
+func (p *Pipeline) extractStatement(ctx context.Context, path string, opts ProcessOptions) (*document, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open CSV: %w", err)
+		return nil, fmt.Errorf("failed to open statement: %w", err)
 	}
 	defer file.Close()
 
-	rawTransactions, err := p.csvProcessor.ProcessDocument(ctx, file)
+	// Select the parser registered for the bank, or detect it from the file header
+	parser, parserInfo, reader, err := p.selectParser(file, opts.Bank)
 	if err != nil {
-		return nil, fmt.Errorf("failed to process CSV: %w", err)
+		return nil, fmt.Errorf("failed to select statement parser: %w", err)
 	}
 
-	// Convert and categorize transactions
-	transactions := make([]db.Transaction, 0, len(rawTransactions))
+	rawTransactions, err := parser.ProcessDocument(ctx, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process statement: %w", err)
+	}
+	doc := &document{Parser: parserInfo.ID, Parsed: len(rawTransactions)}
+	if reporter, ok := parser.(processor.RowErrorReporter); ok {
+		doc.Rejected = reporter.RejectedRows()
+	}
+	doc.Balances = core.StatementBalances(parser, rawTransactions)
+	doc.BalanceGaps = processor.CheckRunningBalance(rawTransactions)
+	for _, gap := range doc.BalanceGaps {
+		p.logger.Warn("running balance does not match transactions, rows may be missing",
+			"path", path,
+			"date", gap.Date.Format("2006-01-02"),
+			"description", gap.Description,
+			"difference", gap.Difference())
+	}
+
+	// Convert transactions, categorizing rows whose document category maps onto an existing category
 	for _, tx := range rawTransactions {
 		// Convert raw data to JSON string
 		rawData, err := json.Marshal(tx.RawData)
@@ -216,32 +430,92 @@ func (p *Pipeline) processCSV(ctx context.Context, path string, opts ProcessOpti
 		dbTx := db.Transaction{
 			Description:     tx.Description,
 			Amount:          tx.Amount,
+			Balance:         tx.Balance,
+			Date:            tx.Date,
 			TransactionDate: tx.Date,
+			ValueDate:       tx.ValueDate,
+			Reference:       tx.Reference,
+			Source:          tx.Source,
 			RawData:         string(rawData),
-			Currency:        db.CurrencySEK, // Default to SEK
+			Currency:        opts.currency(),
+		}
+		if tx.Currency != "" {
+			dbTx.Currency = tx.Currency
+		}
+		if categoryID, subcategoryID, ok := opts.Categories.Map(tx); ok {
+			dbTx.CategoryID = categoryID
+			dbTx.SubcategoryID = subcategoryID
 		}
 
-		// Only analyze with AI if service is available
-		if p.aiService != nil {
-			analysis, err := p.aiService.AnalyzeTransaction(ctx, &dbTx, ai.AnalysisOptions{
-				DocumentType:    opts.DocumentType,
-				RuntimeInsights: opts.TransactionInsights + "\n" + opts.CategoryInsights,
-			})
-			if err != nil {
-				p.logger.Error("failed to analyze transaction", "error", err)
-			} else {
-				// Convert analysis to JSON string
-				aiAnalysis, err := json.Marshal(analysis)
-				if err != nil {
-					p.logger.Error("failed to marshal AI analysis", "error", err)
-				} else {
-					dbTx.AIAnalysis = string(aiAnalysis)
-				}
+		doc.Transactions = append(doc.Transactions, dbTx)
+	}
+
+	return doc, nil
+}
+
+// analyseDocument categorizes the transactions of a document with AI. Transactions
+// that already have a category need no analysis, and those the analysis fails for
+// are kept without one. The context error is returned when the run is cancelled,
+// as the analysis is then incomplete.
+func (p *Pipeline) analyseDocument(ctx context.Context, doc *document, opts ProcessOptions) error {
+	// Only analyze with AI if service is available
+	if p.aiService == nil {
+		return nil
+	}
+
+	for i := range doc.Transactions {
+		dbTx := &doc.Transactions[i]
+		if dbTx.CategoryID != nil {
+			continue
+		}
+
+		analysis, err := p.aiService.AnalyzeTransaction(ctx, dbTx, ai.AnalysisOptions{
+			DocumentType:    opts.DocumentType,
+			RuntimeInsights: opts.TransactionInsights + "\n" + opts.CategoryInsights,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
 			}
+			p.logger.Error("failed to analyze transaction", "error", err)
+			continue
 		}
 
-		transactions = append(transactions, dbTx)
+		// Convert analysis to JSON string
+		aiAnalysis, err := json.Marshal(analysis)
+		if err != nil {
+			p.logger.Error("failed to marshal AI analysis", "error", err)
+			continue
+		}
+		dbTx.AIAnalysis = string(aiAnalysis)
+	}
+
+	return ctx.Err()
+}
+
+// selectParser returns the parser for the given bank id, or the parser detected from the
+// document header when bank is empty, together with a reader positioned at the start of the file
+func (p *Pipeline) selectParser(file io.Reader, bank string) (processor.Parser, processor.ParserInfo, io.Reader, error) {
+	if p.parsers == nil {
+		return nil, processor.ParserInfo{}, nil, fmt.Errorf("no parser registry configured")
 	}
 
-	return transactions, nil
+	if bank != "" {
+		parser, info, err := p.parsers.Get(bank, p.logger)
+		if err != nil {
+			return nil, processor.ParserInfo{}, nil, err
+		}
+		return parser, info, file, nil
+	}
+
+	sample, reader, err := processor.Sniff(file)
+	if err != nil {
+		return nil, processor.ParserInfo{}, nil, err
+	}
+	parser, info, err := p.parsers.Detect(sample, p.logger)
+	if err != nil {
+		return nil, processor.ParserInfo{}, nil, err
+	}
+	p.logger.Debug("detected document format", "bank", info.ID, "format", info.Format)
+	return parser, info, reader, nil
 }
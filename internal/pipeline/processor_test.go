@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -9,11 +10,13 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/lindehoff/Budget-Assist/internal/ai"
 	"github.com/lindehoff/Budget-Assist/internal/db"
 	"github.com/lindehoff/Budget-Assist/internal/docprocess"
 	"github.com/lindehoff/Budget-Assist/internal/processor"
+	"github.com/shopspring/decimal"
 )
 
 // Mock implementations for testing
@@ -83,14 +86,56 @@ func (m *mockStore) DeleteCategorySubcategory(ctx context.Context, categoryID, s
 func (m *mockStore) GetTransactionByID(ctx context.Context, id uint) (*db.Transaction, error) {
 	return nil, nil
 }
+func (m *mockStore) GetTransactionByFingerprint(ctx context.Context, fingerprint string) (*db.Transaction, error) {
+	return nil, db.ErrNotFound
+}
 func (m *mockStore) ListTransactions(ctx context.Context, filter *db.TransactionFilter) ([]db.Transaction, error) {
 	return nil, nil
 }
 func (m *mockStore) UpdateTransaction(ctx context.Context, tx *db.Transaction) error { return nil }
 func (m *mockStore) DeleteTransaction(ctx context.Context, id uint) error            { return nil }
-func (m *mockStore) CreatePrompt(ctx context.Context, p *db.Prompt) error            { return nil }
-func (m *mockStore) UpdatePrompt(ctx context.Context, p *db.Prompt) error            { return nil }
-func (m *mockStore) GetPromptByID(ctx context.Context, id uint) (*db.Prompt, error)  { return nil, nil }
+func (m *mockStore) CreateAccount(ctx context.Context, a *db.Account) error          { return nil }
+func (m *mockStore) UpdateAccount(ctx context.Context, a *db.Account) error          { return nil }
+func (m *mockStore) GetAccountByID(ctx context.Context, id uint) (*db.Account, error) {
+	return nil, db.ErrNotFound
+}
+func (m *mockStore) GetAccountByName(ctx context.Context, name string) (*db.Account, error) {
+	return nil, db.ErrNotFound
+}
+func (m *mockStore) ListAccounts(ctx context.Context) ([]db.Account, error) { return nil, nil }
+func (m *mockStore) DeleteAccount(ctx context.Context, id uint) (int64, error) {
+	return 0, db.ErrNotFound
+}
+func (m *mockStore) CreateStatementBalance(ctx context.Context, b *db.StatementBalance) error {
+	return nil
+}
+func (m *mockStore) ListStatementBalances(ctx context.Context, accountID uint) ([]db.StatementBalance, error) {
+	return nil, nil
+}
+func (m *mockStore) SaveExchangeRates(ctx context.Context, rates []db.ExchangeRate) error {
+	return nil
+}
+func (m *mockStore) ListExchangeRates(ctx context.Context, start, end *time.Time) ([]db.ExchangeRate, error) {
+	return nil, nil
+}
+func (m *mockStore) CreateImportBatch(ctx context.Context, b *db.ImportBatch) error {
+	return nil
+}
+func (m *mockStore) UpdateImportBatch(ctx context.Context, b *db.ImportBatch) error {
+	return nil
+}
+func (m *mockStore) GetImportBatchByID(ctx context.Context, id uint) (*db.ImportBatch, error) {
+	return nil, db.ErrNotFound
+}
+func (m *mockStore) ListImportBatches(ctx context.Context) ([]db.ImportBatch, error) {
+	return nil, nil
+}
+func (m *mockStore) DeleteImportBatch(ctx context.Context, id uint) (int64, error) {
+	return 0, db.ErrNotFound
+}
+func (m *mockStore) CreatePrompt(ctx context.Context, p *db.Prompt) error           { return nil }
+func (m *mockStore) UpdatePrompt(ctx context.Context, p *db.Prompt) error           { return nil }
+func (m *mockStore) GetPromptByID(ctx context.Context, id uint) (*db.Prompt, error) { return nil, nil }
 func (m *mockStore) GetPromptByType(ctx context.Context, promptType string) (*db.Prompt, error) {
 	return nil, nil
 }
@@ -104,8 +149,41 @@ func (m *mockStore) LinkSubcategoryTag(ctx context.Context, subcategoryID, tagID
 func (m *mockStore) UnlinkSubcategoryTag(ctx context.Context, subcategoryID, tagID uint) error {
 	return nil
 }
+func (m *mockStore) CreateProcessingJob(ctx context.Context, job *db.ProcessingJob) error {
+	return nil
+}
+func (m *mockStore) UpdateProcessingJob(ctx context.Context, job *db.ProcessingJob) error {
+	return nil
+}
+func (m *mockStore) GetProcessingJobByID(ctx context.Context, id uint) (*db.ProcessingJob, error) {
+	return nil, db.ErrNotFound
+}
+func (m *mockStore) ListProcessingJobs(ctx context.Context) ([]db.ProcessingJob, error) {
+	return nil, nil
+}
+func (m *mockStore) UpdateJobFile(ctx context.Context, file *db.JobFile) error { return nil }
+func (m *mockStore) WithTransaction(ctx context.Context, fn func(store db.Store) error) error {
+	return fn(m)
+}
 func (m *mockStore) Close() error { return nil }
 
+// failingStore is a db.MockStore that fails to create the transactions with the given description
+type failingStore struct {
+	*db.MockStore
+	failDescription string
+}
+
+func (s *failingStore) CreateTransaction(ctx context.Context, tx *db.Transaction) error {
+	if tx.Description == s.failDescription {
+		return errors.New("constraint failed")
+	}
+	return s.MockStore.CreateTransaction(ctx, tx)
+}
+
+func (s *failingStore) WithTransaction(ctx context.Context, fn func(store db.Store) error) error {
+	return s.MockStore.WithTransaction(ctx, func(db.Store) error { return fn(s) })
+}
+
 // Helper functions for testing
 func createTempFile(t *testing.T, ext string, content []byte) string {
 	t.Helper()
@@ -122,13 +200,13 @@ func createTempFile(t *testing.T, ext string, content []byte) string {
 func TestNewPipeline(t *testing.T) {
 	// Setup
 	pdfProcessor := &docprocess.PDFProcessor{}
-	csvProcessor := &processor.SEBProcessor{}
+	csvParsers := processor.NewDefaultRegistry()
 	mockAI := &mockAIService{}
 	mockDB := &mockStore{}
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
 	// Execute
-	pipeline := NewPipeline(pdfProcessor, csvProcessor, mockAI, mockDB, logger)
+	pipeline := NewPipeline(pdfProcessor, csvParsers, mockAI, mockDB, logger)
 
 	// Verify
 	if pipeline == nil {
@@ -142,14 +220,98 @@ func TestProcessFile_Successfully_process_pdf(t *testing.T) {
 }
 
 func TestProcessFile_Successfully_process_csv(t *testing.T) {
-	// Skip this test for now until we can properly mock the SEBProcessor
-	t.Skip("Skipping test until we can properly mock the SEBProcessor")
+	// Setup
+	pdfProcessor := &docprocess.PDFProcessor{}
+	csvParsers := processor.NewDefaultRegistry()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var stored []db.Transaction
+	mockDB := &mockStore{
+		createTransactionFunc: func(ctx context.Context, tx *db.Transaction) error {
+			stored = append(stored, *tx)
+			return nil
+		},
+	}
+
+	csvPath := createTempFile(t, ".csv", []byte("Bokföringsdatum;Valutadatum;Verifikationsnummer;Text;Belopp;Saldo\n"+
+		"2025-02-24;2025-02-22;5490990004;ICA Kvantum;-1000.000;2814.160\n"))
+
+	// Create pipeline without AI so no analysis is performed
+	pipeline := NewPipeline(pdfProcessor, csvParsers, nil, mockDB, logger)
+
+	// Execute
+	result, err := pipeline.processFile(context.Background(), csvPath, ProcessOptions{})
+
+	// Verify
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TransactionsFound != 1 {
+		t.Errorf("Expected 1 transaction, got %d", result.TransactionsFound)
+	}
+	if len(stored) != 1 || stored[0].Description != "ICA Kvantum" {
+		t.Errorf("Expected the SEB transaction to be stored, got %+v", stored)
+	}
+}
+
+func TestProcessFile_Successfully_skip_transactions_stored_by_earlier_run(t *testing.T) {
+	// Setup
+	pdfProcessor := &docprocess.PDFProcessor{}
+	csvParsers := processor.NewDefaultRegistry()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := db.NewMockStore()
+
+	csvPath := createTempFile(t, ".csv", []byte("Bokföringsdatum;Valutadatum;Verifikationsnummer;Text;Belopp;Saldo\n"+
+		"2025-02-24;2025-02-22;5490990004;ICA Kvantum;-1000.000;2814.160\n"+
+		"2025-02-25;2025-02-25;5490990005;Lön;25000.000;27814.160\n"))
+	pipeline := NewPipeline(pdfProcessor, csvParsers, nil, store, logger)
+
+	// Execute
+	first, err := pipeline.processFile(context.Background(), csvPath, ProcessOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := pipeline.processFile(context.Background(), csvPath, ProcessOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Verify
+	if first.Duplicates.New != 2 || first.Duplicates.Known() != 0 {
+		t.Errorf("Expected 2 new transactions on the first run, got %s", first.Duplicates)
+	}
+	if second.Duplicates.New != 0 || second.Duplicates.Skipped != 2 {
+		t.Errorf("Expected 2 skipped transactions on the second run, got %s", second.Duplicates)
+	}
+	stored, _ := store.ListTransactions(context.Background(), nil)
+	if len(stored) != 2 {
+		t.Errorf("Expected 2 stored transactions, got %d", len(stored))
+	}
+}
+
+func TestProcessFile_Error_undetected_csv_format(t *testing.T) {
+	// Setup
+	pdfProcessor := &docprocess.PDFProcessor{}
+	csvParsers := processor.NewDefaultRegistry()
+	mockDB := &mockStore{}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	csvPath := createTempFile(t, ".csv", []byte("Date,Description,Amount\n2025-02-24,Coffee,-3.50\n"))
+	pipeline := NewPipeline(pdfProcessor, csvParsers, nil, mockDB, logger)
+
+	// Execute
+	_, err := pipeline.processFile(context.Background(), csvPath, ProcessOptions{})
+
+	// Verify
+	if !errors.Is(err, processor.ErrFormatNotDetected) {
+		t.Errorf("Expected ErrFormatNotDetected, got %v", err)
+	}
 }
 
 func TestProcessFile_Error_unsupported_file_type(t *testing.T) {
 	// Setup
 	pdfProcessor := &docprocess.PDFProcessor{}
-	csvProcessor := &processor.SEBProcessor{}
+	csvParsers := processor.NewDefaultRegistry()
 	mockAI := &mockAIService{}
 	mockDB := &mockStore{}
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
@@ -158,7 +320,7 @@ func TestProcessFile_Error_unsupported_file_type(t *testing.T) {
 	txtPath := createTempFile(t, ".txt", []byte("test text content"))
 
 	// Create pipeline
-	pipeline := NewPipeline(pdfProcessor, csvProcessor, mockAI, mockDB, logger)
+	pipeline := NewPipeline(pdfProcessor, csvParsers, mockAI, mockDB, logger)
 
 	// Execute
 	_, err := pipeline.processFile(context.Background(), txtPath, ProcessOptions{})
@@ -176,13 +338,13 @@ func TestProcessFile_Error_unsupported_file_type(t *testing.T) {
 func TestProcessDocuments_Error_invalid_path(t *testing.T) {
 	// Setup
 	pdfProcessor := &docprocess.PDFProcessor{}
-	csvProcessor := &processor.SEBProcessor{}
+	csvParsers := processor.NewDefaultRegistry()
 	mockAI := &mockAIService{}
 	mockDB := &mockStore{}
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
 	// Create pipeline
-	pipeline := NewPipeline(pdfProcessor, csvProcessor, mockAI, mockDB, logger)
+	pipeline := NewPipeline(pdfProcessor, csvParsers, mockAI, mockDB, logger)
 
 	// Execute with non-existent path
 	_, err := pipeline.ProcessDocuments(context.Background(), "/path/does/not/exist", ProcessOptions{})
@@ -196,3 +358,265 @@ func TestProcessDocuments_Error_invalid_path(t *testing.T) {
 		t.Errorf("Expected error message to contain 'failed to access path', got '%s'", err.Error())
 	}
 }
+
+func TestProcessDocuments_Successfully_skip_processed_documents(t *testing.T) {
+	// Setup
+	pdfProcessor := &docprocess.PDFProcessor{}
+	csvParsers := processor.NewDefaultRegistry()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := db.NewMockStore()
+	pipeline := NewPipeline(pdfProcessor, csvParsers, nil, store, logger)
+
+	header := "Bokföringsdatum;Valutadatum;Verifikationsnummer;Text;Belopp;Saldo\n"
+	dir := t.TempDir()
+	writeStatement := func(name, rows string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(header+rows), 0600); err != nil {
+			t.Fatalf("Failed to write statement: %v", err)
+		}
+	}
+	statuses := func(results []ProcessingResult) map[string]string {
+		got := make(map[string]string)
+		for _, result := range results {
+			if result.Error != nil {
+				t.Fatalf("Unexpected error for %s: %v", result.FilePath, result.Error)
+			}
+			status := string(result.Status)
+			if result.Skipped {
+				status = "skipped"
+			}
+			got[filepath.Base(result.FilePath)] = status
+		}
+		return got
+	}
+
+	writeStatement("januari.csv", "2025-01-24;2025-01-24;5490990001;ICA Kvantum;-1000.000;2814.160\n")
+	writeStatement("februari.csv", "2025-02-25;2025-02-25;5490990005;Lön;25000.000;27814.160\n")
+	first, err := pipeline.ProcessDocuments(context.Background(), dir, ProcessOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Change one statement and add a copy of the other under a new name
+	writeStatement("februari.csv", "2025-02-25;2025-02-25;5490990005;Lön;25000.000;27814.160\n"+
+		"2025-02-26;2025-02-26;5490990006;Hyra;-9000.000;18814.160\n")
+	writeStatement("januari-kopia.csv", "2025-01-24;2025-01-24;5490990001;ICA Kvantum;-1000.000;2814.160\n")
+	second, err := pipeline.ProcessDocuments(context.Background(), dir, ProcessOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	forced, err := pipeline.ProcessDocuments(context.Background(), filepath.Join(dir, "januari.csv"), ProcessOptions{Force: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Verify
+	tests := []struct {
+		name    string
+		results []ProcessingResult
+		want    map[string]string
+	}{
+		{
+			name:    "Successfully_process_new_documents",
+			results: first,
+			want:    map[string]string{"januari.csv": "new", "februari.csv": "new"},
+		},
+		{
+			name:    "Successfully_skip_unchanged_documents",
+			results: second,
+			want:    map[string]string{"januari.csv": "skipped", "januari-kopia.csv": "skipped", "februari.csv": "changed"},
+		},
+		{
+			name:    "Successfully_reprocess_with_force",
+			results: forced,
+			want:    map[string]string{"januari.csv": "unchanged"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statuses(tt.results)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d results, got %v", len(tt.want), got)
+			}
+			for name, want := range tt.want {
+				if got[name] != want {
+					t.Errorf("Expected %s to be %s, got %s", name, want, got[name])
+				}
+			}
+		})
+	}
+
+	stored, _ := store.ListTransactions(context.Background(), nil)
+	if len(stored) != 3 {
+		t.Errorf("Expected 3 stored transactions, got %d", len(stored))
+	}
+}
+
+func TestProcessFile_Error_roll_back_document_on_store_failure(t *testing.T) {
+	// Setup
+	pdfProcessor := &docprocess.PDFProcessor{}
+	csvParsers := processor.NewDefaultRegistry()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := &failingStore{MockStore: db.NewMockStore(), failDescription: "Hyra"}
+
+	csvPath := createTempFile(t, ".csv", []byte("Bokföringsdatum;Valutadatum;Verifikationsnummer;Text;Belopp;Saldo\n"+
+		"2025-02-24;2025-02-22;5490990004;ICA Kvantum;-1000.000;2814.160\n"+
+		"2025-02-25;2025-02-25;5490990005;Hyra;-9000.000;-6185.840\n"+
+		"2025-02-25;2025-02-25;5490990006;Lön;25000.000;18814.160\n"+
+		"2025-02-26;2025-02-26;5490990007;Swish;abc;18814.160\n"))
+	pipeline := NewPipeline(pdfProcessor, csvParsers, nil, store, logger)
+
+	// Execute
+	result, err := pipeline.processFile(context.Background(), csvPath, ProcessOptions{})
+
+	// Verify
+	if err == nil || !strings.Contains(err.Error(), "document rolled back") {
+		t.Fatalf("Expected rolled back document error, got %v", err)
+	}
+	if result.TransactionsFound != 3 || result.Stored != 0 || result.Failed != 1 {
+		t.Errorf("Expected 3 found, 0 stored and 1 failed, got %d found, %d stored and %d failed",
+			result.TransactionsFound, result.Stored, result.Failed)
+	}
+	if len(result.StoreErrors) != 1 || result.StoreErrors[0].Row != 2 || result.StoreErrors[0].Description != "Hyra" {
+		t.Errorf("Expected a store error for row 2 (Hyra), got %v", result.StoreErrors)
+	}
+	if len(result.Rejected) != 1 || result.Rejected[0].Line != 5 {
+		t.Errorf("Expected the parser to reject line 5, got %v", result.Rejected)
+	}
+
+	stored, _ := store.ListTransactions(context.Background(), nil)
+	if len(stored) != 0 {
+		t.Errorf("Expected no stored transactions after rollback, got %d", len(stored))
+	}
+	batches, _ := store.ListImportBatches(context.Background())
+	if len(batches) != 0 {
+		t.Errorf("Expected no import batch after rollback, got %d", len(batches))
+	}
+}
+
+// cancellingStore is a db.MockStore that cancels the run when the first document is stored
+type cancellingStore struct {
+	*db.MockStore
+	cancel context.CancelFunc
+}
+
+func (s *cancellingStore) WithTransaction(ctx context.Context, fn func(store db.Store) error) error {
+	err := s.MockStore.WithTransaction(ctx, fn)
+	s.cancel()
+	return err
+}
+
+func createStatementDir(t *testing.T, count int) []string {
+	t.Helper()
+	dir := t.TempDir()
+	var names []string
+	for i := 1; i <= count; i++ {
+		name := fmt.Sprintf("statement-%02d.csv", i)
+		content := fmt.Sprintf("Bokföringsdatum;Valutadatum;Verifikationsnummer;Text;Belopp;Saldo\n"+
+			"2025-02-%02d;2025-02-%02d;54909900%02d;ICA Kvantum;-%d.000;2814.160\n", i, i, i, i*10)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write statement: %v", err)
+		}
+		names = append(names, name)
+	}
+	return append([]string{dir}, names...)
+}
+
+func TestProcessDocuments_Successfully_process_in_parallel_in_walk_order(t *testing.T) {
+	// Setup
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := db.NewMockStore()
+	pipeline := NewPipeline(&docprocess.PDFProcessor{}, processor.NewDefaultRegistry(), nil, store, logger)
+	dir := createStatementDir(t, 8)
+
+	// Execute
+	results, err := pipeline.ProcessDocuments(context.Background(), dir[0], ProcessOptions{Workers: 4})
+
+	// Verify
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 8 {
+		t.Fatalf("Expected 8 results, got %d", len(results))
+	}
+	for i, result := range results {
+		if got := filepath.Base(result.FilePath); got != dir[i+1] {
+			t.Errorf("Expected result %d for %s, got %s", i, dir[i+1], got)
+		}
+		if result.Error != nil || result.Stored != 1 {
+			t.Errorf("Expected %s to store 1 transaction, got %d (%v)", dir[i+1], result.Stored, result.Error)
+		}
+	}
+	stored, _ := store.ListTransactions(context.Background(), nil)
+	if len(stored) != 8 {
+		t.Errorf("Expected 8 stored transactions, got %d", len(stored))
+	}
+}
+
+func TestProcessDocuments_Error_cancelled_context(t *testing.T) {
+	// Setup
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := &cancellingStore{MockStore: db.NewMockStore(), cancel: cancel}
+	pipeline := NewPipeline(&docprocess.PDFProcessor{}, processor.NewDefaultRegistry(), nil, store, logger)
+	dir := createStatementDir(t, 4)
+
+	// Execute
+	results, err := pipeline.ProcessDocuments(ctx, dir[0], ProcessOptions{Workers: 1})
+
+	// Verify
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+	if results[0].Error != nil || results[0].Stored != 1 {
+		t.Errorf("Expected the first document to be stored, got %d (%v)", results[0].Stored, results[0].Error)
+	}
+	for _, result := range results[1:] {
+		if !errors.Is(result.Error, context.Canceled) {
+			t.Errorf("Expected %s to be cancelled, got %v", filepath.Base(result.FilePath), result.Error)
+		}
+	}
+}
+
+func TestProcessFile_Successfully_store_dates_and_balances(t *testing.T) {
+	// Setup
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := db.NewMockStore()
+	pipeline := NewPipeline(&docprocess.PDFProcessor{}, processor.NewDefaultRegistry(), nil, store, logger)
+
+	// Newest first as SEB exports it, with the row between Lön and ICA Kvantum missing
+	csvPath := createTempFile(t, ".csv", []byte("Bokföringsdatum;Valutadatum;Verifikationsnummer;Text;Belopp;Saldo\n"+
+		"2025-02-25;2025-02-25;5490990006;Lön;25000.000;26814.160\n"+
+		"2025-02-24;2025-02-22;5490990004;ICA Kvantum;-1000.000;2814.160\n"+
+		"2025-02-21;2025-02-21;5490990003;Swish;200.000;3814.160\n"))
+
+	// Execute
+	result, err := pipeline.processFile(context.Background(), csvPath, ProcessOptions{})
+
+	// Verify
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.BalanceGaps) != 1 || result.BalanceGaps[0].Description != "Lön" ||
+		!result.BalanceGaps[0].Difference().Equal(decimal.NewFromInt(-1000)) {
+		t.Errorf("Expected a balance gap of -1000 at Lön, got %v", result.BalanceGaps)
+	}
+
+	stored, _ := store.ListTransactions(context.Background(), nil)
+	byDescription := make(map[string]db.Transaction)
+	for _, tx := range stored {
+		byDescription[tx.Description] = tx
+	}
+	tx := byDescription["ICA Kvantum"]
+	if !tx.Date.Equal(time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC)) ||
+		!tx.ValueDate.Equal(time.Date(2025, 2, 22, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected booking date 2025-02-24 and value date 2025-02-22, got %v and %v", tx.Date, tx.ValueDate)
+	}
+	if !tx.Balance.Valid || !tx.Balance.Decimal.Equal(decimal.RequireFromString("2814.16")) {
+		t.Errorf("Expected balance 2814.16, got %v", tx.Balance)
+	}
+}
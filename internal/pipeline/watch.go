@@ -0,0 +1,257 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/lindehoff/Budget-Assist/internal/db"
+)
+
+// Subfolders of a watched folder that documents are moved to once processed
+const (
+	ProcessedFolder = "processed"
+	FailedFolder    = "failed"
+)
+
+// DefaultWatchDebounce is how long a file must stay unchanged before it is processed
+const DefaultWatchDebounce = 2 * time.Second
+
+// WatchOptions configures how a folder is watched
+type WatchOptions struct {
+	Process  ProcessOptions
+	Debounce time.Duration     // How long a file must stay unchanged before it is processed
+	OnResult func(WatchResult) // Called with the outcome of each file
+}
+
+// WatchResult is the outcome of a file picked up from a watched folder
+type WatchResult struct {
+	Path    string             // Where the file was found
+	MovedTo string             // Where the file was moved after processing, if it was moved
+	Results []ProcessingResult // One for each document, several for a ZIP archive or email
+	Error   error              // Set when the file or any of its documents failed
+}
+
+// pendingFile is a file in a watched folder that is waiting to stop changing
+type pendingFile struct {
+	size    int64
+	modTime time.Time
+	changed time.Time
+}
+
+// Watch processes the documents that appear in dir until ctx is cancelled.
+// Documents already in the folder are processed first. A document is processed
+// once it has not changed for opts.Debounce, so files that are still being
+// written or copied are left alone, and is then moved to the processed or
+// failed subfolder. Files with unsupported extensions and hidden files, such as
+// the temporary files of a download in progress, are ignored.
+func (p *Pipeline) Watch(ctx context.Context, dir string, opts WatchOptions) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to access path: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", dir)
+	}
+	for _, folder := range []string{ProcessedFolder, FailedFolder} {
+		if err := os.MkdirAll(filepath.Join(dir, folder), 0755); err != nil {
+			return fmt.Errorf("failed to create %s folder: %w", folder, err)
+		}
+	}
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultWatchDebounce
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(dir); err != nil {
+		return fmt.Errorf("failed to watch directory: %w", err)
+	}
+
+	pending := make(map[string]*pendingFile)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+	for _, entry := range entries {
+		p.trackWatchedFile(pending, dir, filepath.Join(dir, entry.Name()), time.Now())
+	}
+
+	interval := opts.Debounce / 4
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	p.logger.Info("watching folder", "path", dir, "debounce", opts.Debounce)
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("stopped watching folder", "path", dir)
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			switch {
+			case event.Has(fsnotify.Create), event.Has(fsnotify.Write):
+				p.trackWatchedFile(pending, dir, event.Name, time.Now())
+			case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+				delete(pending, event.Name)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			p.logger.Error("folder watcher error", "path", dir, "error", err)
+		case now := <-ticker.C:
+			for _, path := range settledFiles(pending, now, opts.Debounce) {
+				if ctx.Err() != nil {
+					break
+				}
+				p.processWatchedFile(ctx, dir, path, opts)
+			}
+		}
+	}
+}
+
+// trackWatchedFile starts or restarts the debounce of a supported document in dir
+func (p *Pipeline) trackWatchedFile(pending map[string]*pendingFile, dir, path string, now time.Time) {
+	if filepath.Dir(path) != dir || strings.HasPrefix(filepath.Base(path), ".") {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return
+	}
+	if !isSupportedDocument(path) {
+		p.logger.Debug("ignoring unsupported file", "path", path)
+		return
+	}
+	pending[path] = &pendingFile{size: info.Size(), modTime: info.ModTime(), changed: now}
+}
+
+// settledFiles removes and returns the pending files, in name order, that have
+// not changed for the debounce period
+func settledFiles(pending map[string]*pendingFile, now time.Time, debounce time.Duration) []string {
+	var settled []string
+	for path, file := range pending {
+		info, err := os.Stat(path)
+		if err != nil {
+			delete(pending, path)
+			continue
+		}
+		// Not every writer triggers events, so the size and modification time are compared as well
+		if info.Size() != file.size || !info.ModTime().Equal(file.modTime) {
+			file.size, file.modTime, file.changed = info.Size(), info.ModTime(), now
+			continue
+		}
+		if now.Sub(file.changed) >= debounce {
+			delete(pending, path)
+			settled = append(settled, path)
+		}
+	}
+	sort.Strings(settled)
+	return settled
+}
+
+// processWatchedFile runs a document through the pipeline as a job of its own
+// and moves it to the processed or failed subfolder. A ZIP archive or email is
+// moved to the failed subfolder when any of its documents failed. A file the
+// run was cancelled on stays in the folder, to be processed on the next start.
+func (p *Pipeline) processWatchedFile(ctx context.Context, dir, path string, opts WatchOptions) {
+	result := WatchResult{Path: path}
+	job, err := p.CreateJob(ctx, path, opts.Process)
+	if err == nil {
+		result.Results, err = p.RunJob(ctx, job, opts.Process)
+		for _, processed := range result.Results {
+			if err == nil && processed.Error != nil {
+				err = processed.Error
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	result.Error = err
+
+	folder := ProcessedFolder
+	if err != nil {
+		folder = FailedFolder
+	}
+	target := uniquePath(filepath.Join(dir, folder, filepath.Base(path)))
+	if err := os.Rename(path, target); err != nil {
+		p.logger.Error("failed to move watched file", "path", path, "target", target, "error", err)
+		if result.Error == nil {
+			result.Error = fmt.Errorf("failed to move file to %s: %w", folder, err)
+		}
+	} else {
+		result.MovedTo = target
+		if job != nil {
+			p.moveJob(ctx, job, target)
+		}
+	}
+
+	for _, processed := range result.Results {
+		switch {
+		case processed.Error != nil:
+			p.logger.Error("failed to process watched document", "path", processed.FilePath, "error", processed.Error)
+		case processed.Skipped:
+			p.logger.Info("skipped unchanged watched document", "path", processed.FilePath, "batch", processed.PreviousBatchID)
+		default:
+			p.logger.Info("processed watched document", "path", processed.FilePath,
+				"transactions", processed.TransactionsFound,
+				"stored", processed.Stored,
+				"batch", processed.BatchID)
+		}
+	}
+	if result.Error != nil {
+		p.logger.Error("failed to process watched file", "path", path, "moved_to", result.MovedTo, "error", result.Error)
+	} else {
+		p.logger.Info("processed watched file", "path", path, "documents", len(result.Results), "moved_to", result.MovedTo)
+	}
+	if opts.OnResult != nil {
+		opts.OnResult(result)
+	}
+}
+
+// moveJob points the job of a watched file at the subfolder the file was moved
+// to, so that a failed job can still be resumed
+func (p *Pipeline) moveJob(ctx context.Context, job *db.ProcessingJob, path string) {
+	job.Path = path
+	if err := p.store.UpdateProcessingJob(ctx, job); err != nil {
+		p.logger.Error("failed to update processing job", "job", job.ID, "error", err)
+	}
+	for i := range job.Files {
+		job.Files[i].Path = path
+		if err := p.store.UpdateJobFile(ctx, &job.Files[i]); err != nil {
+			p.logger.Error("failed to update job file", "job", job.ID, "error", err)
+		}
+	}
+}
+
+// uniquePath returns path, or a variant with a numbered suffix when path already exists
+func uniquePath(path string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	candidate := path
+	for i := 2; ; i++ {
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+}
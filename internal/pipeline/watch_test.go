@@ -0,0 +1,153 @@
+package pipeline
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lindehoff/Budget-Assist/internal/db"
+	"github.com/lindehoff/Budget-Assist/internal/docprocess"
+	"github.com/lindehoff/Budget-Assist/internal/processor"
+)
+
+func TestWatch_Successfully_process_dropped_documents(t *testing.T) {
+	// Setup
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := db.NewMockStore()
+	pipeline := NewPipeline(&docprocess.PDFProcessor{}, processor.NewDefaultRegistry(), nil, store, logger)
+	dir := t.TempDir()
+	header := "Bokföringsdatum;Valutadatum;Verifikationsnummer;Text;Belopp;Saldo\n"
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	writeFile("januari.csv", header+"2025-01-24;2025-01-24;5490990001;ICA Kvantum;-1000.000;2814.160\n")
+	writeFile("notes.txt", "not a statement")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	outcomes := make(chan WatchResult, 10)
+	done := make(chan error, 1)
+
+	// Execute
+	go func() {
+		done <- pipeline.Watch(ctx, dir, WatchOptions{
+			Debounce: 50 * time.Millisecond,
+			OnResult: func(result WatchResult) { outcomes <- result },
+		})
+	}()
+	waitFor := func() WatchResult {
+		t.Helper()
+		select {
+		case result := <-outcomes:
+			return result
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a watched file")
+			return WatchResult{}
+		}
+	}
+
+	first := waitFor()
+	writeFile("februari.csv", header+"2025-02-25;2025-02-25;5490990005;Lön;25000.000;27814.160\n")
+	second := waitFor()
+	writeFile("broken.csv", "not;a;known;format\n1;2;3;4\n")
+	third := waitFor()
+	writeFile("januari-kopia.csv", header+"2025-01-24;2025-01-24;5490990001;ICA Kvantum;-1000.000;2814.160\n")
+	fourth := waitFor()
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Verify
+	tests := []struct {
+		name       string
+		result     WatchResult
+		wantFile   string
+		wantFolder string
+		wantError  bool
+		wantSkip   bool
+	}{
+		{name: "Successfully_process_existing_document", result: first, wantFile: "januari.csv", wantFolder: ProcessedFolder},
+		{name: "Successfully_process_new_document", result: second, wantFile: "februari.csv", wantFolder: ProcessedFolder},
+		{name: "Error_move_unreadable_document_to_failed", result: third, wantFile: "broken.csv", wantFolder: FailedFolder, wantError: true},
+		{name: "Successfully_skip_processed_content", result: fourth, wantFile: "januari-kopia.csv", wantFolder: ProcessedFolder, wantSkip: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filepath.Base(tt.result.Path); got != tt.wantFile {
+				t.Fatalf("Expected %s, got %s", tt.wantFile, got)
+			}
+			if (tt.result.Error != nil) != tt.wantError {
+				t.Errorf("Expected error %v, got %v", tt.wantError, tt.result.Error)
+			}
+			if len(tt.result.Results) != 1 {
+				t.Fatalf("Expected 1 result, got %d", len(tt.result.Results))
+			}
+			if tt.result.Results[0].Skipped != tt.wantSkip {
+				t.Errorf("Expected skipped %v, got %v", tt.wantSkip, tt.result.Results[0].Skipped)
+			}
+			if _, err := os.Stat(filepath.Join(dir, tt.wantFolder, tt.wantFile)); err != nil {
+				t.Errorf("Expected %s in %s: %v", tt.wantFile, tt.wantFolder, err)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("Expected unsupported file to be left alone: %v", err)
+	}
+	stored, _ := store.ListTransactions(context.Background(), nil)
+	if len(stored) != 2 {
+		t.Errorf("Expected 2 stored transactions, got %d", len(stored))
+	}
+}
+
+func TestSettledFiles_Successfully_wait_for_unchanged_file(t *testing.T) {
+	path := createTempFile(t, ".csv", []byte("Bokföringsdatum;Valutadatum"))
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	start := time.Now()
+	pending := map[string]*pendingFile{path: {size: info.Size(), modTime: info.ModTime(), changed: start}}
+
+	if got := settledFiles(pending, start.Add(time.Second), 2*time.Second); len(got) != 0 {
+		t.Fatalf("Expected no settled files within the debounce period, got %v", got)
+	}
+
+	// A file that grows restarts the debounce period
+	if err := os.WriteFile(path, []byte("Bokföringsdatum;Valutadatum;Text"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if got := settledFiles(pending, start.Add(3*time.Second), 2*time.Second); len(got) != 0 {
+		t.Fatalf("Expected the changed file to wait, got %v", got)
+	}
+	if got := settledFiles(pending, start.Add(5*time.Second), 2*time.Second); len(got) != 1 || got[0] != path {
+		t.Fatalf("Expected %s to be settled, got %v", path, got)
+	}
+	if len(pending) != 0 {
+		t.Errorf("Expected settled file to no longer be pending, got %d", len(pending))
+	}
+}
+
+func TestUniquePath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "januari.csv")
+	if got := uniquePath(path); got != path {
+		t.Errorf("uniquePath() = %s, want %s", got, path)
+	}
+	for _, name := range []string{"januari.csv", "januari-2.csv"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	if got, want := uniquePath(path), filepath.Join(dir, "januari-3.csv"); got != want {
+		t.Errorf("uniquePath() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,109 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// NumberFormat describes how amounts are written in a document. The zero
+// value infers the separators from each amount, see ParseAmount.
+type NumberFormat struct {
+	DecimalSeparator   string // "," or "."
+	ThousandsSeparator string // e.g. " ", "." or ""
+}
+
+// amountSpaces are the characters exporters use to group thousands
+var amountSpaces = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "", "'", "")
+
+// currencyAffixes are currency markers stripped from either end of an amount
+var currencyAffixes = []string{"SEK", "EUR", "USD", "NOK", "DKK", "GBP", "kr", "€", "$", "£"}
+
+// ParseAmount parses a localized amount such as "1 234,50", "-1,234.50",
+// "1.234,50 kr", "(12,00)" or "12,00-". Unicode minus signs and
+// (non-breaking) space thousand separators are accepted. When the format
+// leaves both separators empty they are inferred from the amount itself.
+func ParseAmount(raw string, format NumberFormat) (decimal.Decimal, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return decimal.Decimal{}, fmt.Errorf("empty amount")
+	}
+
+	value, negative := stripAmountSign(value)
+	if format.DecimalSeparator == "" && format.ThousandsSeparator == "" {
+		format = inferNumberFormat(value)
+	}
+	if format.ThousandsSeparator != "" {
+		value = strings.ReplaceAll(value, format.ThousandsSeparator, "")
+	}
+	if format.DecimalSeparator != "" && format.DecimalSeparator != "." {
+		value = strings.ReplaceAll(value, format.DecimalSeparator, ".")
+	}
+	value = amountSpaces.Replace(value)
+
+	amount, err := decimal.NewFromString(value)
+	if err != nil || strings.ContainsAny(value, "eE") {
+		return decimal.Decimal{}, fmt.Errorf("invalid amount format '%s'", raw)
+	}
+	if negative {
+		amount = amount.Neg()
+	}
+	return amount, nil
+}
+
+// stripAmountSign removes currency markers and the sign from an amount and
+// reports whether it was negative. Besides a leading "-" banks write negative
+// amounts with a trailing "-", in parentheses or with the unicode minus sign.
+func stripAmountSign(value string) (string, bool) {
+	value = strings.ReplaceAll(value, "\u2212", "-")
+	for _, affix := range currencyAffixes {
+		if strings.HasSuffix(value, affix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, affix))
+			break
+		}
+		if strings.HasPrefix(value, affix) {
+			value = strings.TrimSpace(strings.TrimPrefix(value, affix))
+			break
+		}
+	}
+
+	negative := false
+	switch {
+	case strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")"):
+		value, negative = value[1:len(value)-1], true
+	case strings.HasSuffix(value, "-"):
+		value, negative = strings.TrimSuffix(value, "-"), true
+	case strings.HasPrefix(value, "-"):
+		value, negative = value[1:], true
+	case strings.HasPrefix(value, "+"):
+		value = value[1:]
+	}
+	return strings.TrimSpace(value), negative
+}
+
+// inferNumberFormat guesses the separators of an unsigned amount. When both
+// "," and "." occur the last one is the decimal separator. A separator that
+// occurs once is taken as the decimal separator, as Swedish exports write
+// "1234,50"; one that repeats, as in "1.234.567", groups thousands.
+func inferNumberFormat(value string) NumberFormat {
+	lastComma := strings.LastIndex(value, ",")
+	lastDot := strings.LastIndex(value, ".")
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		if lastComma > lastDot {
+			return NumberFormat{DecimalSeparator: ",", ThousandsSeparator: "."}
+		}
+		return NumberFormat{DecimalSeparator: ".", ThousandsSeparator: ","}
+	case lastComma >= 0:
+		if strings.Count(value, ",") > 1 {
+			return NumberFormat{DecimalSeparator: ".", ThousandsSeparator: ","}
+		}
+		return NumberFormat{DecimalSeparator: ","}
+	case lastDot >= 0:
+		if strings.Count(value, ".") > 1 {
+			return NumberFormat{DecimalSeparator: ",", ThousandsSeparator: "."}
+		}
+	}
+	return NumberFormat{DecimalSeparator: "."}
+}
@@ -0,0 +1,102 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestParseAmount_Successfully_parse_localized_amounts(t *testing.T) {
+	swedish := NumberFormat{DecimalSeparator: ",", ThousandsSeparator: " "}
+	tests := []struct {
+		name   string
+		input  string
+		format NumberFormat
+		want   string
+	}{
+		{name: "Successfully_parse_space_thousands", input: "1 234,50", format: swedish, want: "1234.50"},
+		{name: "Successfully_parse_nbsp_thousands", input: "-1\u00a0234,50", format: swedish, want: "-1234.50"},
+		{name: "Successfully_parse_narrow_nbsp_thousands", input: "12\u202f345,00", format: swedish, want: "12345"},
+		{name: "Successfully_parse_unicode_minus", input: "\u2212250,00", format: swedish, want: "-250"},
+		{name: "Successfully_parse_trailing_minus", input: "250,00-", format: swedish, want: "-250"},
+		{name: "Successfully_parse_parentheses", input: "(250,00)", format: swedish, want: "-250"},
+		{name: "Successfully_parse_currency_suffix", input: "-1 234,50 kr", format: swedish, want: "-1234.50"},
+		{name: "Successfully_parse_currency_code", input: "99,90 SEK", format: swedish, want: "99.90"},
+		{name: "Successfully_infer_comma_decimal", input: "-1 234,50", want: "-1234.50"},
+		{name: "Successfully_infer_dot_decimal", input: "-1000.000", want: "-1000"},
+		{name: "Successfully_infer_dot_thousands", input: "1.234,50", want: "1234.50"},
+		{name: "Successfully_infer_comma_thousands", input: "1,234.50", want: "1234.50"},
+		{name: "Successfully_infer_repeated_dot_thousands", input: "1.234.567", want: "1234567"},
+		{name: "Successfully_infer_integer", input: "+42", want: "42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAmount(tt.input, tt.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := decimal.RequireFromString(tt.want); !got.Equal(want) {
+				t.Errorf("ParseAmount(%q) = %v, want %v", tt.input, got, want)
+			}
+		})
+	}
+}
+
+func TestParseAmount_error_validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "Parse_error_empty", input: " ", wantErr: "empty amount"},
+		{name: "Parse_error_text", input: "abc", wantErr: "invalid amount format 'abc'"},
+		{name: "Parse_error_repeated_decimal", input: "1,2,3.4.5", wantErr: "invalid amount format"},
+		{name: "Parse_error_exponent", input: "1e5", wantErr: "invalid amount format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseAmount(tt.input, NumberFormat{})
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		layouts []string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "Successfully_parse_iso_date", input: "2025-02-24", want: time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC)},
+		{name: "Successfully_parse_slash_date", input: "2025/02/24", want: time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC)},
+		{name: "Successfully_parse_compact_date", input: "20250224", want: time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC)},
+		{name: "Successfully_parse_european_date", input: " 24.02.2025 ", want: time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC)},
+		{name: "Successfully_parse_with_layout", input: "02/24/2025", layouts: []string{"01/02/2006"}, want: time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC)},
+		{name: "Parse_error_layout_mismatch", input: "2025-02-24", layouts: []string{"02.01.2006"}, wantErr: true},
+		{name: "Parse_error_invalid_date", input: "2025-02-30", wantErr: true},
+		{name: "Parse_error_empty", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDate(tt.input, tt.layouts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("ParseDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
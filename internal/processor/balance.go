@@ -0,0 +1,242 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Statement balance types
+const (
+	BalanceOpening = "opening"
+	BalanceClosing = "closing"
+)
+
+// StatementBalance is a balance stated in a bank statement
+type StatementBalance struct {
+	Date     time.Time
+	Amount   decimal.Decimal
+	Account  string
+	Currency string
+	Type     string // BalanceOpening or BalanceClosing
+}
+
+// BalanceProvider is implemented by parsers that read opening and closing
+// balances from the statement, so that an import can be reconciled
+type BalanceProvider interface {
+	// Balances returns the statement balances read by the last ProcessDocument call
+	Balances() []StatementBalance
+}
+
+// BalanceCheck is the result of comparing the transactions of one account with its statement balances
+type BalanceCheck struct {
+	Opening     decimal.Decimal
+	Closing     decimal.Decimal
+	Sum         decimal.Decimal
+	Discrepancy decimal.Decimal // Closing - (Opening + Sum)
+	Account     string
+	Currency    string
+}
+
+// OK reports whether the statement balances agree with the transactions
+func (c BalanceCheck) OK() bool {
+	return c.Discrepancy.IsZero()
+}
+
+// String returns a short human readable summary of the check
+func (c BalanceCheck) String() string {
+	status := "OK"
+	if !c.OK() {
+		status = fmt.Sprintf("discrepancy %s", c.Discrepancy.StringFixed(2))
+	}
+	return fmt.Sprintf("%s: opening %s + transactions %s = closing %s %s (%s)",
+		c.Account, c.Opening.StringFixed(2), c.Sum.StringFixed(2), c.Closing.StringFixed(2), c.Currency, status)
+}
+
+// CheckBalances verifies that opening balance plus the sum of the transactions
+// equals the closing balance for each account with both balances stated.
+// Transactions are attributed to an account through RawData["Account"]; when
+// the statement covers a single account all transactions belong to it.
+func CheckBalances(transactions []Transaction, balances []StatementBalance) []BalanceCheck {
+	type pair struct {
+		opening, closing *StatementBalance
+	}
+	accounts := make(map[string]*pair)
+	for i := range balances {
+		b := &balances[i]
+		p, ok := accounts[b.Account]
+		if !ok {
+			p = &pair{}
+			accounts[b.Account] = p
+		}
+		switch b.Type {
+		case BalanceOpening:
+			if p.opening == nil || b.Date.Before(p.opening.Date) {
+				p.opening = b
+			}
+		case BalanceClosing:
+			// Later balances win on equal dates, so a final balance replaces an intermediate one
+			if p.closing == nil || !b.Date.Before(p.closing.Date) {
+				p.closing = b
+			}
+		}
+	}
+
+	var checks []BalanceCheck
+	for account, p := range accounts {
+		if p.opening == nil || p.closing == nil {
+			continue
+		}
+		sum := decimal.Zero
+		for _, tx := range transactions {
+			if len(accounts) > 1 {
+				if txAccount, _ := tx.RawData["Account"].(string); txAccount != account {
+					continue
+				}
+			}
+			sum = sum.Add(tx.Amount)
+		}
+		checks = append(checks, BalanceCheck{
+			Account:     account,
+			Currency:    p.closing.Currency,
+			Opening:     p.opening.Amount,
+			Closing:     p.closing.Amount,
+			Sum:         sum,
+			Discrepancy: p.closing.Amount.Sub(p.opening.Amount.Add(sum)),
+		})
+	}
+
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].Account < checks[j].Account
+	})
+	return checks
+}
+
+// BalanceGap is a row whose stated balance does not follow from the balance
+// of the row before it, which means rows are missing or corrupted in between
+type BalanceGap struct {
+	Date        time.Time
+	Description string
+	Expected    decimal.Decimal // Balance of the previous row plus the amount of this row
+	Balance     decimal.Decimal // Balance stated on this row
+}
+
+// Difference returns the amount the stated balance is off by, which is the
+// sum of the amounts of the missing rows
+func (g BalanceGap) Difference() decimal.Decimal {
+	return g.Balance.Sub(g.Expected)
+}
+
+// String returns a short human readable summary of the gap
+func (g BalanceGap) String() string {
+	return fmt.Sprintf("%s %s: balance %s, expected %s (difference %s)",
+		g.Date.Format("2006-01-02"), g.Description,
+		g.Balance.StringFixed(2), g.Expected.StringFixed(2), g.Difference().StringFixed(2))
+}
+
+// CheckRunningBalance verifies that the running balance stated on consecutive
+// rows agrees with their amounts. Rows may be listed oldest or newest first;
+// the order is taken from the booking dates and, when all rows share a date,
+// from the order the balances agree with. Rows without a balance break the
+// chain, so the check starts over at the next row with a balance.
+func CheckRunningBalance(transactions []Transaction) []BalanceGap {
+	rows := transactions
+	if newestFirst(transactions) {
+		rows = make([]Transaction, len(transactions))
+		for i, tx := range transactions {
+			rows[len(transactions)-1-i] = tx
+		}
+	}
+
+	var gaps []BalanceGap
+	for i := 1; i < len(rows); i++ {
+		prev, cur := rows[i-1], rows[i]
+		if !prev.Balance.Valid || !cur.Balance.Valid {
+			continue
+		}
+		expected := prev.Balance.Decimal.Add(cur.Amount)
+		if !expected.Equal(cur.Balance.Decimal) {
+			gaps = append(gaps, BalanceGap{
+				Date:        cur.Date,
+				Description: cur.Description,
+				Expected:    expected,
+				Balance:     cur.Balance.Decimal,
+			})
+		}
+	}
+	return gaps
+}
+
+// RunningBalances derives the opening and closing balances of a statement from
+// the running balance stated on its rows: the opening balance is the balance
+// before the oldest row and the closing balance the one after the newest row.
+// Nil is returned when no row states a balance.
+func RunningBalances(transactions []Transaction) []StatementBalance {
+	rows := transactions
+	if newestFirst(transactions) {
+		rows = make([]Transaction, len(transactions))
+		for i, tx := range transactions {
+			rows[len(transactions)-1-i] = tx
+		}
+	}
+
+	var first, last *Transaction
+	for i := range rows {
+		if !rows[i].Balance.Valid {
+			continue
+		}
+		if first == nil {
+			first = &rows[i]
+		}
+		last = &rows[i]
+	}
+	if first == nil {
+		return nil
+	}
+
+	account, _ := first.RawData["Account"].(string)
+	return []StatementBalance{
+		{
+			Date:     first.Date,
+			Amount:   first.Balance.Decimal.Sub(first.Amount),
+			Account:  account,
+			Currency: first.Currency,
+			Type:     BalanceOpening,
+		},
+		{
+			Date:     last.Date,
+			Amount:   last.Balance.Decimal,
+			Account:  account,
+			Currency: last.Currency,
+			Type:     BalanceClosing,
+		},
+	}
+}
+
+// newestFirst reports whether the rows are listed with the latest booking first
+func newestFirst(transactions []Transaction) bool {
+	if len(transactions) < 2 {
+		return false
+	}
+	first, last := transactions[0].Date, transactions[len(transactions)-1].Date
+	if !first.Equal(last) {
+		return first.After(last)
+	}
+
+	var ascending, descending int
+	for i := 1; i < len(transactions); i++ {
+		prev, cur := transactions[i-1], transactions[i]
+		if !prev.Balance.Valid || !cur.Balance.Valid {
+			continue
+		}
+		if prev.Balance.Decimal.Add(cur.Amount).Equal(cur.Balance.Decimal) {
+			ascending++
+		}
+		if cur.Balance.Decimal.Add(prev.Amount).Equal(prev.Balance.Decimal) {
+			descending++
+		}
+	}
+	return descending > ascending
+}
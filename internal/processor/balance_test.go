@@ -0,0 +1,152 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCheckRunningBalance(t *testing.T) {
+	row := func(day int, description, amount, balance string) Transaction {
+		tx := Transaction{
+			Date:        time.Date(2025, 2, day, 0, 0, 0, 0, time.UTC),
+			Description: description,
+			Amount:      decimal.RequireFromString(amount),
+		}
+		if balance != "" {
+			tx.Balance = decimal.NewNullDecimal(decimal.RequireFromString(balance))
+		}
+		return tx
+	}
+
+	tests := []struct {
+		name     string
+		rows     []Transaction
+		wantGaps []string
+	}{
+		{
+			name: "Successfully_check_oldest_first",
+			rows: []Transaction{
+				row(24, "ICA", "-100.00", "900.00"),
+				row(25, "Lön", "25000.00", "25900.00"),
+				row(26, "Hyra", "-8000.00", "17900.00"),
+			},
+		},
+		{
+			name: "Successfully_check_newest_first",
+			rows: []Transaction{
+				row(26, "Hyra", "-8000.00", "17900.00"),
+				row(25, "Lön", "25000.00", "25900.00"),
+				row(24, "ICA", "-100.00", "900.00"),
+			},
+		},
+		{
+			name: "Successfully_check_rows_on_the_same_day_newest_first",
+			rows: []Transaction{
+				row(24, "Coop", "-50.00", "850.00"),
+				row(24, "ICA", "-100.00", "900.00"),
+				row(24, "Swish", "200.00", "1000.00"),
+			},
+		},
+		{
+			name: "Successfully_restart_after_row_without_balance",
+			rows: []Transaction{
+				row(24, "ICA", "-100.00", "900.00"),
+				row(25, "Kortköp", "-40.00", ""),
+				row(26, "Coop", "-50.00", "810.00"),
+			},
+		},
+		{
+			name: "Error_missing_row",
+			rows: []Transaction{
+				row(24, "ICA", "-100.00", "900.00"),
+				row(26, "Hyra", "-8000.00", "17900.00"),
+			},
+			wantGaps: []string{"2025-02-26 Hyra: balance 17900.00, expected -7100.00 (difference 25000.00)"},
+		},
+		{
+			name: "Error_corrupted_amount_newest_first",
+			rows: []Transaction{
+				row(27, "Swish", "100.00", "18000.00"),
+				row(26, "Hyra", "-800.00", "17900.00"),
+				row(25, "Lön", "25000.00", "25900.00"),
+				row(24, "ICA", "-100.00", "900.00"),
+			},
+			wantGaps: []string{"2025-02-26 Hyra: balance 17900.00, expected 25100.00 (difference -7200.00)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gaps := CheckRunningBalance(tt.rows)
+			if len(gaps) != len(tt.wantGaps) {
+				t.Fatalf("CheckRunningBalance() = %v, want %v", gaps, tt.wantGaps)
+			}
+			for i, want := range tt.wantGaps {
+				if got := gaps[i].String(); got != want {
+					t.Errorf("gap[%d] = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunningBalances(t *testing.T) {
+	row := func(day int, amount, balance string) Transaction {
+		tx := Transaction{
+			Date:     time.Date(2026, 9, day, 0, 0, 0, 0, time.UTC),
+			Amount:   decimal.RequireFromString(amount),
+			Currency: "SEK",
+		}
+		if balance != "" {
+			tx.Balance = decimal.NewNullDecimal(decimal.RequireFromString(balance))
+		}
+		return tx
+	}
+
+	tests := []struct {
+		name        string
+		rows        []Transaction
+		wantOpening string
+		wantClosing string
+		wantDates   [2]int
+	}{
+		{
+			name:        "Successfully_derive_newest_first",
+			rows:        []Transaction{row(25, "25000.00", "25900.00"), row(3, "-100.00", "900.00")},
+			wantOpening: "1000.00",
+			wantClosing: "25900.00",
+			wantDates:   [2]int{3, 25},
+		},
+		{
+			name:        "Successfully_skip_rows_without_balance",
+			rows:        []Transaction{row(1, "-40.00", ""), row(3, "-100.00", "900.00"), row(25, "25000.00", "25900.00"), row(30, "-10.00", "")},
+			wantOpening: "1000.00",
+			wantClosing: "25900.00",
+			wantDates:   [2]int{3, 25},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			balances := RunningBalances(tt.rows)
+			if len(balances) != 2 {
+				t.Fatalf("RunningBalances() returned %d balances, want 2", len(balances))
+			}
+			opening, closing := balances[0], balances[1]
+			if opening.Type != BalanceOpening || opening.Amount.StringFixed(2) != tt.wantOpening || opening.Date.Day() != tt.wantDates[0] {
+				t.Errorf("opening = %s %s on day %d, want %s on day %d", opening.Type, opening.Amount.StringFixed(2), opening.Date.Day(), tt.wantOpening, tt.wantDates[0])
+			}
+			if closing.Type != BalanceClosing || closing.Amount.StringFixed(2) != tt.wantClosing || closing.Date.Day() != tt.wantDates[1] {
+				t.Errorf("closing = %s %s on day %d, want %s on day %d", closing.Type, closing.Amount.StringFixed(2), closing.Date.Day(), tt.wantClosing, tt.wantDates[1])
+			}
+			if opening.Currency != "SEK" {
+				t.Errorf("currency = %q, want SEK", opening.Currency)
+			}
+		})
+	}
+
+	if balances := RunningBalances([]Transaction{row(3, "-100.00", "")}); balances != nil {
+		t.Errorf("RunningBalances() = %v, want nil without balances", balances)
+	}
+}
@@ -0,0 +1,217 @@
+package processor
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// bankCSVLayout describes a bank's CSV export. The header row is found by its
+// required columns, after at most maxPreamble records of account details.
+type bankCSVLayout struct {
+	delimiter   rune
+	required    []string
+	maxPreamble int
+}
+
+// bankCSVRecord is a data record with its line number in the file
+type bankCSVRecord struct {
+	fields []string
+	line   int
+}
+
+// bankCSVTable is a parsed bank export with its columns resolved by header name
+type bankCSVTable struct {
+	columns   map[string]int
+	preamble  [][]string
+	records   []bankCSVRecord
+	delimiter rune
+}
+
+// detect reports whether the sample contains the layout's header row
+func (l bankCSVLayout) detect(sample []byte) bool {
+	_, err := l.parse(decodeBankExport(sample), true)
+	return err == nil
+}
+
+// read decodes and parses a complete export
+func (l bankCSVLayout) read(reader io.Reader) (*bankCSVTable, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, &ProcessingError{
+			Operation: "read_record",
+			Err:       err,
+		}
+	}
+	return l.parse(decodeBankExport(data), false)
+}
+
+// parse splits the text into preamble, header and records. In sample mode a
+// read error after the header, e.g. from a truncated last line, is ignored.
+func (l bankCSVLayout) parse(text string, sample bool) (*bankCSVTable, error) {
+	csvReader := csv.NewReader(strings.NewReader(text))
+	csvReader.Comma = l.delimiter
+	csvReader.TrimLeadingSpace = true
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+
+	table := &bankCSVTable{delimiter: l.delimiter}
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if table.columns != nil && sample {
+				break
+			}
+			return nil, &ProcessingError{
+				Operation: "read_record",
+				Err:       err,
+			}
+		}
+		line, _ := csvReader.FieldPos(0)
+
+		if table.columns == nil {
+			if columns, ok := l.header(record); ok {
+				table.columns = columns
+				if sample {
+					break
+				}
+				continue
+			}
+			if len(table.preamble) >= l.maxPreamble {
+				break
+			}
+			table.preamble = append(table.preamble, record)
+			continue
+		}
+
+		if !isBlankRecord(record) {
+			table.records = append(table.records, bankCSVRecord{fields: record, line: line})
+		}
+	}
+
+	if table.columns == nil {
+		return nil, &ProcessingError{
+			Operation: "validate_header",
+			Err:       fmt.Errorf("header with columns %s not found", strings.Join(l.required, ", ")),
+		}
+	}
+	return table, nil
+}
+
+// processBankCSV converts the records with parse. Records that fail are logged,
+// recorded in rejections and skipped; records parse reports as not booked are
+// skipped as well.
+func processBankCSV(logger *slog.Logger, bank string, table *bankCSVTable, rejections *rowRejections, parse func(record bankCSVRecord) (Transaction, bool, error)) ([]Transaction, error) {
+	var transactions []Transaction
+	for _, record := range table.records {
+		trans, ok, err := parse(record)
+		if err != nil {
+			logger.Error("failed to parse transaction",
+				"bank", bank,
+				"line", record.line,
+				"error", err,
+				"raw_data", record.fields)
+			rejections.reject(record.line, err, strings.Join(record.fields, string(table.delimiter)))
+			continue
+		}
+		if !ok {
+			logger.Info("skipping transaction that is not booked", "bank", bank, "line", record.line)
+			continue
+		}
+		transactions = append(transactions, trans)
+	}
+
+	if len(transactions) == 0 {
+		logger.Warn("no transactions were found in the document")
+		return nil, &ProcessingError{
+			Operation: "process_document",
+			Err:       fmt.Errorf("no valid transactions found in document"),
+		}
+	}
+
+	logger.Info("successfully processed document",
+		"bank", bank,
+		"total_transactions", len(transactions))
+	return transactions, nil
+}
+
+// header returns the column positions when the record contains all required columns
+func (l bankCSVLayout) header(record []string) (map[string]int, bool) {
+	columns := make(map[string]int, len(record))
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		if _, exists := columns[name]; !exists {
+			columns[name] = i
+		}
+	}
+	for _, name := range l.required {
+		if _, ok := columns[strings.ToLower(name)]; !ok {
+			return nil, false
+		}
+	}
+	return columns, true
+}
+
+// has reports whether the export contains the column
+func (t *bankCSVTable) has(column string) bool {
+	_, ok := t.columns[strings.ToLower(column)]
+	return ok
+}
+
+// get returns the trimmed value of the column, or "" when the column or field is missing
+func (t *bankCSVTable) get(record bankCSVRecord, column string) string {
+	idx, ok := t.columns[strings.ToLower(column)]
+	if !ok || idx >= len(record.fields) {
+		return ""
+	}
+	return strings.TrimSpace(record.fields[idx])
+}
+
+// balance parses the account balance in the column, which is null when the
+// export leaves it empty
+func (t *bankCSVTable) balance(record bankCSVRecord, column string, format NumberFormat) (decimal.NullDecimal, error) {
+	value := t.get(record, column)
+	if value == "" {
+		return decimal.NullDecimal{}, nil
+	}
+	balance, err := ParseAmount(value, format)
+	if err != nil {
+		return decimal.NullDecimal{}, &ProcessingError{
+			Operation: "parse_balance",
+			Err:       err,
+			Line:      record.line,
+		}
+	}
+	return decimal.NewNullDecimal(balance), nil
+}
+
+// preambleValue returns the value following a preamble label such as "Kontonummer"
+func (t *bankCSVTable) preambleValue(label string) string {
+	for _, record := range t.preamble {
+		for i, field := range record {
+			field = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(field), ":"))
+			if !strings.EqualFold(field, label) {
+				continue
+			}
+			for _, value := range record[i+1:] {
+				if value = strings.TrimSpace(value); value != "" {
+					return value
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// decodeBankExport returns the export as UTF-8 without BOM, detecting the encoding
+func decodeBankExport(data []byte) string {
+	text, _ := decodeBytes(data, EncodingAuto)
+	return strings.TrimPrefix(text, "\uFEFF")
+}
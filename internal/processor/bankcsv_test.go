@@ -0,0 +1,61 @@
+package processor
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+)
+
+// assertTransactions compares the parsed transactions field by field.
+// Only the RawData keys present in want are compared.
+func assertTransactions(t *testing.T, got, want []Transaction) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d transactions, got %d", len(want), len(got))
+	}
+	for i, w := range want {
+		if !w.Date.Equal(got[i].Date) {
+			t.Errorf("transaction[%d].Date = %v, want %v", i, got[i].Date, w.Date)
+		}
+		if !w.Amount.Equal(got[i].Amount) {
+			t.Errorf("transaction[%d].Amount = %v, want %v", i, got[i].Amount, w.Amount)
+		}
+		if w.Description != got[i].Description {
+			t.Errorf("transaction[%d].Description = %q, want %q", i, got[i].Description, w.Description)
+		}
+		if w.Reference != got[i].Reference {
+			t.Errorf("transaction[%d].Reference = %q, want %q", i, got[i].Reference, w.Reference)
+		}
+		if w.Source != got[i].Source {
+			t.Errorf("transaction[%d].Source = %q, want %q", i, got[i].Source, w.Source)
+		}
+		if w.Currency != got[i].Currency {
+			t.Errorf("transaction[%d].Currency = %q, want %q", i, got[i].Currency, w.Currency)
+		}
+		if !w.ValueDate.IsZero() && !w.ValueDate.Equal(got[i].ValueDate) {
+			t.Errorf("transaction[%d].ValueDate = %v, want %v", i, got[i].ValueDate, w.ValueDate)
+		}
+		if w.Balance.Valid && (!got[i].Balance.Valid || !w.Balance.Decimal.Equal(got[i].Balance.Decimal)) {
+			t.Errorf("transaction[%d].Balance = %v, want %v", i, got[i].Balance, w.Balance)
+		}
+		for k, v := range w.RawData {
+			if got[i].RawData[k] != v {
+				t.Errorf("transaction[%d].RawData[%q] = %v, want %v", i, k, got[i].RawData[k], v)
+			}
+		}
+	}
+}
+
+// assertDetectedBank checks that the default registry picks the expected bank for the export
+func assertDetectedBank(t *testing.T, input, wantID string) {
+	t.Helper()
+
+	_, info, err := NewDefaultRegistry().Detect([]byte(input), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("Detect() unexpected error: %v", err)
+	}
+	if info.ID != wantID {
+		t.Errorf("Detect() id = %q, want %q", info.ID, wantID)
+	}
+}
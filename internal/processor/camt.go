@@ -0,0 +1,340 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// camtDocument covers both camt.053 account statements and camt.054
+// debit/credit notifications. Element names are matched without namespace so
+// that all message versions are accepted.
+type camtDocument struct {
+	Statements    []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+	Notifications []camtStatement `xml:"BkToCstmrDbtCdtNtfctn>Ntfctn"`
+}
+
+type camtStatement struct {
+	ID       string        `xml:"Id"`
+	Account  camtAccount   `xml:"Acct"`
+	Balances []camtBalance `xml:"Bal"`
+	Entries  []camtEntry   `xml:"Ntry"`
+}
+
+type camtAccount struct {
+	IBAN     string `xml:"Id>IBAN"`
+	Other    string `xml:"Id>Othr>Id"`
+	Currency string `xml:"Ccy"`
+}
+
+func (a camtAccount) id() string {
+	if a.IBAN != "" {
+		return a.IBAN
+	}
+	return a.Other
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtBalance struct {
+	Code      string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    camtAmount `xml:"Amt"`
+	Indicator string     `xml:"CdtDbtInd"`
+	Date      camtDate   `xml:"Dt"`
+}
+
+type camtEntry struct {
+	Amount         camtAmount     `xml:"Amt"`
+	Indicator      string         `xml:"CdtDbtInd"`
+	Reversal       bool           `xml:"RvslInd"`
+	Status         camtStatus     `xml:"Sts"`
+	BookingDate    camtDate       `xml:"BookgDt"`
+	ValueDate      camtDate       `xml:"ValDt"`
+	ServicerRef    string         `xml:"AcctSvcrRef"`
+	Details        []camtTxDetail `xml:"NtryDtls>TxDtls"`
+	AdditionalInfo string         `xml:"AddtlNtryInf"`
+}
+
+// camtStatus is a plain status code in camt.053.001.02 and <Cd> in later versions
+type camtStatus struct {
+	Value string `xml:",chardata"`
+	Code  string `xml:"Cd"`
+}
+
+type camtParty struct {
+	Name      string `xml:"Nm"`
+	PartyName string `xml:"Pty>Nm"`
+}
+
+func (p camtParty) name() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.PartyName
+}
+
+type camtTxDetail struct {
+	Amount         camtAmount `xml:"Amt"`
+	EndToEndID     string     `xml:"Refs>EndToEndId"`
+	ServicerRef    string     `xml:"Refs>AcctSvcrRef"`
+	Debtor         camtParty  `xml:"RltdPties>Dbtr"`
+	DebtorIBAN     string     `xml:"RltdPties>DbtrAcct>Id>IBAN"`
+	Creditor       camtParty  `xml:"RltdPties>Cdtr"`
+	CreditorIBAN   string     `xml:"RltdPties>CdtrAcct>Id>IBAN"`
+	Unstructured   []string   `xml:"RmtInf>Ustrd"`
+	StructuredRef  string     `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	AdditionalInfo string     `xml:"AddtlTxInf"`
+}
+
+// CAMTProcessor implements the Parser interface for ISO 20022 camt.053 and camt.054 XML files
+type CAMTProcessor struct {
+	rowRejections
+	logger   *slog.Logger
+	balances []StatementBalance
+}
+
+// NewCAMTProcessor creates a new ISO 20022 camt processor
+func NewCAMTProcessor(logger *slog.Logger) *CAMTProcessor {
+	return &CAMTProcessor{
+		logger: logger,
+	}
+}
+
+// Detect implements the Parser interface by looking for the camt message elements
+func (p *CAMTProcessor) Detect(sample []byte) bool {
+	return bytes.Contains(sample, []byte("BkToCstmrStmt")) ||
+		bytes.Contains(sample, []byte("BkToCstmrDbtCdtNtfctn"))
+}
+
+// Balances implements the BalanceProvider interface
+func (p *CAMTProcessor) Balances() []StatementBalance {
+	return p.balances
+}
+
+// ProcessDocument implements the DocumentProcessor interface for camt XML files
+func (p *CAMTProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([]Transaction, error) {
+	var doc camtDocument
+	if err := xml.NewDecoder(reader).Decode(&doc); err != nil {
+		return nil, &ProcessingError{
+			Operation: "decode_xml",
+			Err:       err,
+		}
+	}
+
+	statements := append(doc.Statements, doc.Notifications...)
+	if len(statements) == 0 {
+		return nil, &ProcessingError{
+			Operation: "read_header",
+			Err:       fmt.Errorf("no camt.053 statements or camt.054 notifications found"),
+		}
+	}
+
+	p.balances = nil
+	p.resetRejections()
+	var transactions []Transaction
+	for _, stmt := range statements {
+		account := stmt.Account.id()
+
+		for _, bal := range stmt.Balances {
+			balance, err := p.parseBalance(bal, stmt.Account)
+			if err != nil {
+				p.logger.Warn("failed to parse balance", "account", account, "error", err)
+				continue
+			}
+			if balance.Type != "" {
+				p.balances = append(p.balances, balance)
+			}
+		}
+
+		for i, entry := range stmt.Entries {
+			status := strings.ToUpper(firstNonEmpty(entry.Status.Code, entry.Status.Value))
+			if status != "" && status != "BOOK" {
+				p.logger.Info("skipping entry that is not booked", "account", account, "entry", i+1, "status", status)
+				continue
+			}
+
+			parsed, err := p.parseEntry(entry, stmt)
+			if err != nil {
+				p.logger.Error("failed to parse transaction",
+					"account", account,
+					"entry", i+1,
+					"error", err)
+				p.reject(0, fmt.Errorf("account %s entry %d: %w", account, i+1, err), "")
+				continue
+			}
+			transactions = append(transactions, parsed...)
+		}
+	}
+
+	if len(transactions) == 0 {
+		p.logger.Warn("no transactions were found in the document")
+		return nil, &ProcessingError{
+			Operation: "process_document",
+			Err:       fmt.Errorf("no valid transactions found in document"),
+		}
+	}
+
+	p.logger.Info("successfully processed document",
+		"format", "camt",
+		"statements", len(statements),
+		"total_transactions", len(transactions))
+	return transactions, nil
+}
+
+func (p *CAMTProcessor) parseBalance(bal camtBalance, account camtAccount) (StatementBalance, error) {
+	amount, err := camtSignedAmount(bal.Amount.Value, bal.Indicator, false)
+	if err != nil {
+		return StatementBalance{}, err
+	}
+	date, err := bal.Date.parse()
+	if err != nil {
+		return StatementBalance{}, err
+	}
+
+	balance := StatementBalance{
+		Date:     date,
+		Amount:   amount,
+		Account:  account.id(),
+		Currency: firstNonEmpty(bal.Amount.Currency, account.Currency),
+	}
+	switch strings.ToUpper(bal.Code) {
+	case "OPBD", "PRCD":
+		balance.Type = BalanceOpening
+	case "CLBD":
+		balance.Type = BalanceClosing
+	}
+	return balance, nil
+}
+
+// parseEntry converts an entry into transactions. Batch entries with several
+// amounted transaction details produce one transaction per detail.
+func (p *CAMTProcessor) parseEntry(entry camtEntry, stmt camtStatement) ([]Transaction, error) {
+	bookingDate, err := entry.BookingDate.parse()
+	if err != nil {
+		return nil, &ProcessingError{Operation: "parse_date", Err: err}
+	}
+
+	details := entry.Details
+	if len(details) > 1 {
+		for _, d := range details {
+			if d.Amount.Value == "" {
+				details = details[:1]
+				break
+			}
+		}
+	}
+	if len(details) == 0 {
+		details = []camtTxDetail{{}}
+	}
+
+	transactions := make([]Transaction, 0, len(details))
+	for _, d := range details {
+		amountValue, currency := entry.Amount.Value, entry.Amount.Currency
+		if len(details) > 1 {
+			amountValue, currency = d.Amount.Value, firstNonEmpty(d.Amount.Currency, currency)
+		}
+		amount, err := camtSignedAmount(amountValue, entry.Indicator, entry.Reversal)
+		if err != nil {
+			return nil, &ProcessingError{Operation: "parse_amount", Err: err}
+		}
+
+		// The counterparty is the creditor for outgoing payments and the debtor for incoming ones
+		counterparty, counterpartyIBAN := d.Debtor.name(), d.DebtorIBAN
+		if strings.EqualFold(entry.Indicator, "DBIT") {
+			counterparty, counterpartyIBAN = d.Creditor.name(), d.CreditorIBAN
+		}
+
+		endToEnd := d.EndToEndID
+		if strings.EqualFold(endToEnd, "NOTPROVIDED") {
+			endToEnd = ""
+		}
+		servicerRef := firstNonEmpty(d.ServicerRef, entry.ServicerRef)
+
+		remittance := strings.TrimSpace(strings.Join(d.Unstructured, " "))
+		description := firstNonEmpty(remittance, counterparty, d.AdditionalInfo, entry.AdditionalInfo, d.StructuredRef)
+
+		// The value date is left out of some statements
+		valueDate, _ := entry.ValueDate.parse()
+		rawData := map[string]any{
+			"BookingDate": bookingDate.Format("2006-01-02"),
+			"Account":     stmt.Account.id(),
+		}
+		addRawString(rawData, "StatementID", stmt.ID)
+		addRawString(rawData, "EndToEndID", endToEnd)
+		addRawString(rawData, "AccountServicerReference", servicerRef)
+		addRawString(rawData, "CounterpartyName", counterparty)
+		addRawString(rawData, "CounterpartyIBAN", counterpartyIBAN)
+		addRawString(rawData, "RemittanceInformation", remittance)
+		addRawString(rawData, "CreditorReference", d.StructuredRef)
+
+		transactions = append(transactions, Transaction{
+			Date:        bookingDate,
+			Amount:      amount,
+			Description: description,
+			Reference:   firstNonEmpty(endToEnd, servicerRef),
+			RawData:     rawData,
+			Source:      "camt",
+			Currency:    strings.ToUpper(firstNonEmpty(currency, stmt.Account.Currency)),
+			ValueDate:   valueDate,
+		})
+	}
+	return transactions, nil
+}
+
+// parse returns the date part of a camt Dt or DtTm element
+func (d camtDate) parse() (time.Time, error) {
+	value := strings.TrimSpace(firstNonEmpty(d.Date, d.DateTime))
+	if len(value) < 10 {
+		return time.Time{}, fmt.Errorf("invalid date format '%s'", value)
+	}
+	date, err := time.Parse("2006-01-02", value[:10])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date format '%s': %v", value, err)
+	}
+	return date, nil
+}
+
+// camtSignedAmount applies the credit/debit indicator, and an optional reversal, to an unsigned amount
+func camtSignedAmount(value, indicator string, reversal bool) (decimal.Decimal, error) {
+	amount, err := ParseAmount(value, NumberFormat{DecimalSeparator: "."})
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	debit := strings.EqualFold(indicator, "DBIT")
+	if debit != reversal {
+		amount = amount.Abs().Neg()
+	} else {
+		amount = amount.Abs()
+	}
+	return amount, nil
+}
+
+func addRawString(rawData map[string]any, key, value string) {
+	if value = strings.TrimSpace(value); value != "" {
+		rawData[key] = value
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,185 @@
+package processor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const testCAMT053 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <GrpHdr><MsgId>MSG1</MsgId><CreDtTm>2025-03-01T06:00:00</CreDtTm></GrpHdr>
+    <Stmt>
+      <Id>STMT-2025-02</Id>
+      <Acct><Id><IBAN>SE4550000000058398257466</IBAN></Id><Ccy>SEK</Ccy></Acct>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="SEK">1000.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Dt><Dt>2025-02-01</Dt></Dt>
+      </Bal>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="SEK">25750.50</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Dt><Dt>2025-02-28</Dt></Dt>
+      </Bal>
+      <Ntry>
+        <Amt Ccy="SEK">249.50</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2025-02-24</Dt></BookgDt>
+        <ValDt><Dt>2025-02-22</Dt></ValDt>
+        <AcctSvcrRef>SVC-1</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <Refs><EndToEndId>E2E-123</EndToEndId></Refs>
+          <RltdPties>
+            <Cdtr><Nm>Vattenfall AB</Nm></Cdtr>
+            <CdtrAcct><Id><IBAN>SE3550000000054910000003</IBAN></Id></CdtrAcct>
+          </RltdPties>
+          <RmtInf><Ustrd>Elräkning februari</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="SEK">25000.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2025-02-25</Dt></BookgDt>
+        <ValDt><Dt>2025-02-25</Dt></ValDt>
+        <AcctSvcrRef>SVC-2</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs>
+          <RltdPties><Dbtr><Nm>Arbetsgivaren AB</Nm></Dbtr></RltdPties>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="SEK">10.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>PDNG</Sts>
+        <BookgDt><Dt>2025-02-28</Dt></BookgDt>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+
+func TestCAMTProcessor_Successfully_process_statement(t *testing.T) {
+	processor := NewCAMTProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if !processor.Detect([]byte(testCAMT053)) {
+		t.Fatal("Detect() = false, want true")
+	}
+
+	got, err := processor.ProcessDocument(context.Background(), strings.NewReader(testCAMT053))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Transaction{
+		{
+			Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("-249.50"),
+			Description: "Elräkning februari",
+			Reference:   "E2E-123",
+			ValueDate:   time.Date(2025, 2, 22, 0, 0, 0, 0, time.UTC),
+			RawData: map[string]any{
+				"BookingDate":      "2025-02-24",
+				"EndToEndID":       "E2E-123",
+				"CounterpartyName": "Vattenfall AB",
+				"CounterpartyIBAN": "SE3550000000054910000003",
+			},
+		},
+		{
+			Date:        time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("25000"),
+			Description: "Arbetsgivaren AB",
+			Reference:   "SVC-2",
+			RawData: map[string]any{
+				"CounterpartyName": "Arbetsgivaren AB",
+				"Account":          "SE4550000000058398257466",
+			},
+		},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d transactions, got %d", len(want), len(got))
+	}
+	for i, w := range want {
+		if !w.Date.Equal(got[i].Date) {
+			t.Errorf("transaction[%d].Date = %v, want %v", i, got[i].Date, w.Date)
+		}
+		if !w.Amount.Equal(got[i].Amount) {
+			t.Errorf("transaction[%d].Amount = %v, want %v", i, got[i].Amount, w.Amount)
+		}
+		if w.Description != got[i].Description {
+			t.Errorf("transaction[%d].Description = %q, want %q", i, got[i].Description, w.Description)
+		}
+		if w.Reference != got[i].Reference {
+			t.Errorf("transaction[%d].Reference = %q, want %q", i, got[i].Reference, w.Reference)
+		}
+		if got[i].Currency != "SEK" {
+			t.Errorf("transaction[%d].Currency = %q, want SEK", i, got[i].Currency)
+		}
+		if !w.ValueDate.IsZero() && !w.ValueDate.Equal(got[i].ValueDate) {
+			t.Errorf("transaction[%d].ValueDate = %v, want %v", i, got[i].ValueDate, w.ValueDate)
+		}
+		for k, v := range w.RawData {
+			if got[i].RawData[k] != v {
+				t.Errorf("transaction[%d].RawData[%q] = %v, want %v", i, k, got[i].RawData[k], v)
+			}
+		}
+	}
+
+	balances := processor.Balances()
+	if len(balances) != 2 {
+		t.Fatalf("expected 2 balances, got %d", len(balances))
+	}
+	checks := CheckBalances(got, balances)
+	if len(checks) != 1 {
+		t.Fatalf("expected 1 balance check, got %d", len(checks))
+	}
+	if !checks[0].OK() {
+		t.Errorf("balance check failed: %s", checks[0])
+	}
+}
+
+func TestCAMTProcessor_error_validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "Process_error_invalid_xml",
+			input:   "<Document><BkToCstmrStmt>",
+			wantErr: "decode_xml failed",
+		},
+		{
+			name:    "Process_error_not_camt",
+			input:   "<Document><Other/></Document>",
+			wantErr: "no camt.053 statements",
+		},
+		{
+			name:    "Process_error_no_booked_entries",
+			input:   "<Document><BkToCstmrStmt><Stmt><Ntry><Amt>1.00</Amt><Sts><Cd>PDNG</Cd></Sts></Ntry></Stmt></BkToCstmrStmt></Document>",
+			wantErr: "no valid transactions found",
+		},
+	}
+
+	processor := NewCAMTProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := processor.ProcessDocument(context.Background(), strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
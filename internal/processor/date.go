@@ -0,0 +1,39 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultDateLayouts are the date formats found in Swedish and European bank
+// exports, tried in order when a parser does not name its layouts
+var DefaultDateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"20060102",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"02.01.2006",
+	"2.1.2006",
+}
+
+// ParseDate parses a date with the first matching layout, or with
+// DefaultDateLayouts when no layouts are given. Surrounding whitespace and
+// quotes are ignored.
+func ParseDate(value string, layouts ...string) (time.Time, error) {
+	value = strings.Trim(strings.TrimSpace(value), `"`)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+	if len(layouts) == 0 {
+		layouts = DefaultDateLayouts
+	}
+	for _, layout := range layouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date format '%s'", value)
+}
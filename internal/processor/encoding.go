@@ -0,0 +1,119 @@
+package processor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Supported character encodings for bank exports. EncodingAuto detects the
+// encoding from the data, see DetectEncoding.
+const (
+	EncodingAuto        = "auto"
+	EncodingUTF8        = "utf-8"
+	EncodingISO88591    = "iso-8859-1"
+	EncodingWindows1252 = "windows-1252"
+)
+
+// utf8BOM is the byte order mark some exporters write at the start of UTF-8 files
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// windows1252 maps the bytes 0x80-0x9F, where Windows-1252 differs from ISO-8859-1
+var windows1252 = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
+
+// normalizeEncoding returns the canonical name of a supported encoding
+func normalizeEncoding(encoding string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "auto", "detect":
+		return EncodingAuto, nil
+	case "", "utf-8", "utf8":
+		return EncodingUTF8, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1":
+		return EncodingISO88591, nil
+	case "windows-1252", "cp1252":
+		return EncodingWindows1252, nil
+	default:
+		return "", fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+}
+
+// DetectEncoding guesses the encoding of a bank export. Data that is valid
+// UTF-8 is UTF-8; anything else is treated as the single byte encodings
+// Swedish banks use, Windows-1252 when it contains bytes that only have a
+// printable meaning there and ISO-8859-1 otherwise.
+func DetectEncoding(data []byte) string {
+	if bytes.HasPrefix(data, utf8BOM) || validUTF8Prefix(data) {
+		return EncodingUTF8
+	}
+	for _, b := range data {
+		if b >= 0x80 && b <= 0x9F {
+			return EncodingWindows1252
+		}
+	}
+	return EncodingISO88591
+}
+
+// validUTF8Prefix reports whether data is valid UTF-8, allowing a multi-byte
+// character to be cut off at the end as happens in sniffed samples
+func validUTF8Prefix(data []byte) bool {
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size == 1 {
+			return len(data) < utf8.UTFMax && !utf8.FullRune(data)
+		}
+		data = data[size:]
+	}
+	return true
+}
+
+// decodeBytes converts data in the given encoding to UTF-8
+func decodeBytes(data []byte, encoding string) (string, error) {
+	enc, err := normalizeEncoding(encoding)
+	if err != nil {
+		return "", err
+	}
+	if enc == EncodingAuto {
+		enc = DetectEncoding(data)
+	}
+	if enc == EncodingUTF8 {
+		return string(data), nil
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(data) + len(data)/8)
+	for _, b := range data {
+		if enc == EncodingWindows1252 && b >= 0x80 && b <= 0x9F {
+			sb.WriteRune(windows1252[b-0x80])
+			continue
+		}
+		sb.WriteRune(rune(b))
+	}
+	return sb.String(), nil
+}
+
+// decodeReader returns a reader producing the UTF-8 representation of the input
+func decodeReader(reader io.Reader, encoding string) (io.Reader, error) {
+	enc, err := normalizeEncoding(encoding)
+	if err != nil {
+		return nil, err
+	}
+	if enc == EncodingUTF8 {
+		return reader, nil
+	}
+
+	// Other encodings, and detecting one, need the complete document
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	text, err := decodeBytes(data, enc)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(text), nil
+}
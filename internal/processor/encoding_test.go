@@ -0,0 +1,71 @@
+package processor
+
+import (
+	"testing"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{name: "Successfully_detect_utf8", input: []byte("Bokföringsdag;Belopp"), want: EncodingUTF8},
+		{name: "Successfully_detect_utf8_with_bom", input: []byte("\xef\xbb\xbfDatum;Belopp"), want: EncodingUTF8},
+		{name: "Successfully_detect_ascii_as_utf8", input: []byte("Date;Amount"), want: EncodingUTF8},
+		{name: "Successfully_detect_iso_8859_1", input: []byte("Bokf\xf6ringsdag;\xc5terbetalning"), want: EncodingISO88591},
+		{name: "Successfully_detect_windows_1252", input: []byte("Bokf\xf6ringsdag;\x80 100"), want: EncodingWindows1252},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectEncoding(tt.input); got != tt.want {
+				t.Errorf("DetectEncoding() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeBytes_Successfully_convert_detected_encoding(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{name: "Successfully_keep_utf8", input: []byte("Räkning åäö"), want: "Räkning åäö"},
+		{name: "Successfully_convert_iso_8859_1", input: []byte("R\xe4kning \xe5\xe4\xf6"), want: "Räkning åäö"},
+		{name: "Successfully_convert_windows_1252", input: []byte("\x80 \xc5terk\xf6p \x96 kr"), want: "€ Återköp – kr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeBytes(tt.input, EncodingAuto)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("decodeBytes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidUTF8Prefix(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  bool
+	}{
+		{name: "Successfully_accept_utf8", input: []byte("Bokföringsdag"), want: true},
+		{name: "Successfully_accept_truncated_rune", input: []byte("Bokf\xc3"), want: true},
+		{name: "Successfully_reject_latin1", input: []byte("Bokf\xf6ringsdag"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validUTF8Prefix(tt.input); got != tt.want {
+				t.Errorf("validUTF8Prefix() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package processor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// handelsbankenLayout is Handelsbanken's account statement export, which lists
+// the account and period above a semicolon separated table
+var handelsbankenLayout = bankCSVLayout{
+	delimiter:   ';',
+	required:    []string{"Reskontradatum", "Transaktionsdatum", "Text", "Belopp"},
+	maxPreamble: 10,
+}
+
+// HandelsbankenProcessor implements the Parser interface for Handelsbanken CSV exports
+type HandelsbankenProcessor struct {
+	rowRejections
+	logger       *slog.Logger
+	numberFormat NumberFormat
+}
+
+// NewHandelsbankenProcessor creates a new Handelsbanken CSV processor
+func NewHandelsbankenProcessor(logger *slog.Logger) *HandelsbankenProcessor {
+	return &HandelsbankenProcessor{
+		logger:       logger,
+		numberFormat: NumberFormat{DecimalSeparator: ",", ThousandsSeparator: " "},
+	}
+}
+
+// Detect implements the Parser interface by looking for the Handelsbanken header below the account details
+func (p *HandelsbankenProcessor) Detect(sample []byte) bool {
+	return handelsbankenLayout.detect(sample)
+}
+
+// ProcessDocument implements the DocumentProcessor interface for Handelsbanken CSV files
+func (p *HandelsbankenProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([]Transaction, error) {
+	p.resetRejections()
+	table, err := handelsbankenLayout.read(reader)
+	if err != nil {
+		return nil, err
+	}
+	account := firstNonEmpty(table.preambleValue("Kontonummer"), table.preambleValue("Konto"))
+	return processBankCSV(p.logger, "handelsbanken", table, &p.rowRejections, func(record bankCSVRecord) (Transaction, bool, error) {
+		trans, err := p.parseTransaction(table, record, account)
+		return trans, true, err
+	})
+}
+
+func (p *HandelsbankenProcessor) parseTransaction(table *bankCSVTable, record bankCSVRecord, account string) (Transaction, error) {
+	date, err := ParseDate(table.get(record, "Reskontradatum"), "2006-01-02")
+	if err != nil {
+		return Transaction{}, &ProcessingError{
+			Operation: "parse_date",
+			Err:       err,
+			Line:      record.line,
+		}
+	}
+
+	amount, err := ParseAmount(table.get(record, "Belopp"), p.numberFormat)
+	if err != nil {
+		return Transaction{}, &ProcessingError{
+			Operation: "parse_amount",
+			Err:       err,
+			Line:      record.line,
+		}
+	}
+
+	balance, err := table.balance(record, "Saldo", p.numberFormat)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	rawData := map[string]any{}
+	addRawString(rawData, "TransactionDate", table.get(record, "Transaktionsdatum"))
+	addRawString(rawData, "Account", account)
+
+	return Transaction{
+		Date:        date,
+		Amount:      amount,
+		Description: table.get(record, "Text"),
+		RawData:     rawData,
+		Source:      "Handelsbanken",
+		Balance:     balance,
+	}, nil
+}
@@ -0,0 +1,103 @@
+package processor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const testHandelsbankenCSV = `Kontoutdrag;
+Kontonummer:;Allkonto 6000-123 456 789
+Period:;2025-02-01 - 2025-02-28
+
+Reskontradatum;Transaktionsdatum;Text;Belopp;Saldo
+2025-02-24;2025-02-23;Kafé Åre;-1 249,50;10 750,50
+2025-02-25;2025-02-25;Lön;25 000,00;35 750,50
+`
+
+func TestHandelsbankenProcessor_Successfully_process_valid_transactions(t *testing.T) {
+	want := []Transaction{
+		{
+			Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("-1249.50"),
+			Description: "Kafé Åre",
+			Source:      "Handelsbanken",
+			Balance:     decimal.NewNullDecimal(decimal.RequireFromString("10750.50")),
+			RawData: map[string]any{
+				"TransactionDate": "2025-02-23",
+				"Account":         "Allkonto 6000-123 456 789",
+			},
+		},
+		{
+			Date:        time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("25000"),
+			Description: "Lön",
+			Source:      "Handelsbanken",
+			Balance:     decimal.NewNullDecimal(decimal.RequireFromString("35750.50")),
+		},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "Successfully_process_latin1_export",
+			input: latin1(testHandelsbankenCSV),
+		},
+		{
+			name:  "Successfully_process_utf8_export_with_bom",
+			input: "\uFEFF" + testHandelsbankenCSV,
+		},
+	}
+
+	processor := NewHandelsbankenProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertDetectedBank(t, tt.input, "handelsbanken")
+
+			got, err := processor.ProcessDocument(context.Background(), strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertTransactions(t, got, want)
+		})
+	}
+}
+
+func TestHandelsbankenProcessor_error_validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "Process_error_missing_header",
+			input:   "Datum;Text;Belopp\n2025-02-24;Kafé;-1,00\n",
+			wantErr: "validate_header failed",
+		},
+		{
+			name:    "Process_error_invalid_date_format",
+			input:   "Reskontradatum;Transaktionsdatum;Text;Belopp;Saldo\n24/02/2025;24/02/2025;Kafé;-1,00;0,00\n",
+			wantErr: "no valid transactions found",
+		},
+	}
+
+	processor := NewHandelsbankenProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := processor.ProcessDocument(context.Background(), strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
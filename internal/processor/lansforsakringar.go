@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// lansforsakringarLayout is Länsförsäkringar Bank's account export
+var lansforsakringarLayout = bankCSVLayout{
+	delimiter:   ';',
+	required:    []string{"Bokföringsdatum", "Transaktionsdatum", "Meddelande", "Belopp"},
+	maxPreamble: 5,
+}
+
+// LansforsakringarProcessor implements the Parser interface for Länsförsäkringar CSV exports
+type LansforsakringarProcessor struct {
+	rowRejections
+	logger       *slog.Logger
+	numberFormat NumberFormat
+}
+
+// NewLansforsakringarProcessor creates a new Länsförsäkringar CSV processor
+func NewLansforsakringarProcessor(logger *slog.Logger) *LansforsakringarProcessor {
+	return &LansforsakringarProcessor{
+		logger:       logger,
+		numberFormat: NumberFormat{DecimalSeparator: ",", ThousandsSeparator: " "},
+	}
+}
+
+// Detect implements the Parser interface by looking for the Länsförsäkringar header columns
+func (p *LansforsakringarProcessor) Detect(sample []byte) bool {
+	return lansforsakringarLayout.detect(sample)
+}
+
+// ProcessDocument implements the DocumentProcessor interface for Länsförsäkringar CSV files
+func (p *LansforsakringarProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([]Transaction, error) {
+	p.resetRejections()
+	table, err := lansforsakringarLayout.read(reader)
+	if err != nil {
+		return nil, err
+	}
+	return processBankCSV(p.logger, "lansforsakringar", table, &p.rowRejections, func(record bankCSVRecord) (Transaction, bool, error) {
+		trans, err := p.parseTransaction(table, record)
+		return trans, true, err
+	})
+}
+
+func (p *LansforsakringarProcessor) parseTransaction(table *bankCSVTable, record bankCSVRecord) (Transaction, error) {
+	date, err := ParseDate(table.get(record, "Bokföringsdatum"), "2006-01-02")
+	if err != nil {
+		return Transaction{}, &ProcessingError{
+			Operation: "parse_date",
+			Err:       err,
+			Line:      record.line,
+		}
+	}
+
+	amount, err := ParseAmount(table.get(record, "Belopp"), p.numberFormat)
+	if err != nil {
+		return Transaction{}, &ProcessingError{
+			Operation: "parse_amount",
+			Err:       err,
+			Line:      record.line,
+		}
+	}
+
+	balance, err := table.balance(record, "Saldo", p.numberFormat)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	transactionType := table.get(record, "Transaktionstyp")
+	rawData := map[string]any{}
+	addRawString(rawData, "TransactionDate", table.get(record, "Transaktionsdatum"))
+	addRawString(rawData, "TransactionType", transactionType)
+
+	return Transaction{
+		Date:        date,
+		Amount:      amount,
+		Description: firstNonEmpty(table.get(record, "Meddelande"), transactionType),
+		RawData:     rawData,
+		Source:      "Länsförsäkringar",
+		Balance:     balance,
+	}, nil
+}
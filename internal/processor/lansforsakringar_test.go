@@ -0,0 +1,97 @@
+package processor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLansforsakringarProcessor_Successfully_process_valid_transactions(t *testing.T) {
+	input := latin1("Bokföringsdatum;Transaktionsdatum;Transaktionstyp;Meddelande;Belopp;Saldo\n" +
+		"2025-02-24;2025-02-22;Kortköp;Kafé Åre;-1 249,50;10 750,50\n" +
+		"2025-02-25;2025-02-25;Insättning;;25 000,00;35 750,50\n")
+
+	want := []Transaction{
+		{
+			Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("-1249.50"),
+			Description: "Kafé Åre",
+			Source:      "Länsförsäkringar",
+			Balance:     decimal.NewNullDecimal(decimal.RequireFromString("10750.50")),
+			RawData: map[string]any{
+				"TransactionDate": "2025-02-22",
+				"TransactionType": "Kortköp",
+			},
+		},
+		{
+			Date:        time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("25000"),
+			Description: "Insättning",
+			Source:      "Länsförsäkringar",
+		},
+	}
+
+	assertDetectedBank(t, input, "lansforsakringar")
+
+	processor := NewLansforsakringarProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	got, err := processor.ProcessDocument(context.Background(), strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertTransactions(t, got, want)
+}
+
+func TestLansforsakringarProcessor_Successfully_check_running_balance(t *testing.T) {
+	// The row between the two, a withdrawal of 500, is missing from the export
+	input := latin1("Bokföringsdatum;Transaktionsdatum;Transaktionstyp;Meddelande;Belopp;Saldo\n" +
+		"2025-02-24;2025-02-22;Kortköp;Kafé Åre;-1 249,50;10 750,50\n" +
+		"2025-02-26;2025-02-26;Insättning;;25 000,00;35 250,50\n")
+
+	processor := NewLansforsakringarProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	got, err := processor.ProcessDocument(context.Background(), strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	gaps := CheckRunningBalance(got)
+	if len(gaps) != 1 || !gaps[0].Difference().Equal(decimal.RequireFromString("-500")) {
+		t.Errorf("CheckRunningBalance() = %+v, want one gap of -500", gaps)
+	}
+}
+
+func TestLansforsakringarProcessor_error_validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "Process_error_missing_header",
+			input:   "Bokföringsdatum;Text;Belopp\n2025-02-24;Kafé;-1,00\n",
+			wantErr: "validate_header failed",
+		},
+		{
+			name:    "Process_error_invalid_amount_format",
+			input:   "Bokföringsdatum;Transaktionsdatum;Transaktionstyp;Meddelande;Belopp;Saldo\n2025-02-24;2025-02-24;Kortköp;Kafé;abc;0,00\n",
+			wantErr: "no valid transactions found",
+		},
+	}
+
+	processor := NewLansforsakringarProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := processor.ProcessDocument(context.Background(), strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package processor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// mt940StatementLine matches the :61: statement line:
+// value date, optional entry date, debit/credit mark, optional funds code,
+// amount, transaction type, customer reference and optional bank reference
+var mt940StatementLine = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?([0-9]+,[0-9]*)([NFS][A-Z0-9]{3})([^/]*?)(?://(.*))?$`)
+
+// mt940Balance matches balance tags such as :60F: and :62F:
+var mt940Balance = regexp.MustCompile(`^(C|D)(\d{6})([A-Z]{3})([0-9]+,[0-9]*)$`)
+
+// mt940Subfield matches structured :86: subfields such as ?20 or ?32
+var mt940Subfield = regexp.MustCompile(`\?(\d{2})`)
+
+// mt940Tag is a tag with its value, including continuation lines
+type mt940Tag struct {
+	name  string
+	value string
+	line  int
+}
+
+// MT940Processor implements the Parser interface for SWIFT MT940 customer statements
+type MT940Processor struct {
+	rowRejections
+	logger   *slog.Logger
+	balances []StatementBalance
+}
+
+// NewMT940Processor creates a new MT940 processor
+func NewMT940Processor(logger *slog.Logger) *MT940Processor {
+	return &MT940Processor{
+		logger: logger,
+	}
+}
+
+// Detect implements the Parser interface by looking for the mandatory MT940 tags
+func (p *MT940Processor) Detect(sample []byte) bool {
+	text := string(sample)
+	return strings.Contains(text, ":20:") && strings.Contains(text, ":25:") &&
+		(strings.Contains(text, ":60F:") || strings.Contains(text, ":60M:"))
+}
+
+// Balances implements the BalanceProvider interface
+func (p *MT940Processor) Balances() []StatementBalance {
+	return p.balances
+}
+
+// ProcessDocument implements the DocumentProcessor interface for MT940 files
+func (p *MT940Processor) ProcessDocument(ctx context.Context, reader io.Reader) ([]Transaction, error) {
+	tags, err := readMT940Tags(reader)
+	if err != nil {
+		return nil, &ProcessingError{
+			Operation: "read_record",
+			Err:       err,
+		}
+	}
+	if len(tags) == 0 {
+		return nil, &ProcessingError{
+			Operation: "read_header",
+			Err:       fmt.Errorf("no MT940 tags found"),
+		}
+	}
+
+	p.balances = nil
+	p.resetRejections()
+	var (
+		transactions []Transaction
+		account      string
+		statementRef string
+		current      *Transaction
+		currency     string
+	)
+
+	flush := func() {
+		if current != nil {
+			transactions = append(transactions, *current)
+			current = nil
+		}
+	}
+
+	for _, tag := range tags {
+		switch tag.name {
+		case "20":
+			flush()
+			statementRef = tag.value
+		case "25":
+			account = tag.value
+		case "60F", "60M", "62F", "62M":
+			flush()
+			balance, err := parseMT940Balance(tag.value)
+			if err != nil {
+				p.logger.Warn("failed to parse balance", "tag", tag.name, "line", tag.line, "error", err)
+				continue
+			}
+			balance.Account = account
+			balance.Type = BalanceOpening
+			if strings.HasPrefix(tag.name, "62") {
+				balance.Type = BalanceClosing
+			}
+			currency = balance.Currency
+			p.balances = append(p.balances, balance)
+		case "61":
+			flush()
+			trans, err := p.parseStatementLine(tag)
+			if err != nil {
+				p.logger.Error("failed to parse transaction",
+					"line", tag.line,
+					"error", err,
+					"raw_data", tag.value)
+				p.reject(tag.line, err, ":61:"+tag.value)
+				continue
+			}
+			trans.Currency = currency
+			trans.RawData["Account"] = account
+			if statementRef != "" {
+				trans.RawData["StatementReference"] = statementRef
+			}
+			current = &trans
+		case "86":
+			if current != nil {
+				applyMT940Narrative(current, tag.value)
+			}
+		}
+	}
+	flush()
+
+	if len(transactions) == 0 {
+		p.logger.Warn("no transactions were found in the document")
+		return nil, &ProcessingError{
+			Operation: "process_document",
+			Err:       fmt.Errorf("no valid transactions found in document"),
+		}
+	}
+
+	p.logger.Info("successfully processed document",
+		"format", "mt940",
+		"total_transactions", len(transactions))
+	return transactions, nil
+}
+
+// readMT940Tags splits the document into tags, joining continuation lines and
+// ignoring SWIFT block headers and statement separators
+func readMT940Tags(reader io.Reader) ([]mt940Tag, error) {
+	scanner := bufio.NewScanner(reader)
+	var (
+		tags    []mt940Tag
+		lineNum int
+	)
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r ")
+		if lineNum == 1 {
+			line = strings.TrimPrefix(line, "\uFEFF")
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "-" || trimmed == "-}" || strings.HasPrefix(trimmed, "{") {
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			if end := strings.Index(line[1:], ":"); end > 0 {
+				tags = append(tags, mt940Tag{
+					name:  line[1 : end+1],
+					value: line[end+2:],
+					line:  lineNum,
+				})
+				continue
+			}
+		}
+		if len(tags) > 0 {
+			tags[len(tags)-1].value += "\n" + line
+		}
+	}
+	return tags, scanner.Err()
+}
+
+func (p *MT940Processor) parseStatementLine(tag mt940Tag) (Transaction, error) {
+	first, supplementary, _ := strings.Cut(tag.value, "\n")
+	match := mt940StatementLine.FindStringSubmatch(strings.TrimSpace(first))
+	if match == nil {
+		return Transaction{}, &ProcessingError{
+			Operation: "parse_transaction",
+			Err:       fmt.Errorf("invalid :61: statement line '%s'", first),
+			Line:      tag.line,
+		}
+	}
+
+	valueDate, err := time.Parse("060102", match[1])
+	if err != nil {
+		return Transaction{}, &ProcessingError{
+			Operation: "parse_date",
+			Err:       fmt.Errorf("invalid value date '%s': %v", match[1], err),
+			Line:      tag.line,
+		}
+	}
+	bookingDate := valueDate
+	if match[2] != "" {
+		bookingDate, err = mt940EntryDate(valueDate, match[2])
+		if err != nil {
+			return Transaction{}, &ProcessingError{
+				Operation: "parse_date",
+				Err:       err,
+				Line:      tag.line,
+			}
+		}
+	}
+
+	amount, err := ParseAmount(match[5], NumberFormat{DecimalSeparator: ","})
+	if err != nil {
+		return Transaction{}, &ProcessingError{
+			Operation: "parse_amount",
+			Err:       err,
+			Line:      tag.line,
+		}
+	}
+	// D and RC (reversal of credit) reduce the balance
+	if match[3] == "D" || match[3] == "RC" {
+		amount = amount.Neg()
+	}
+
+	customerRef := strings.TrimSpace(match[7])
+	bankRef := strings.TrimSpace(match[8])
+	reference := customerRef
+	if reference == "" || strings.EqualFold(reference, "NONREF") {
+		reference = bankRef
+	}
+
+	rawData := map[string]any{
+		"TransactionType": match[6],
+	}
+	addRawString(rawData, "CustomerReference", customerRef)
+	addRawString(rawData, "BankReference", bankRef)
+	addRawString(rawData, "SupplementaryDetails", supplementary)
+	if strings.HasPrefix(match[3], "R") {
+		rawData["Reversal"] = true
+	}
+
+	return Transaction{
+		Date:      bookingDate,
+		Amount:    amount,
+		Reference: reference,
+		RawData:   rawData,
+		Source:    "MT940",
+		ValueDate: valueDate,
+	}, nil
+}
+
+// mt940EntryDate resolves the MMDD entry date relative to the value date,
+// which may fall in the previous or next year around new year
+func mt940EntryDate(valueDate time.Time, mmdd string) (time.Time, error) {
+	entry, err := time.Parse("0102", mmdd)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid entry date '%s': %v", mmdd, err)
+	}
+	date := time.Date(valueDate.Year(), entry.Month(), entry.Day(), 0, 0, 0, 0, time.UTC)
+	switch {
+	case date.Sub(valueDate) > 180*24*time.Hour:
+		date = date.AddDate(-1, 0, 0)
+	case valueDate.Sub(date) > 180*24*time.Hour:
+		date = date.AddDate(1, 0, 0)
+	}
+	return date, nil
+}
+
+// applyMT940Narrative sets the description from the :86: information to account owner.
+// Structured narratives (e.g. "166?00GUTSCHRIFT?20...") are split into their subfields.
+func applyMT940Narrative(tx *Transaction, narrative string) {
+	text := strings.ReplaceAll(narrative, "\n", "")
+	tx.RawData["Narrative"] = strings.TrimSpace(strings.ReplaceAll(narrative, "\n", " "))
+
+	locs := mt940Subfield.FindAllStringSubmatchIndex(text, -1)
+	if len(locs) == 0 {
+		tx.Description = strings.Join(strings.Fields(strings.ReplaceAll(narrative, "\n", " ")), " ")
+		return
+	}
+
+	var purpose, name []string
+	for i, loc := range locs {
+		end := len(text)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		// Purpose subfields are fixed width continuations, so only the joined text is trimmed
+		code, value := text[loc[2]:loc[3]], text[loc[1]:end]
+		switch {
+		case code == "00":
+			addRawString(tx.RawData, "PostingText", value)
+		case code >= "20" && code <= "29", code >= "60" && code <= "63":
+			purpose = append(purpose, value)
+		case code == "30":
+			addRawString(tx.RawData, "CounterpartyBIC", value)
+		case code == "31":
+			addRawString(tx.RawData, "CounterpartyAccount", value)
+		case code == "32" || code == "33":
+			name = append(name, value)
+		}
+	}
+
+	counterparty := strings.TrimSpace(strings.Join(name, ""))
+	addRawString(tx.RawData, "CounterpartyName", counterparty)
+	tx.Description = firstNonEmpty(strings.Join(strings.Fields(strings.Join(purpose, "")), " "), counterparty)
+}
+
+// parseMT940Balance parses a balance value such as C250201SEK1000,00
+func parseMT940Balance(value string) (StatementBalance, error) {
+	match := mt940Balance.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return StatementBalance{}, fmt.Errorf("invalid balance '%s'", value)
+	}
+	date, err := time.Parse("060102", match[2])
+	if err != nil {
+		return StatementBalance{}, fmt.Errorf("invalid balance date '%s': %v", match[2], err)
+	}
+	amount, err := ParseAmount(match[4], NumberFormat{DecimalSeparator: ","})
+	if err != nil {
+		return StatementBalance{}, err
+	}
+	if match[1] == "D" {
+		amount = amount.Neg()
+	}
+	return StatementBalance{
+		Date:     date,
+		Amount:   amount,
+		Currency: match[3],
+	}, nil
+}
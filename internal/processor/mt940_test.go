@@ -0,0 +1,197 @@
+package processor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const testMT940 = `{1:F01ESSESESSAXXX0000000000}{2:O9401200250301ESSESESSXXXX00000000002503011200N}{4:
+:20:STMT-2025-02-1
+:25:SE4550000000058398257466
+:28C:00001/001
+:60F:C250201SEK1000,00
+:61:2502220224D249,50NTRFNONREF//SVC-1
+:86:Elräkning februari
+Vattenfall AB
+:61:250225C25000,00NSALLON2502
+:86:166?00GUTSCHRIFT?20Lön?21 februari?31SE3550000000054910000003
+?32Arbetsgivaren AB
+:62M:C250225SEK25750,50
+-
+:20:STMT-2025-02-2
+:25:SE4550000000058398257466
+:28C:00001/002
+:60M:C250225SEK25750,50
+:61:250228D100,00NCHGNONREF
+:86:Bankavgift
+:62F:C250228SEK25650,50
+-}`
+
+func TestMT940Processor_Successfully_process_statement(t *testing.T) {
+	processor := NewMT940Processor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if !processor.Detect([]byte(testMT940)) {
+		t.Fatal("Detect() = false, want true")
+	}
+
+	got, err := processor.ProcessDocument(context.Background(), strings.NewReader(testMT940))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Transaction{
+		{
+			Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("-249.50"),
+			Description: "Elräkning februari Vattenfall AB",
+			Reference:   "SVC-1",
+			ValueDate:   time.Date(2025, 2, 22, 0, 0, 0, 0, time.UTC),
+			RawData: map[string]any{
+				"TransactionType":    "NTRF",
+				"BankReference":      "SVC-1",
+				"StatementReference": "STMT-2025-02-1",
+			},
+		},
+		{
+			Date:        time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("25000"),
+			Description: "Lön februari",
+			Reference:   "LON2502",
+			RawData: map[string]any{
+				"PostingText":         "GUTSCHRIFT",
+				"CounterpartyName":    "Arbetsgivaren AB",
+				"CounterpartyAccount": "SE3550000000054910000003",
+			},
+		},
+		{
+			Date:        time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("-100"),
+			Description: "Bankavgift",
+			RawData: map[string]any{
+				"Account":            "SE4550000000058398257466",
+				"StatementReference": "STMT-2025-02-2",
+			},
+		},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d transactions, got %d", len(want), len(got))
+	}
+	for i, w := range want {
+		if !w.Date.Equal(got[i].Date) {
+			t.Errorf("transaction[%d].Date = %v, want %v", i, got[i].Date, w.Date)
+		}
+		if !w.Amount.Equal(got[i].Amount) {
+			t.Errorf("transaction[%d].Amount = %v, want %v", i, got[i].Amount, w.Amount)
+		}
+		if w.Description != got[i].Description {
+			t.Errorf("transaction[%d].Description = %q, want %q", i, got[i].Description, w.Description)
+		}
+		if w.Reference != got[i].Reference {
+			t.Errorf("transaction[%d].Reference = %q, want %q", i, got[i].Reference, w.Reference)
+		}
+		if got[i].Currency != "SEK" {
+			t.Errorf("transaction[%d].Currency = %q, want SEK", i, got[i].Currency)
+		}
+		if !w.ValueDate.IsZero() && !w.ValueDate.Equal(got[i].ValueDate) {
+			t.Errorf("transaction[%d].ValueDate = %v, want %v", i, got[i].ValueDate, w.ValueDate)
+		}
+		for k, v := range w.RawData {
+			if got[i].RawData[k] != v {
+				t.Errorf("transaction[%d].RawData[%q] = %v, want %v", i, k, got[i].RawData[k], v)
+			}
+		}
+	}
+
+	balances := processor.Balances()
+	if len(balances) != 4 {
+		t.Fatalf("expected 4 balances, got %d", len(balances))
+	}
+	checks := CheckBalances(got, balances)
+	if len(checks) != 1 {
+		t.Fatalf("expected 1 balance check, got %d", len(checks))
+	}
+	if !checks[0].OK() {
+		t.Errorf("balance check failed: %s", checks[0])
+	}
+	if !checks[0].Closing.Equal(decimal.RequireFromString("25650.50")) {
+		t.Errorf("closing balance = %v, want 25650.50", checks[0].Closing)
+	}
+}
+
+func TestMT940EntryDate(t *testing.T) {
+	tests := []struct {
+		name      string
+		valueDate time.Time
+		entry     string
+		want      time.Time
+	}{
+		{
+			name:      "Successfully_resolve_same_year",
+			valueDate: time.Date(2025, 2, 22, 0, 0, 0, 0, time.UTC),
+			entry:     "0224",
+			want:      time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "Successfully_resolve_next_year",
+			valueDate: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+			entry:     "0102",
+			want:      time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "Successfully_resolve_previous_year",
+			valueDate: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+			entry:     "1231",
+			want:      time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mt940EntryDate(tt.valueDate, tt.entry)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("mt940EntryDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMT940Processor_error_validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "Process_error_no_tags",
+			input:   "not an MT940 file",
+			wantErr: "no MT940 tags found",
+		},
+		{
+			name:    "Process_error_invalid_statement_line",
+			input:   ":20:REF\n:25:ACCOUNT\n:60F:C250201SEK0,00\n:61:invalid\n:62F:C250228SEK0,00\n",
+			wantErr: "no valid transactions found",
+		},
+	}
+
+	processor := NewMT940Processor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := processor.ProcessDocument(context.Background(), strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// nordeaLayout is Nordea's transaction export. Amounts are either a signed
+// Belopp column or separate Insättning and Uttag columns, where withdrawals
+// are listed as positive numbers.
+var nordeaLayout = bankCSVLayout{
+	delimiter:   ';',
+	required:    []string{"Bokföringsdag", "Rubrik"},
+	maxPreamble: 3,
+}
+
+// nordeaPending is the booking date Nordea uses for reserved, not yet booked, amounts
+const nordeaPending = "reserverat"
+
+// NordeaProcessor implements the Parser interface for Nordea CSV exports
+type NordeaProcessor struct {
+	rowRejections
+	logger       *slog.Logger
+	numberFormat NumberFormat
+}
+
+// NewNordeaProcessor creates a new Nordea CSV processor
+func NewNordeaProcessor(logger *slog.Logger) *NordeaProcessor {
+	return &NordeaProcessor{
+		logger:       logger,
+		numberFormat: NumberFormat{DecimalSeparator: ",", ThousandsSeparator: " "},
+	}
+}
+
+// Detect implements the Parser interface by looking for the Nordea header columns
+func (p *NordeaProcessor) Detect(sample []byte) bool {
+	return nordeaLayout.detect(sample)
+}
+
+// ProcessDocument implements the DocumentProcessor interface for Nordea CSV files.
+// Reserved amounts are skipped until they are booked.
+func (p *NordeaProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([]Transaction, error) {
+	p.resetRejections()
+	table, err := nordeaLayout.read(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !table.has("Belopp") && !(table.has("Insättning") && table.has("Uttag")) {
+		return nil, &ProcessingError{
+			Operation: "validate_header",
+			Err:       fmt.Errorf("missing amount columns: want Belopp or Insättning and Uttag"),
+		}
+	}
+
+	return processBankCSV(p.logger, "nordea", table, &p.rowRejections, func(record bankCSVRecord) (Transaction, bool, error) {
+		if strings.EqualFold(table.get(record, "Bokföringsdag"), nordeaPending) {
+			return Transaction{}, false, nil
+		}
+		trans, err := p.parseTransaction(table, record)
+		return trans, true, err
+	})
+}
+
+func (p *NordeaProcessor) parseTransaction(table *bankCSVTable, record bankCSVRecord) (Transaction, error) {
+	date, err := ParseDate(table.get(record, "Bokföringsdag"), "2006/01/02", "2006-01-02")
+	if err != nil {
+		return Transaction{}, &ProcessingError{
+			Operation: "parse_date",
+			Err:       err,
+			Line:      record.line,
+		}
+	}
+
+	amount, err := p.parseAmount(table, record)
+	if err != nil {
+		return Transaction{}, &ProcessingError{
+			Operation: "parse_amount",
+			Err:       err,
+			Line:      record.line,
+		}
+	}
+
+	balance, err := table.balance(record, "Saldo", p.numberFormat)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	rawData := map[string]any{}
+	addRawString(rawData, "CounterpartyName", table.get(record, "Namn"))
+	addRawString(rawData, "SenderAccount", table.get(record, "Avsändare"))
+	addRawString(rawData, "RecipientAccount", table.get(record, "Mottagare"))
+
+	return Transaction{
+		Date:        date,
+		Amount:      amount,
+		Description: firstNonEmpty(table.get(record, "Rubrik"), table.get(record, "Namn")),
+		RawData:     rawData,
+		Source:      "Nordea",
+		Currency:    table.get(record, "Valuta"),
+		Balance:     balance,
+	}, nil
+}
+
+// parseAmount returns the signed amount from Belopp, or from the deposit and
+// withdrawal columns where withdrawals count as negative whatever their sign
+func (p *NordeaProcessor) parseAmount(table *bankCSVTable, record bankCSVRecord) (decimal.Decimal, error) {
+	if table.has("Belopp") {
+		return ParseAmount(table.get(record, "Belopp"), p.numberFormat)
+	}
+
+	amount := decimal.Zero
+	if deposit := table.get(record, "Insättning"); deposit != "" {
+		value, err := ParseAmount(deposit, p.numberFormat)
+		if err != nil {
+			return decimal.Decimal{}, err
+		}
+		amount = amount.Add(value.Abs())
+	}
+	if withdrawal := table.get(record, "Uttag"); withdrawal != "" {
+		value, err := ParseAmount(withdrawal, p.numberFormat)
+		if err != nil {
+			return decimal.Decimal{}, err
+		}
+		amount = amount.Sub(value.Abs())
+	}
+	return amount, nil
+}
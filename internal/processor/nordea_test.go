@@ -0,0 +1,124 @@
+package processor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestNordeaProcessor_Successfully_process_valid_transactions(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Transaction
+	}{
+		{
+			name: "Successfully_process_signed_amount_export",
+			input: "\uFEFFBokföringsdag;Belopp;Avsändare;Mottagare;Namn;Rubrik;Saldo;Valuta\n" +
+				"Reserverat;-89,00;;;;Pressbyrån;;SEK\n" +
+				"2025/02/24;-1 249,50;3300 12 34567;;;Kortköp ICA Maxi;10 750,50;SEK\n" +
+				"2025/02/25;25 000,00;;3300 12 34567;Arbetsgivaren AB;;35 750,50;SEK\n",
+			want: []Transaction{
+				{
+					Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+					Amount:      decimal.RequireFromString("-1249.50"),
+					Description: "Kortköp ICA Maxi",
+					Source:      "Nordea",
+					Currency:    "SEK",
+					Balance:     decimal.NewNullDecimal(decimal.RequireFromString("10750.50")),
+					RawData: map[string]any{
+						"SenderAccount": "3300 12 34567",
+					},
+				},
+				{
+					Date:        time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC),
+					Amount:      decimal.RequireFromString("25000"),
+					Description: "Arbetsgivaren AB",
+					Source:      "Nordea",
+					Currency:    "SEK",
+					RawData: map[string]any{
+						"CounterpartyName": "Arbetsgivaren AB",
+						"RecipientAccount": "3300 12 34567",
+					},
+				},
+			},
+		},
+		{
+			name: "Successfully_process_deposit_and_withdrawal_columns",
+			input: latin1("Bokföringsdag;Rubrik;Insättning;Uttag;Saldo\n" +
+				"2025-02-24;Kortköp ICA Maxi;;1 249,50;10 750,50\n" +
+				"2025-02-25;Lön;25 000,00;;35 750,50\n" +
+				"2025-02-26;Överföring;;-500,00;35 250,50\n"),
+			want: []Transaction{
+				{
+					Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+					Amount:      decimal.RequireFromString("-1249.50"),
+					Description: "Kortköp ICA Maxi",
+					Source:      "Nordea",
+				},
+				{
+					Date:        time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC),
+					Amount:      decimal.RequireFromString("25000"),
+					Description: "Lön",
+					Source:      "Nordea",
+				},
+				{
+					Date:        time.Date(2025, 2, 26, 0, 0, 0, 0, time.UTC),
+					Amount:      decimal.RequireFromString("-500"),
+					Description: "Överföring",
+					Source:      "Nordea",
+				},
+			},
+		},
+	}
+
+	processor := NewNordeaProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertDetectedBank(t, tt.input, "nordea")
+
+			got, err := processor.ProcessDocument(context.Background(), strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertTransactions(t, got, tt.want)
+		})
+	}
+}
+
+func TestNordeaProcessor_error_validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "Process_error_missing_amount_columns",
+			input:   "Bokföringsdag;Rubrik;Saldo\n2025/02/24;ICA;10,00\n",
+			wantErr: "missing amount columns",
+		},
+		{
+			name:    "Process_error_only_reserved_amounts",
+			input:   "Bokföringsdag;Belopp;Rubrik\nReserverat;-89,00;Pressbyrån\n",
+			wantErr: "no valid transactions found",
+		},
+	}
+
+	processor := NewNordeaProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := processor.ProcessDocument(context.Background(), strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
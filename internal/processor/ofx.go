@@ -0,0 +1,325 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// ofxEntities replaces the XML entities allowed in OFX element values
+var ofxEntities = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ")
+
+// ofxStatement holds the statement level data collected while parsing
+type ofxStatement struct {
+	currency      string
+	accountID     string
+	ledgerBalance string
+	ledgerDate    string
+	availBalance  string
+	startDate     string
+	endDate       string
+}
+
+// OFXProcessor implements the Parser interface for OFX 1.x (SGML) and 2.x (XML) files, including QFX
+type OFXProcessor struct {
+	rowRejections
+	logger   *slog.Logger
+	balances []StatementBalance
+}
+
+// NewOFXProcessor creates a new OFX/QFX processor
+func NewOFXProcessor(logger *slog.Logger) *OFXProcessor {
+	return &OFXProcessor{
+		logger: logger,
+	}
+}
+
+// Detect implements the Parser interface by looking for the OFX header or root element
+func (p *OFXProcessor) Detect(sample []byte) bool {
+	upper := bytes.ToUpper(sample)
+	return bytes.Contains(upper, []byte("OFXHEADER")) || bytes.Contains(upper, []byte("<OFX>"))
+}
+
+// Balances implements the BalanceProvider interface. OFX states the ledger
+// balance at the end of the statement only, so no opening balance is returned.
+func (p *OFXProcessor) Balances() []StatementBalance {
+	return p.balances
+}
+
+// ProcessDocument implements the DocumentProcessor interface for OFX files
+func (p *OFXProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([]Transaction, error) {
+	p.resetRejections()
+	p.balances = nil
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, &ProcessingError{
+			Operation: "read_document",
+			Err:       err,
+		}
+	}
+
+	text := string(data)
+	start := strings.Index(strings.ToUpper(text), "<OFX>")
+	if start < 0 {
+		return nil, &ProcessingError{
+			Operation: "read_header",
+			Err:       fmt.Errorf("missing <OFX> root element"),
+		}
+	}
+
+	var (
+		transactions []Transaction
+		current      map[string]string
+		stmt         ofxStatement
+		path         []string
+	)
+
+	for _, tok := range tokenizeOFX(text[start:]) {
+		switch {
+		case tok.end:
+			if tok.name == "STMTTRN" && current != nil {
+				trans, err := p.parseTransaction(current)
+				if err != nil {
+					p.logger.Error("failed to parse transaction", "error", err, "raw_data", current)
+					p.reject(0, fmt.Errorf("transaction %s: %w", firstNonEmpty(current["FITID"], current["DTPOSTED"]), err), "")
+				} else {
+					transactions = append(transactions, trans)
+				}
+				current = nil
+			}
+			path = closeOFXElement(path, tok.name)
+		case !tok.leaf:
+			if tok.name == "STMTTRN" {
+				current = make(map[string]string)
+			}
+			path = append(path, tok.name)
+		default:
+			parent := ""
+			if len(path) > 0 {
+				parent = path[len(path)-1]
+			}
+			switch {
+			case current != nil:
+				current[tok.name] = tok.value
+			case tok.name == "CURDEF":
+				stmt.currency = strings.ToUpper(tok.value)
+			case tok.name == "ACCTID":
+				stmt.accountID = tok.value
+			case parent == "LEDGERBAL" && tok.name == "BALAMT":
+				stmt.ledgerBalance = tok.value
+			case parent == "LEDGERBAL" && tok.name == "DTASOF":
+				stmt.ledgerDate = tok.value
+			case parent == "AVAILBAL" && tok.name == "BALAMT":
+				stmt.availBalance = tok.value
+			case parent == "BANKTRANLIST" && tok.name == "DTSTART":
+				stmt.startDate = tok.value
+			case parent == "BANKTRANLIST" && tok.name == "DTEND":
+				stmt.endDate = tok.value
+			}
+		}
+	}
+
+	if len(transactions) == 0 {
+		p.logger.Warn("no transactions were found in the document")
+		return nil, &ProcessingError{
+			Operation: "process_document",
+			Err:       fmt.Errorf("no valid transactions found in document"),
+		}
+	}
+
+	// Statement level data is only known once the whole document has been read
+	for i := range transactions {
+		transactions[i].Currency = stmt.currency
+		if stmt.accountID != "" {
+			transactions[i].RawData["AccountID"] = stmt.accountID
+		}
+		if stmt.ledgerBalance != "" {
+			transactions[i].RawData["LedgerBalance"] = stmt.ledgerBalance
+			transactions[i].RawData["LedgerBalanceDate"] = formatOFXDate(stmt.ledgerDate)
+		}
+		if stmt.availBalance != "" {
+			transactions[i].RawData["AvailableBalance"] = stmt.availBalance
+		}
+		if stmt.startDate != "" && stmt.endDate != "" {
+			transactions[i].RawData["StatementStart"] = formatOFXDate(stmt.startDate)
+			transactions[i].RawData["StatementEnd"] = formatOFXDate(stmt.endDate)
+		}
+	}
+
+	if balance, err := stmt.closingBalance(); err != nil {
+		p.logger.Warn("failed to parse ledger balance", "error", err)
+	} else if balance != nil {
+		p.balances = append(p.balances, *balance)
+	}
+
+	p.logger.Info("successfully processed document",
+		"format", "ofx",
+		"currency", stmt.currency,
+		"total_transactions", len(transactions))
+	return transactions, nil
+}
+
+// closingBalance returns the ledger balance of the statement, or nil when it
+// states none
+func (s ofxStatement) closingBalance() (*StatementBalance, error) {
+	if s.ledgerBalance == "" || s.ledgerDate == "" {
+		return nil, nil
+	}
+	date, err := parseOFXDate(s.ledgerDate)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := ParseAmount(s.ledgerBalance, NumberFormat{DecimalSeparator: "."})
+	if err != nil {
+		if amount, err = ParseAmount(s.ledgerBalance, NumberFormat{DecimalSeparator: ","}); err != nil {
+			return nil, err
+		}
+	}
+	return &StatementBalance{
+		Date:     date,
+		Amount:   amount,
+		Account:  s.accountID,
+		Currency: s.currency,
+		Type:     BalanceClosing,
+	}, nil
+}
+
+func (p *OFXProcessor) parseTransaction(fields map[string]string) (Transaction, error) {
+	date, err := parseOFXDate(fields["DTPOSTED"])
+	if err != nil {
+		return Transaction{}, &ProcessingError{
+			Operation: "parse_date",
+			Err:       err,
+		}
+	}
+
+	amount, err := ParseAmount(fields["TRNAMT"], NumberFormat{DecimalSeparator: "."})
+	if err != nil {
+		// Some European banks write OFX amounts with a decimal comma
+		amount, err = ParseAmount(fields["TRNAMT"], NumberFormat{DecimalSeparator: ","})
+		if err != nil {
+			return Transaction{}, &ProcessingError{
+				Operation: "parse_amount",
+				Err:       err,
+			}
+		}
+	}
+
+	description := fields["NAME"]
+	if description == "" {
+		description = fields["MEMO"]
+	}
+
+	rawData := map[string]any{
+		"TransactionType": fields["TRNTYPE"],
+	}
+	if memo := fields["MEMO"]; memo != "" {
+		rawData["Memo"] = memo
+	}
+	if userDate := fields["DTUSER"]; userDate != "" {
+		rawData["ValueDate"] = formatOFXDate(userDate)
+	}
+	if checkNum := fields["CHECKNUM"]; checkNum != "" {
+		rawData["CheckNumber"] = checkNum
+	}
+
+	return Transaction{
+		Date:        date,
+		Amount:      amount,
+		Description: strings.TrimSpace(description),
+		Reference:   fields["FITID"],
+		RawData:     rawData,
+		Source:      "OFX",
+	}, nil
+}
+
+// ofxToken is an element start, element end or a leaf element with its value
+type ofxToken struct {
+	name  string
+	value string
+	end   bool
+	leaf  bool
+}
+
+// tokenizeOFX splits an OFX body into tokens. It handles both SGML, where leaf
+// elements are not closed, and XML, where every element has an end tag.
+func tokenizeOFX(body string) []ofxToken {
+	var tokens []ofxToken
+	for {
+		open := strings.IndexByte(body, '<')
+		if open < 0 {
+			break
+		}
+		body = body[open+1:]
+		end := strings.IndexByte(body, '>')
+		if end < 0 {
+			break
+		}
+		tag := strings.TrimSpace(body[:end])
+		body = body[end+1:]
+
+		// Skip processing instructions and comments
+		if strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!") {
+			continue
+		}
+		if strings.HasPrefix(tag, "/") {
+			tokens = append(tokens, ofxToken{name: strings.ToUpper(tag[1:]), end: true})
+			continue
+		}
+
+		name := strings.ToUpper(strings.Fields(tag + " ")[0])
+		next := strings.IndexByte(body, '<')
+		if next < 0 {
+			next = len(body)
+		}
+		value := strings.TrimSpace(body[:next])
+		if value == "" {
+			tokens = append(tokens, ofxToken{name: name})
+			continue
+		}
+
+		tokens = append(tokens, ofxToken{name: name, value: ofxEntities.Replace(value), leaf: true})
+		body = body[next:]
+		// Consume the matching end tag of an XML leaf element
+		if closing := "</" + name + ">"; len(body) >= len(closing) && strings.EqualFold(body[:len(closing)], closing) {
+			body = body[len(closing):]
+		}
+	}
+	return tokens
+}
+
+// closeOFXElement pops the path up to and including the named element
+func closeOFXElement(path []string, name string) []string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == name {
+			return path[:i]
+		}
+	}
+	return path
+}
+
+// parseOFXDate parses the date part of an OFX datetime such as 20250224120000.000[-5:EST]
+func parseOFXDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date format '%s'", value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date format '%s': %v", value, err)
+	}
+	return date, nil
+}
+
+// formatOFXDate returns an OFX datetime as YYYY-MM-DD, or the raw value if it cannot be parsed
+func formatOFXDate(value string) string {
+	date, err := parseOFXDate(value)
+	if err != nil {
+		return value
+	}
+	return date.Format("2006-01-02")
+}
@@ -0,0 +1,231 @@
+package processor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const testOFXSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+SECURITY:NONE
+ENCODING:USASCII
+CHARSET:1252
+COMPRESSION:NONE
+OLDFILEUID:NONE
+NEWFILEUID:NONE
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0<SEVERITY>INFO</STATUS><DTSERVER>20250301120000<LANGUAGE>ENG</SONRS></SIGNONMSGSRSV1>
+<CREDITCARDMSGSRSV1>
+<CCSTMTTRNRS>
+<CCSTMTRS>
+<CURDEF>EUR
+<CCACCTFROM><ACCTID>4111111111111111</CCACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20250201
+<DTEND>20250228
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20250224120000.000[-5:EST]
+<DTUSER>20250222
+<TRNAMT>-42.50
+<FITID>2025022401
+<NAME>Café &amp; Bar
+<MEMO>Card purchase
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20250225
+<TRNAMT>100.00
+<FITID>2025022502
+<MEMO>Refund
+</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL>
+<BALAMT>-1234.56
+<DTASOF>20250228
+</LEDGERBAL>
+</CCSTMTRS>
+</CCSTMTTRNRS>
+</CREDITCARDMSGSRSV1>
+</OFX>`
+
+const testOFXXML = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+  <BANKMSGSRSV1>
+    <STMTTRNRS>
+      <STMTRS>
+        <CURDEF>USD</CURDEF>
+        <BANKACCTFROM><BANKID>123</BANKID><ACCTID>987654</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+        <BANKTRANLIST>
+          <STMTTRN>
+            <TRNTYPE>DEBIT</TRNTYPE>
+            <DTPOSTED>20250224</DTPOSTED>
+            <TRNAMT>-12.34</TRNAMT>
+            <FITID>ABC123</FITID>
+            <NAME>Coffee Shop</NAME>
+            <MEMO></MEMO>
+          </STMTTRN>
+        </BANKTRANLIST>
+        <LEDGERBAL><BALAMT>500.00</BALAMT><DTASOF>20250228</DTASOF></LEDGERBAL>
+        <AVAILBAL><BALAMT>450.00</BALAMT><DTASOF>20250228</DTASOF></AVAILBAL>
+      </STMTRS>
+    </STMTTRNRS>
+  </BANKMSGSRSV1>
+</OFX>`
+
+func TestOFXProcessor_Successfully_process_statements(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantCurrency string
+		want         []Transaction
+	}{
+		{
+			name:         "Successfully_process_ofx1_sgml",
+			input:        testOFXSGML,
+			wantCurrency: "EUR",
+			want: []Transaction{
+				{
+					Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+					Amount:      decimal.RequireFromString("-42.50"),
+					Description: "Café & Bar",
+					Reference:   "2025022401",
+					RawData: map[string]any{
+						"LedgerBalance":     "-1234.56",
+						"LedgerBalanceDate": "2025-02-28",
+						"ValueDate":         "2025-02-22",
+						"AccountID":         "4111111111111111",
+					},
+				},
+				{
+					Date:        time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC),
+					Amount:      decimal.RequireFromString("100"),
+					Description: "Refund",
+					Reference:   "2025022502",
+					RawData:     map[string]any{"LedgerBalance": "-1234.56"},
+				},
+			},
+		},
+		{
+			name:         "Successfully_process_ofx2_xml",
+			input:        testOFXXML,
+			wantCurrency: "USD",
+			want: []Transaction{
+				{
+					Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+					Amount:      decimal.RequireFromString("-12.34"),
+					Description: "Coffee Shop",
+					Reference:   "ABC123",
+					RawData: map[string]any{
+						"LedgerBalance":    "500.00",
+						"AvailableBalance": "450.00",
+						"AccountID":        "987654",
+					},
+				},
+			},
+		},
+	}
+
+	processor := NewOFXProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !processor.Detect([]byte(tt.input)) {
+				t.Fatal("Detect() = false, want true")
+			}
+
+			got, err := processor.ProcessDocument(context.Background(), strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d transactions, got %d", len(tt.want), len(got))
+			}
+
+			for i, want := range tt.want {
+				if !want.Date.Equal(got[i].Date) {
+					t.Errorf("transaction[%d].Date = %v, want %v", i, got[i].Date, want.Date)
+				}
+				if !want.Amount.Equal(got[i].Amount) {
+					t.Errorf("transaction[%d].Amount = %v, want %v", i, got[i].Amount, want.Amount)
+				}
+				if want.Description != got[i].Description {
+					t.Errorf("transaction[%d].Description = %q, want %q", i, got[i].Description, want.Description)
+				}
+				if want.Reference != got[i].Reference {
+					t.Errorf("transaction[%d].Reference = %q, want %q", i, got[i].Reference, want.Reference)
+				}
+				if got[i].Currency != tt.wantCurrency {
+					t.Errorf("transaction[%d].Currency = %q, want %q", i, got[i].Currency, tt.wantCurrency)
+				}
+				for k, v := range want.RawData {
+					if got[i].RawData[k] != v {
+						t.Errorf("transaction[%d].RawData[%q] = %v, want %v", i, k, got[i].RawData[k], v)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestOFXProcessor_error_validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "Process_error_missing_root",
+			input:   "OFXHEADER:100\n\n<NOTOFX></NOTOFX>",
+			wantErr: "missing <OFX> root element",
+		},
+		{
+			name:    "Process_error_no_transactions",
+			input:   "<OFX><BANKTRANLIST></BANKTRANLIST></OFX>",
+			wantErr: "no valid transactions found",
+		},
+		{
+			name:    "Process_error_invalid_date",
+			input:   "<OFX><STMTTRN><DTPOSTED>bad<TRNAMT>1.00<FITID>1</STMTTRN></OFX>",
+			wantErr: "no valid transactions found",
+		},
+	}
+
+	processor := NewOFXProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := processor.ProcessDocument(context.Background(), strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOFXProcessor_Successfully_read_ledger_balance(t *testing.T) {
+	processor := NewOFXProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, err := processor.ProcessDocument(context.Background(), strings.NewReader(testOFXSGML)); err != nil {
+		t.Fatalf("ProcessDocument() unexpected error: %v", err)
+	}
+
+	balances := processor.Balances()
+	if len(balances) != 1 {
+		t.Fatalf("Balances() returned %d balances, want 1", len(balances))
+	}
+	got := balances[0]
+	if got.Type != BalanceClosing || got.Amount.StringFixed(2) != "-1234.56" || got.Date.Format("2006-01-02") != "2025-02-28" ||
+		got.Account != "4111111111111111" || got.Currency != "EUR" {
+		t.Errorf("Balances() = %+v, want closing -1234.56 EUR on 2025-02-28 for 4111111111111111", got)
+	}
+}
@@ -0,0 +1,404 @@
+package processor
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+)
+
+// Profile file formats
+const (
+	ProfileFormatCSV  = "csv"
+	ProfileFormatXLSX = "xlsx"
+)
+
+// Sign conventions for amount columns
+const (
+	SignNormal   = "normal"   // Negative amounts are expenses
+	SignInverted = "inverted" // Positive amounts are expenses
+)
+
+// CSVColumns maps transaction fields to header names in a CSV export
+type CSVColumns struct {
+	Date        string `mapstructure:"date" json:"date"`
+	ValueDate   string `mapstructure:"value_date" json:"value_date,omitempty"`
+	Description string `mapstructure:"description" json:"description"`
+	Amount      string `mapstructure:"amount" json:"amount"`
+	Reference   string `mapstructure:"reference" json:"reference,omitempty"`
+	Balance     string `mapstructure:"balance" json:"balance,omitempty"`
+}
+
+// CSVProfile declares how a bank's CSV or XLSX export is mapped onto transactions,
+// so that new banks can be onboarded from configuration instead of code
+type CSVProfile struct {
+	Columns            CSVColumns `mapstructure:"columns" json:"columns"`
+	ID                 string     `mapstructure:"id" json:"id"`
+	Name               string     `mapstructure:"name" json:"name,omitempty"`
+	Format             string     `mapstructure:"format" json:"format,omitempty"`
+	Sheet              string     `mapstructure:"sheet" json:"sheet,omitempty"`
+	Delimiter          string     `mapstructure:"delimiter" json:"delimiter,omitempty"`
+	Encoding           string     `mapstructure:"encoding" json:"encoding,omitempty"`
+	DateLayout         string     `mapstructure:"date_layout" json:"date_layout,omitempty"`
+	DecimalSeparator   string     `mapstructure:"decimal_separator" json:"decimal_separator,omitempty"`
+	ThousandsSeparator string     `mapstructure:"thousands_separator" json:"thousands_separator,omitempty"`
+	SignConvention     string     `mapstructure:"sign_convention" json:"sign_convention,omitempty"`
+	SkipLines          int        `mapstructure:"skip_lines" json:"skip_lines,omitempty"`
+}
+
+// Validate checks the profile and fills in defaults for optional settings
+func (p *CSVProfile) Validate() error {
+	p.ID = normalizeParserID(p.ID)
+	if p.ID == "" {
+		return fmt.Errorf("profile id is required")
+	}
+	if p.Columns.Date == "" || p.Columns.Description == "" || p.Columns.Amount == "" {
+		return fmt.Errorf("profile %q: date, description and amount columns are required", p.ID)
+	}
+	if p.Name == "" {
+		p.Name = p.ID
+	}
+	switch strings.ToLower(p.Format) {
+	case "", ProfileFormatCSV:
+		p.Format = ProfileFormatCSV
+	case ProfileFormatXLSX:
+		p.Format = ProfileFormatXLSX
+	default:
+		return fmt.Errorf("profile %q: unsupported format %q", p.ID, p.Format)
+	}
+	if p.Delimiter == "" {
+		p.Delimiter = ";"
+	}
+	if p.Delimiter == `\t` {
+		p.Delimiter = "\t"
+	}
+	if utf8.RuneCountInString(p.Delimiter) != 1 {
+		return fmt.Errorf("profile %q: delimiter must be a single character", p.ID)
+	}
+	if p.Encoding == "" {
+		p.Encoding = EncodingAuto
+	}
+	encoding, err := normalizeEncoding(p.Encoding)
+	if err != nil {
+		return fmt.Errorf("profile %q: %w", p.ID, err)
+	}
+	p.Encoding = encoding
+	if p.DateLayout == "" {
+		p.DateLayout = "2006-01-02"
+	}
+	if p.DecimalSeparator == "" {
+		p.DecimalSeparator = ","
+	}
+	switch strings.ToLower(p.SignConvention) {
+	case "", SignNormal:
+		p.SignConvention = SignNormal
+	case SignInverted:
+		p.SignConvention = SignInverted
+	default:
+		return fmt.Errorf("profile %q: invalid sign convention %q", p.ID, p.SignConvention)
+	}
+	if p.SkipLines < 0 {
+		return fmt.Errorf("profile %q: skip_lines cannot be negative", p.ID)
+	}
+	return nil
+}
+
+// ProfileProcessor implements the Parser interface for CSV files described by a CSVProfile
+type ProfileProcessor struct {
+	rowRejections
+	logger  *slog.Logger
+	profile CSVProfile
+}
+
+// NewProfileProcessor creates a CSV processor for the given profile
+func NewProfileProcessor(profile CSVProfile, logger *slog.Logger) (*ProfileProcessor, error) {
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	return &ProfileProcessor{
+		logger:  logger,
+		profile: profile,
+	}, nil
+}
+
+// NewProfileParser creates the CSV or XLSX parser for the profile's format
+func NewProfileParser(profile CSVProfile, logger *slog.Logger) (Parser, error) {
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	if profile.Format == ProfileFormatXLSX {
+		return newXLSXProcessor(profile, logger), nil
+	}
+	return &ProfileProcessor{logger: logger, profile: profile}, nil
+}
+
+// RegisterProfile validates the profile and registers it under its id
+func RegisterProfile(r *Registry, profile CSVProfile) error {
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+	return r.Register(ParserInfo{
+		ID:          profile.ID,
+		Name:        profile.Name,
+		Format:      profile.Format,
+		Description: fmt.Sprintf("%s %s export (mapping profile)", profile.Name, strings.ToUpper(profile.Format)),
+	}, func(logger *slog.Logger) Parser {
+		parser, _ := NewProfileParser(profile, logger)
+		return parser
+	})
+}
+
+// Profile returns the validated profile used by the processor
+func (p *ProfileProcessor) Profile() CSVProfile {
+	return p.profile
+}
+
+// Detect implements the Parser interface by checking that all mapped columns are present in the header
+func (p *ProfileProcessor) Detect(sample []byte) bool {
+	text, err := decodeBytes(sample, p.profile.Encoding)
+	if err != nil {
+		return false
+	}
+
+	lines := strings.SplitN(text, "\n", p.profile.SkipLines+2)
+	if len(lines) <= p.profile.SkipLines {
+		return false
+	}
+	header := strings.Split(firstLine([]byte(lines[p.profile.SkipLines])), p.profile.Delimiter)
+	_, err = p.columnIndices(header)
+	return err == nil
+}
+
+// ProcessDocument implements the DocumentProcessor interface
+func (p *ProfileProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([]Transaction, error) {
+	p.resetRejections()
+	decoded, err := decodeReader(reader, p.profile.Encoding)
+	if err != nil {
+		return nil, &ProcessingError{
+			Operation: "decode",
+			Err:       err,
+		}
+	}
+
+	// Skip preamble lines verbatim, since the CSV reader ignores blank lines
+	buffered := bufio.NewReader(decoded)
+	lineNum := 0
+	for lineNum < p.profile.SkipLines {
+		if _, err := buffered.ReadString('\n'); err != nil {
+			return nil, &ProcessingError{
+				Operation: "skip_lines",
+				Err:       err,
+				Line:      lineNum + 1,
+			}
+		}
+		lineNum++
+	}
+
+	csvReader := csv.NewReader(buffered)
+	csvReader.Comma, _ = utf8.DecodeRuneInString(p.profile.Delimiter)
+	csvReader.TrimLeadingSpace = true
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, &ProcessingError{
+			Operation: "read_header",
+			Err:       err,
+		}
+	}
+	headerLine, _ := csvReader.FieldPos(0)
+	lineNum += headerLine
+
+	indices, err := p.columnIndices(header)
+	if err != nil {
+		return nil, &ProcessingError{
+			Operation: "validate_header",
+			Err:       err,
+			Line:      lineNum,
+		}
+	}
+
+	var transactions []Transaction
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			line := lineNum
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				line = p.profile.SkipLines + parseErr.Line
+			}
+			return nil, &ProcessingError{
+				Operation: "read_record",
+				Err:       err,
+				Line:      line,
+			}
+		}
+		recordLine, _ := csvReader.FieldPos(0)
+		lineNum = p.profile.SkipLines + recordLine
+		if isBlankRecord(record) {
+			continue
+		}
+
+		trans, err := p.parseTransaction(record, indices, lineNum)
+		if err != nil {
+			p.logger.Error("failed to parse transaction",
+				"profile", p.profile.ID,
+				"line", lineNum,
+				"error", err,
+				"raw_data", record)
+			p.reject(lineNum, err, strings.Join(record, p.profile.Delimiter))
+			continue
+		}
+		transactions = append(transactions, trans)
+	}
+
+	if len(transactions) == 0 {
+		return nil, &ProcessingError{
+			Operation: "process_document",
+			Err:       fmt.Errorf("no valid transactions found in document"),
+		}
+	}
+
+	p.logger.Info("successfully processed document",
+		"profile", p.profile.ID,
+		"total_transactions", len(transactions))
+	return transactions, nil
+}
+
+// profileColumns holds the resolved column index per field, -1 when not mapped
+type profileColumns struct {
+	date, valueDate, description, amount, reference, balance int
+}
+
+// columnIndices resolves the configured header names against the header row.
+// Every mapped column must be present; unmapped optional columns resolve to -1.
+func (p *ProfileProcessor) columnIndices(header []string) (profileColumns, error) {
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.Trim(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")), `"`)
+		positions[strings.ToLower(name)] = i
+	}
+
+	var lookupErr error
+	lookup := func(name string) int {
+		if name == "" || lookupErr != nil {
+			return -1
+		}
+		idx, ok := positions[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			lookupErr = fmt.Errorf("missing column %q", name)
+			return -1
+		}
+		return idx
+	}
+
+	cols := profileColumns{
+		date:        lookup(p.profile.Columns.Date),
+		valueDate:   lookup(p.profile.Columns.ValueDate),
+		description: lookup(p.profile.Columns.Description),
+		amount:      lookup(p.profile.Columns.Amount),
+		reference:   lookup(p.profile.Columns.Reference),
+		balance:     lookup(p.profile.Columns.Balance),
+	}
+	if lookupErr != nil {
+		return profileColumns{}, lookupErr
+	}
+	return cols, nil
+}
+
+func (p *ProfileProcessor) parseTransaction(record []string, cols profileColumns, lineNum int) (Transaction, error) {
+	field := func(idx int) string {
+		if idx < 0 || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	if cols.amount >= len(record) || cols.date >= len(record) {
+		return Transaction{}, &ProcessingError{
+			Operation: "parse_transaction",
+			Err:       fmt.Errorf("invalid number of fields: got %d", len(record)),
+			Line:      lineNum,
+		}
+	}
+
+	dateStr := field(cols.date)
+	date, err := ParseDate(dateStr, p.profile.DateLayout)
+	if err != nil {
+		return Transaction{}, &ProcessingError{
+			Operation: "parse_date",
+			Err:       err,
+			Line:      lineNum,
+		}
+	}
+
+	numberFormat := NumberFormat{
+		DecimalSeparator:   p.profile.DecimalSeparator,
+		ThousandsSeparator: p.profile.ThousandsSeparator,
+	}
+	amount, err := ParseAmount(field(cols.amount), numberFormat)
+	if err != nil {
+		return Transaction{}, &ProcessingError{
+			Operation: "parse_amount",
+			Err:       err,
+			Line:      lineNum,
+		}
+	}
+	if p.profile.SignConvention == SignInverted {
+		amount = amount.Neg()
+	}
+
+	trans := Transaction{
+		Date:        date,
+		Amount:      amount,
+		Description: field(cols.description),
+		Reference:   field(cols.reference),
+		RawData:     map[string]any{"Profile": p.profile.ID},
+		Source:      p.profile.Name,
+	}
+
+	if valueDateStr := field(cols.valueDate); valueDateStr != "" {
+		trans.ValueDate, err = ParseDate(valueDateStr, p.profile.DateLayout)
+		if err != nil {
+			return Transaction{}, &ProcessingError{
+				Operation: "parse_value_date",
+				Err:       err,
+				Line:      lineNum,
+			}
+		}
+	}
+	if balanceStr := field(cols.balance); balanceStr != "" {
+		balance, err := ParseAmount(balanceStr, numberFormat)
+		if err != nil {
+			return Transaction{}, &ProcessingError{
+				Operation: "parse_balance",
+				Err:       err,
+				Line:      lineNum,
+			}
+		}
+		trans.Balance = decimal.NewNullDecimal(balance)
+	}
+
+	return trans, nil
+}
+
+// isBlankRecord reports whether all fields of a CSV record are empty
+func isBlankRecord(record []string) bool {
+	for _, f := range record {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
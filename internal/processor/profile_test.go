@@ -0,0 +1,202 @@
+package processor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func createTestProfile() CSVProfile {
+	return CSVProfile{
+		ID:                 "testbank",
+		Name:               "Test Bank",
+		Delimiter:          ";",
+		Encoding:           "iso-8859-1",
+		DateLayout:         "02.01.2006",
+		DecimalSeparator:   ",",
+		ThousandsSeparator: " ",
+		SignConvention:     SignInverted,
+		SkipLines:          2,
+		Columns: CSVColumns{
+			Date:        "Datum",
+			Description: "Händelse",
+			Amount:      "Belopp",
+			Balance:     "Saldo",
+		},
+	}
+}
+
+// latin1 encodes a string containing only Latin-1 characters as ISO-8859-1 bytes
+func latin1(s string) string {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		b = append(b, byte(r))
+	}
+	return string(b)
+}
+
+func TestProfileProcessor_Successfully_process_mapped_csv(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	processor, err := NewProfileProcessor(createTestProfile(), logger)
+	if err != nil {
+		t.Fatalf("NewProfileProcessor() unexpected error: %v", err)
+	}
+
+	input := latin1("Kontoutdrag Test Bank\n\nDatum;Händelse;Belopp;Saldo\n" +
+		"24.02.2025;Kafé Åre;1 234,50;10 000,00\n" +
+		";;;\n" +
+		"25.02.2025;Lön;-25 000,00;35 000,00\n")
+
+	got, err := processor.ProcessDocument(context.Background(), strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ProcessDocument() unexpected error: %v", err)
+	}
+
+	want := []Transaction{
+		{
+			Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("-1234.50"),
+			Description: "Kafé Åre",
+			Source:      "Test Bank",
+			Balance:     decimal.NewNullDecimal(decimal.RequireFromString("10000")),
+			RawData:     map[string]any{"Profile": "testbank"},
+		},
+		{
+			Date:        time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("25000"),
+			Description: "Lön",
+			Source:      "Test Bank",
+			Balance:     decimal.NewNullDecimal(decimal.RequireFromString("35000")),
+			RawData:     map[string]any{"Profile": "testbank"},
+		},
+	}
+	assertTransactions(t, got, want)
+}
+
+func TestProfileProcessor_Successfully_report_rejected_rows(t *testing.T) {
+	profile := createTestProfile()
+	profile.Encoding = ""
+	processor, err := NewProfileProcessor(profile, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewProfileProcessor() unexpected error: %v", err)
+	}
+	if processor.Profile().Encoding != EncodingAuto {
+		t.Errorf("Encoding = %q, want %q", processor.Profile().Encoding, EncodingAuto)
+	}
+
+	input := latin1("Kontoutdrag Test Bank\n\nDatum;Händelse;Belopp;Saldo\n" +
+		"24.02.2025;Kafé Åre;1 234,50;10 000,00\n" +
+		"\n" +
+		"2025-02-25;Lön;-25 000,00;35 000,00\n" +
+		"26.02.2025;Återköp;12,3,4;35 000,00\n")
+
+	got, err := processor.ProcessDocument(context.Background(), strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ProcessDocument() unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].Description != "Kafé Åre" {
+		t.Fatalf("ProcessDocument() = %v, want the Kafé Åre row only", got)
+	}
+
+	want := []RowError{
+		{Line: 6, Reason: "parse_date: invalid date format '2025-02-25'", Raw: "2025-02-25;Lön;-25 000,00;35 000,00"},
+		{Line: 7, Reason: "parse_amount: invalid amount format '12,3,4'", Raw: "26.02.2025;Återköp;12,3,4;35 000,00"},
+	}
+	rejected := processor.RejectedRows()
+	if len(rejected) != len(want) {
+		t.Fatalf("expected %d rejected rows, got %d: %v", len(want), len(rejected), rejected)
+	}
+	for i, w := range want {
+		if rejected[i] != w {
+			t.Errorf("rejected[%d] = %+v, want %+v", i, rejected[i], w)
+		}
+	}
+}
+
+func TestProfileProcessor_Successfully_detect_header(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := NewDefaultRegistry()
+	if err := RegisterProfile(registry, createTestProfile()); err != nil {
+		t.Fatalf("RegisterProfile() unexpected error: %v", err)
+	}
+
+	sample := latin1("Kontoutdrag Test Bank\n\nDatum;Händelse;Belopp;Saldo\r\n24.02.2025;Kafé;1,00;2,00\n")
+	_, info, err := registry.Detect([]byte(sample), logger)
+	if err != nil {
+		t.Fatalf("Detect() unexpected error: %v", err)
+	}
+	if info.ID != "testbank" {
+		t.Errorf("Detect() id = %q, want %q", info.ID, "testbank")
+	}
+}
+
+func TestProfileProcessor_error_validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(p *CSVProfile)
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "Profile_error_missing_id",
+			modify:  func(p *CSVProfile) { p.ID = "" },
+			wantErr: "profile id is required",
+		},
+		{
+			name:    "Profile_error_missing_amount_column",
+			modify:  func(p *CSVProfile) { p.Columns.Amount = "" },
+			wantErr: "columns are required",
+		},
+		{
+			name:    "Profile_error_invalid_delimiter",
+			modify:  func(p *CSVProfile) { p.Delimiter = ";;" },
+			wantErr: "single character",
+		},
+		{
+			name:    "Profile_error_unsupported_encoding",
+			modify:  func(p *CSVProfile) { p.Encoding = "ebcdic" },
+			wantErr: "unsupported encoding",
+		},
+		{
+			name:    "Profile_error_invalid_sign_convention",
+			modify:  func(p *CSVProfile) { p.SignConvention = "sideways" },
+			wantErr: "invalid sign convention",
+		},
+		{
+			name:    "Process_error_missing_header_column",
+			modify:  func(p *CSVProfile) { p.SkipLines = 0 },
+			input:   "Datum;Text;Belopp;Saldo\n24.02.2025;x;1,00;2,00\n",
+			wantErr: `missing column "Händelse"`,
+		},
+		{
+			name:    "Process_error_no_valid_rows",
+			modify:  func(p *CSVProfile) { p.SkipLines = 0 },
+			input:   latin1("Datum;Händelse;Belopp;Saldo\n2025-02-24;x;1,00;2,00\n"),
+			wantErr: "no valid transactions found",
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := createTestProfile()
+			tt.modify(&profile)
+
+			processor, err := NewProfileProcessor(profile, logger)
+			if err == nil {
+				_, err = processor.ProcessDocument(context.Background(), strings.NewReader(tt.input))
+			}
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
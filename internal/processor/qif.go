@@ -0,0 +1,306 @@
+package processor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// qifDateLayouts are the date layouts tried in order when parsing QIF D fields
+var qifDateLayouts = []string{
+	"01/02/2006",
+	"1/2/2006",
+	"01/02'06",
+	"1/2'06",
+	"1/ 2'06",
+	"01/02/06",
+	"2006-01-02",
+	"02.01.2006",
+}
+
+// qifTransactionTypes are the QIF section types that contain cash transactions
+var qifTransactionTypes = map[string]bool{
+	"bank":  true,
+	"ccard": true,
+	"cash":  true,
+	"oth a": true,
+	"oth l": true,
+}
+
+// qifSplit is a single S/E/$ split of a QIF transaction
+type qifSplit struct {
+	category string
+	memo     string
+	amount   string
+}
+
+// qifRecord holds the fields of a QIF record up to its ^ terminator
+type qifRecord struct {
+	fields map[byte]string
+	splits []qifSplit
+	line   int
+}
+
+// QIFProcessor implements the Parser interface for Quicken Interchange Format files
+type QIFProcessor struct {
+	rowRejections
+	logger       *slog.Logger
+	numberFormat NumberFormat
+}
+
+// NewQIFProcessor creates a new QIF processor using US number formatting
+func NewQIFProcessor(logger *slog.Logger) *QIFProcessor {
+	return &QIFProcessor{
+		logger:       logger,
+		numberFormat: NumberFormat{DecimalSeparator: ".", ThousandsSeparator: ","},
+	}
+}
+
+// Detect implements the Parser interface by looking for a QIF section header
+func (p *QIFProcessor) Detect(sample []byte) bool {
+	line := strings.ToLower(strings.TrimSpace(firstLine(sample)))
+	return strings.HasPrefix(line, "!type:") || strings.HasPrefix(line, "!account") || strings.HasPrefix(line, "!option:")
+}
+
+// ProcessDocument implements the DocumentProcessor interface for QIF files.
+// Split transactions produce one transaction per split line.
+func (p *QIFProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([]Transaction, error) {
+	p.resetRejections()
+	scanner := bufio.NewScanner(reader)
+
+	var (
+		transactions []Transaction
+		section      string
+		account      string
+		record       = newQIFRecord(1)
+		lineNum      = 0
+		sawHeader    = false
+	)
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNum == 1 {
+			line = strings.TrimPrefix(line, "\uFEFF")
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "!") {
+			header := strings.ToLower(strings.TrimSpace(line[1:]))
+			switch {
+			case strings.HasPrefix(header, "type:"):
+				section = strings.TrimSpace(strings.TrimPrefix(header, "type:"))
+			case header == "account":
+				section = "account"
+			default:
+				// Options such as !Option:AutoSwitch do not start a new section
+				continue
+			}
+			sawHeader = true
+			record = newQIFRecord(lineNum + 1)
+			continue
+		}
+
+		if line[0] == '^' {
+			switch {
+			case section == "account":
+				account = record.fields['N']
+			case qifTransactionTypes[section]:
+				parsed, err := p.parseRecord(record, account)
+				if err != nil {
+					p.logger.Error("failed to parse transaction",
+						"line", record.line,
+						"error", err,
+						"raw_data", record.fields)
+					p.reject(record.line, err, "")
+				} else {
+					transactions = append(transactions, parsed...)
+				}
+			}
+			record = newQIFRecord(lineNum + 1)
+			continue
+		}
+
+		code, value := line[0], strings.TrimSpace(line[1:])
+		switch code {
+		case 'S':
+			record.splits = append(record.splits, qifSplit{category: value})
+		case 'E':
+			if n := len(record.splits); n > 0 {
+				record.splits[n-1].memo = value
+			}
+		case '$':
+			if n := len(record.splits); n > 0 {
+				record.splits[n-1].amount = value
+			}
+		default:
+			record.fields[code] = value
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, &ProcessingError{
+			Operation: "read_record",
+			Err:       err,
+			Line:      lineNum,
+		}
+	}
+	if !sawHeader {
+		return nil, &ProcessingError{
+			Operation: "read_header",
+			Err:       fmt.Errorf("missing !Type header"),
+		}
+	}
+	if len(transactions) == 0 {
+		p.logger.Warn("no transactions were found in the document")
+		return nil, &ProcessingError{
+			Operation: "process_document",
+			Err:       fmt.Errorf("no valid transactions found in document"),
+		}
+	}
+
+	p.logger.Info("successfully processed document",
+		"format", "qif",
+		"total_transactions", len(transactions))
+	return transactions, nil
+}
+
+func newQIFRecord(line int) *qifRecord {
+	return &qifRecord{fields: make(map[byte]string), line: line}
+}
+
+func (p *QIFProcessor) parseRecord(record *qifRecord, account string) ([]Transaction, error) {
+	date, err := parseQIFDate(record.fields['D'])
+	if err != nil {
+		return nil, &ProcessingError{
+			Operation: "parse_date",
+			Err:       err,
+			Line:      record.line,
+		}
+	}
+
+	amountStr := record.fields['T']
+	if amountStr == "" {
+		amountStr = record.fields['U']
+	}
+	amount, err := ParseAmount(amountStr, p.numberFormat)
+	if err != nil {
+		return nil, &ProcessingError{
+			Operation: "parse_amount",
+			Err:       err,
+			Line:      record.line,
+		}
+	}
+
+	description := record.fields['P']
+	if description == "" {
+		description = record.fields['M']
+	}
+
+	base := Transaction{
+		Date:        date,
+		Amount:      amount,
+		Description: description,
+		Reference:   record.fields['N'],
+		Source:      "QIF",
+		RawData:     map[string]any{},
+	}
+	if memo := record.fields['M']; memo != "" {
+		base.RawData["Memo"] = memo
+	}
+	if cleared := record.fields['C']; cleared != "" {
+		base.RawData["Cleared"] = cleared
+	}
+	if account != "" {
+		base.RawData["Account"] = account
+	}
+
+	if len(record.splits) == 0 {
+		applyQIFCategory(&base, record.fields['L'])
+		return []Transaction{base}, nil
+	}
+
+	// Each split becomes its own transaction so that it can be categorized separately
+	transactions := make([]Transaction, 0, len(record.splits))
+	total := decimal.Zero
+	for i, split := range record.splits {
+		splitAmount, err := ParseAmount(split.amount, p.numberFormat)
+		if err != nil {
+			return nil, &ProcessingError{
+				Operation: "parse_split",
+				Err:       fmt.Errorf("split %d: %w", i+1, err),
+				Line:      record.line,
+			}
+		}
+		total = total.Add(splitAmount)
+
+		trans := base
+		trans.Amount = splitAmount
+		trans.RawData = make(map[string]any, len(base.RawData)+3)
+		for k, v := range base.RawData {
+			trans.RawData[k] = v
+		}
+		trans.RawData["SplitIndex"] = i + 1
+		trans.RawData["SplitTotal"] = amount.String()
+		if split.memo != "" {
+			trans.RawData["SplitMemo"] = split.memo
+			trans.Description = fmt.Sprintf("%s (%s)", description, split.memo)
+		}
+		applyQIFCategory(&trans, split.category)
+		transactions = append(transactions, trans)
+	}
+
+	if !total.Equal(amount) {
+		p.logger.Warn("split amounts do not add up to the transaction total",
+			"line", record.line,
+			"total", amount,
+			"splits", total)
+	}
+	return transactions, nil
+}
+
+// applyQIFCategory sets the category fields from a QIF L or S value such as
+// "Food:Groceries" or a transfer such as "[Savings]"
+func applyQIFCategory(tx *Transaction, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+
+	// Classes are appended after a slash, e.g. "Food:Groceries/Vacation"
+	if idx := strings.Index(value, "/"); idx >= 0 {
+		if class := strings.TrimSpace(value[idx+1:]); class != "" {
+			tx.RawData["Class"] = class
+		}
+		value = strings.TrimSpace(value[:idx])
+	}
+
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		tx.RawData["TransferAccount"] = strings.Trim(value, "[]")
+		return
+	}
+
+	category, subcategory, _ := strings.Cut(value, ":")
+	tx.Category = strings.TrimSpace(category)
+	tx.SubCategory = strings.TrimSpace(subcategory)
+	tx.RawData["QIFCategory"] = value
+}
+
+// parseQIFDate parses a QIF date trying the common layouts used by Quicken and banks
+func parseQIFDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range qifDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date format '%s'", value)
+}
@@ -0,0 +1,161 @@
+package processor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const testQIF = `!Account
+NChecking
+TBank
+^
+!Type:Bank
+D02/24/2025
+T-1,250.00
+PICA Kvantum
+MWeekly shopping
+N1001
+LFood:Groceries
+^
+D2/25'25
+T25,000.00
+PEmployer AB
+LIncome:Salary
+^
+D02/26/2025
+T-500.00
+PTransfer to savings
+L[Savings]
+^
+D02/27/2025
+T-300.00
+PCoop
+SFood:Groceries
+EFood
+$-200.00
+SHousehold:Cleaning
+$-100.00
+^
+`
+
+func TestQIFProcessor_Successfully_process_bank_section(t *testing.T) {
+	processor := NewQIFProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if !processor.Detect([]byte(testQIF)) {
+		t.Fatal("Detect() = false, want true")
+	}
+
+	got, err := processor.ProcessDocument(context.Background(), strings.NewReader(testQIF))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Transaction{
+		{
+			Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("-1250"),
+			Description: "ICA Kvantum",
+			Reference:   "1001",
+			Category:    "Food",
+			SubCategory: "Groceries",
+			RawData:     map[string]any{"Account": "Checking", "Memo": "Weekly shopping"},
+		},
+		{
+			Date:        time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("25000"),
+			Description: "Employer AB",
+			Category:    "Income",
+			SubCategory: "Salary",
+		},
+		{
+			Date:        time.Date(2025, 2, 26, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("-500"),
+			Description: "Transfer to savings",
+			RawData:     map[string]any{"TransferAccount": "Savings"},
+		},
+		{
+			Date:        time.Date(2025, 2, 27, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("-200"),
+			Description: "Coop (Food)",
+			Category:    "Food",
+			SubCategory: "Groceries",
+			RawData:     map[string]any{"SplitIndex": 1, "SplitTotal": "-300"},
+		},
+		{
+			Date:        time.Date(2025, 2, 27, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("-100"),
+			Description: "Coop",
+			Category:    "Household",
+			SubCategory: "Cleaning",
+			RawData:     map[string]any{"SplitIndex": 2},
+		},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d transactions, got %d", len(want), len(got))
+	}
+	for i, w := range want {
+		if !w.Date.Equal(got[i].Date) {
+			t.Errorf("transaction[%d].Date = %v, want %v", i, got[i].Date, w.Date)
+		}
+		if !w.Amount.Equal(got[i].Amount) {
+			t.Errorf("transaction[%d].Amount = %v, want %v", i, got[i].Amount, w.Amount)
+		}
+		if w.Description != got[i].Description {
+			t.Errorf("transaction[%d].Description = %q, want %q", i, got[i].Description, w.Description)
+		}
+		if w.Reference != got[i].Reference {
+			t.Errorf("transaction[%d].Reference = %q, want %q", i, got[i].Reference, w.Reference)
+		}
+		if w.Category != got[i].Category || w.SubCategory != got[i].SubCategory {
+			t.Errorf("transaction[%d] category = %q:%q, want %q:%q", i, got[i].Category, got[i].SubCategory, w.Category, w.SubCategory)
+		}
+		for k, v := range w.RawData {
+			if got[i].RawData[k] != v {
+				t.Errorf("transaction[%d].RawData[%q] = %v, want %v", i, k, got[i].RawData[k], v)
+			}
+		}
+	}
+}
+
+func TestQIFProcessor_error_validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "Process_error_missing_type_header",
+			input:   "D02/24/2025\nT-1.00\n^\n",
+			wantErr: "missing !Type header",
+		},
+		{
+			name:    "Process_error_invalid_date",
+			input:   "!Type:Bank\nDyesterday\nT-1.00\n^\n",
+			wantErr: "no valid transactions found",
+		},
+		{
+			name:    "Process_error_unsupported_section_only",
+			input:   "!Type:Cat\nNFood\n^\n",
+			wantErr: "no valid transactions found",
+		},
+	}
+
+	processor := NewQIFProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := processor.ProcessDocument(context.Background(), strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// sniffSize is the number of leading bytes handed to parsers for format detection
+const sniffSize = 4096
+
+var (
+	// ErrParserNotFound indicates that no parser is registered under the requested bank id
+	ErrParserNotFound = errors.New("parser not found")
+
+	// ErrFormatNotDetected indicates that no registered parser recognised the document
+	ErrFormatNotDetected = errors.New("document format not detected")
+
+	// ErrParserExists indicates that a parser is already registered under the bank id
+	ErrParserExists = errors.New("parser already registered")
+)
+
+// Parser is a DocumentProcessor that can recognise its own document format
+type Parser interface {
+	DocumentProcessor
+
+	// Detect reports whether the sample, taken from the start of a document, matches this parser's format
+	Detect(sample []byte) bool
+}
+
+// ParserFactory creates a new parser instance using the given logger
+type ParserFactory func(logger *slog.Logger) Parser
+
+// ParserInfo describes a registered parser
+type ParserInfo struct {
+	ID          string // Bank id used with --bank, e.g. "seb"
+	Name        string // Human readable name
+	Format      string // File format, e.g. "csv"
+	Description string
+}
+
+type registryEntry struct {
+	factory ParserFactory
+	info    ParserInfo
+}
+
+// Registry keeps track of the parsers available for importing bank documents
+type Registry struct {
+	entries map[string]registryEntry
+	mu      sync.RWMutex
+}
+
+// NewRegistry creates an empty parser registry
+func NewRegistry() *Registry {
+	return &Registry{
+		entries: make(map[string]registryEntry),
+	}
+}
+
+// NewDefaultRegistry creates a registry containing all built-in parsers
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.MustRegister(ParserInfo{
+		ID:          "seb",
+		Name:        "SEB",
+		Format:      "csv",
+		Description: "SEB (Skandinaviska Enskilda Banken) CSV export",
+	}, func(logger *slog.Logger) Parser {
+		return NewSEBProcessor(logger)
+	})
+	r.MustRegister(ParserInfo{
+		ID:          "swedbank",
+		Name:        "Swedbank",
+		Format:      "csv",
+		Description: "Swedbank transaction report CSV export",
+	}, func(logger *slog.Logger) Parser {
+		return NewSwedbankProcessor(logger)
+	})
+	r.MustRegister(ParserInfo{
+		ID:          "handelsbanken",
+		Name:        "Handelsbanken",
+		Format:      "csv",
+		Description: "Handelsbanken account statement CSV export",
+	}, func(logger *slog.Logger) Parser {
+		return NewHandelsbankenProcessor(logger)
+	})
+	r.MustRegister(ParserInfo{
+		ID:          "nordea",
+		Name:        "Nordea",
+		Format:      "csv",
+		Description: "Nordea transaction CSV export",
+	}, func(logger *slog.Logger) Parser {
+		return NewNordeaProcessor(logger)
+	})
+	r.MustRegister(ParserInfo{
+		ID:          "lansforsakringar",
+		Name:        "Länsförsäkringar",
+		Format:      "csv",
+		Description: "Länsförsäkringar Bank CSV export",
+	}, func(logger *slog.Logger) Parser {
+		return NewLansforsakringarProcessor(logger)
+	})
+	r.MustRegister(ParserInfo{
+		ID:          "ofx",
+		Name:        "OFX",
+		Format:      "ofx",
+		Description: "OFX/QFX statement (Open Financial Exchange 1.x and 2.x)",
+	}, func(logger *slog.Logger) Parser {
+		return NewOFXProcessor(logger)
+	})
+	r.MustRegister(ParserInfo{
+		ID:          "qif",
+		Name:        "QIF",
+		Format:      "qif",
+		Description: "Quicken Interchange Format (Bank and CCard accounts)",
+	}, func(logger *slog.Logger) Parser {
+		return NewQIFProcessor(logger)
+	})
+	r.MustRegister(ParserInfo{
+		ID:          "camt",
+		Name:        "ISO 20022 camt",
+		Format:      "camt",
+		Description: "ISO 20022 camt.053 statement / camt.054 notification XML",
+	}, func(logger *slog.Logger) Parser {
+		return NewCAMTProcessor(logger)
+	})
+	r.MustRegister(ParserInfo{
+		ID:          "mt940",
+		Name:        "MT940",
+		Format:      "mt940",
+		Description: "SWIFT MT940 customer statement",
+	}, func(logger *slog.Logger) Parser {
+		return NewMT940Processor(logger)
+	})
+	return r
+}
+
+// Register adds a parser to the registry under info.ID
+func (r *Registry) Register(info ParserInfo, factory ParserFactory) error {
+	id := normalizeParserID(info.ID)
+	if id == "" {
+		return fmt.Errorf("parser id is required")
+	}
+	if factory == nil {
+		return fmt.Errorf("parser factory is required for %q", id)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.entries[id]; exists {
+		return fmt.Errorf("%w: %s", ErrParserExists, id)
+	}
+	info.ID = id
+	info.Format = strings.ToLower(info.Format)
+	r.entries[id] = registryEntry{info: info, factory: factory}
+	return nil
+}
+
+// MustRegister is like Register but panics on error; intended for built-in parsers
+func (r *Registry) MustRegister(info ParserInfo, factory ParserFactory) {
+	if err := r.Register(info, factory); err != nil {
+		panic(err)
+	}
+}
+
+// Get returns a new parser for the given bank id
+func (r *Registry) Get(id string, logger *slog.Logger) (Parser, ParserInfo, error) {
+	r.mu.RLock()
+	entry, exists := r.entries[normalizeParserID(id)]
+	r.mu.RUnlock()
+
+	if !exists {
+		return nil, ParserInfo{}, fmt.Errorf("%w: %s", ErrParserNotFound, id)
+	}
+	return entry.factory(logger), entry.info, nil
+}
+
+// Detect returns the first parser, in bank id order, whose Detect method accepts the sample
+func (r *Registry) Detect(sample []byte, logger *slog.Logger) (Parser, ParserInfo, error) {
+	for _, info := range r.List() {
+		r.mu.RLock()
+		entry := r.entries[info.ID]
+		r.mu.RUnlock()
+
+		parser := entry.factory(logger)
+		if parser.Detect(sample) {
+			return parser, entry.info, nil
+		}
+	}
+	return nil, ParserInfo{}, ErrFormatNotDetected
+}
+
+// List returns information about all registered parsers sorted by bank id
+func (r *Registry) List() []ParserInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]ParserInfo, 0, len(r.entries))
+	for _, entry := range r.entries {
+		infos = append(infos, entry.info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// Sniff reads the start of the reader for format detection. The returned reader
+// yields the complete document, including the sniffed bytes. Zip containers
+// such as XLSX workbooks cannot be recognised from their first bytes, so for
+// those the whole document is returned as the sample.
+func Sniff(reader io.Reader) ([]byte, io.Reader, error) {
+	buffered := bufio.NewReaderSize(reader, sniffSize)
+	sample, err := buffered.Peek(sniffSize)
+	if err != nil && err != io.EOF && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, nil, &ProcessingError{
+			Operation: "sniff",
+			Err:       err,
+		}
+	}
+	if bytes.HasPrefix(sample, zipMagic) {
+		data, err := io.ReadAll(buffered)
+		if err != nil {
+			return nil, nil, &ProcessingError{
+				Operation: "sniff",
+				Err:       err,
+			}
+		}
+		return data, bytes.NewReader(data), nil
+	}
+	return sample, buffered, nil
+}
+
+// firstLine returns the first line of the sample with any UTF-8 BOM removed
+func firstLine(sample []byte) string {
+	line := string(sample)
+	if idx := strings.IndexAny(line, "\r\n"); idx >= 0 {
+		line = line[:idx]
+	}
+	return strings.TrimPrefix(line, "\uFEFF")
+}
+
+func normalizeParserID(id string) string {
+	return strings.ToLower(strings.TrimSpace(id))
+}
@@ -0,0 +1,112 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type stubParser struct {
+	prefix string
+}
+
+func (p *stubParser) ProcessDocument(ctx context.Context, reader io.Reader) ([]Transaction, error) {
+	return nil, nil
+}
+
+func (p *stubParser) Detect(sample []byte) bool {
+	return strings.HasPrefix(string(sample), p.prefix)
+}
+
+func TestRegistry_Successfully_get_and_detect_parsers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := NewDefaultRegistry()
+	if err := registry.Register(ParserInfo{ID: "Stub", Format: "CSV"}, func(logger *slog.Logger) Parser {
+		return &stubParser{prefix: "stub;"}
+	}); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		sample string
+		wantID string
+	}{
+		{
+			name:   "Successfully_detect_seb_header",
+			sample: "Bokföringsdatum;Valutadatum;Verifikationsnummer;Text;Belopp;Saldo\n2025-02-24;2025-02-22;1;x;-1,00;2,00",
+			wantID: "seb",
+		},
+		{
+			name:   "Successfully_detect_seb_header_with_bom",
+			sample: "\uFEFFBokföringsdatum;Valutadatum;Verifikationsnummer;Text;Belopp;Saldo\r\n",
+			wantID: "seb",
+		},
+		{
+			name:   "Successfully_detect_registered_parser",
+			sample: "stub;header\n",
+			wantID: "stub",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, info, err := registry.Detect([]byte(tt.sample), logger)
+			if err != nil {
+				t.Fatalf("Detect() unexpected error: %v", err)
+			}
+			if info.ID != tt.wantID {
+				t.Errorf("Detect() id = %q, want %q", info.ID, tt.wantID)
+			}
+		})
+	}
+
+	_, info, err := registry.Get(" STUB ", logger)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if info.Format != "csv" {
+		t.Errorf("Get() format = %q, want %q", info.Format, "csv")
+	}
+
+	if got, want := len(registry.List()), len(NewDefaultRegistry().List())+1; got != want {
+		t.Errorf("List() returned %d parsers, want %d", got, want)
+	}
+}
+
+func TestRegistry_error_handling(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := NewDefaultRegistry()
+
+	if _, _, err := registry.Get("unknown", logger); !errors.Is(err, ErrParserNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, ErrParserNotFound)
+	}
+	if _, _, err := registry.Detect([]byte("Datum,Text,Belopp\n"), logger); !errors.Is(err, ErrFormatNotDetected) {
+		t.Errorf("Detect() error = %v, want %v", err, ErrFormatNotDetected)
+	}
+	err := registry.Register(ParserInfo{ID: "seb"}, func(logger *slog.Logger) Parser { return NewSEBProcessor(logger) })
+	if !errors.Is(err, ErrParserExists) {
+		t.Errorf("Register() error = %v, want %v", err, ErrParserExists)
+	}
+}
+
+func TestSniff_Successfully_replay_sniffed_bytes(t *testing.T) {
+	input := strings.Repeat("a", sniffSize+10)
+	sample, reader, err := Sniff(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Sniff() unexpected error: %v", err)
+	}
+	if len(sample) != sniffSize {
+		t.Errorf("Sniff() sample length = %d, want %d", len(sample), sniffSize)
+	}
+	all, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() unexpected error: %v", err)
+	}
+	if string(all) != input {
+		t.Errorf("Sniff() reader did not replay the full document")
+	}
+}
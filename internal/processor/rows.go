@@ -0,0 +1,56 @@
+package processor
+
+import "fmt"
+
+// RowError describes a row that was rejected while processing a document
+type RowError struct {
+	Line   int    // Line in the document, 0 when unknown
+	Reason string // Why the row was rejected
+	Raw    string // The rejected row as read from the document
+}
+
+func (e RowError) String() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+	}
+	return e.Reason
+}
+
+// RowErrorReporter is implemented by parsers that skip rows they cannot
+// parse, so that an import can report exactly which rows were left out
+type RowErrorReporter interface {
+	// RejectedRows returns the rows rejected by the last ProcessDocument call
+	RejectedRows() []RowError
+}
+
+// rowRejections collects rejected rows for parsers implementing RowErrorReporter
+type rowRejections struct {
+	rejected []RowError
+}
+
+// RejectedRows implements the RowErrorReporter interface
+func (r *rowRejections) RejectedRows() []RowError {
+	return r.rejected
+}
+
+// resetRejections forgets the rows rejected by a previous document
+func (r *rowRejections) resetRejections() {
+	r.rejected = nil
+}
+
+// reject records a row that could not be parsed. The line of a ProcessingError
+// is used when the caller does not know it.
+func (r *rowRejections) reject(line int, err error, raw string) {
+	reason := err.Error()
+	if procErr, ok := err.(*ProcessingError); ok {
+		reason = fmt.Sprintf("%s: %v", procErr.Operation, procErr.Err)
+		if line == 0 {
+			line = procErr.Line
+		}
+	}
+	r.rejected = append(r.rejected, RowError{
+		Line:   line,
+		Reason: reason,
+		Raw:    raw,
+	})
+}
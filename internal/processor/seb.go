@@ -3,11 +3,11 @@ package processor
 import (
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"strings"
-	"time"
 
 	"github.com/shopspring/decimal"
 )
@@ -34,6 +34,7 @@ var defaultSEBFormat = SEBFormat{
 
 // SEBProcessor implements the DocumentProcessor interface for SEB bank statements
 type SEBProcessor struct {
+	rowRejections
 	logger *slog.Logger
 	format SEBFormat
 }
@@ -46,9 +47,19 @@ func NewSEBProcessor(logger *slog.Logger) *SEBProcessor {
 	}
 }
 
-// ProcessDocument implements the DocumentProcessor interface for SEB CSV files
+// ProcessDocument implements the DocumentProcessor interface for SEB CSV files.
+// Exports in ISO-8859-1 or Windows-1252 are converted to UTF-8 first.
 func (p *SEBProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([]Transaction, error) {
-	csvReader := csv.NewReader(reader)
+	p.resetRejections()
+	decoded, err := decodeReader(reader, EncodingAuto)
+	if err != nil {
+		return nil, &ProcessingError{
+			Operation: "read_header",
+			Err:       err,
+		}
+	}
+
+	csvReader := csv.NewReader(decoded)
 	csvReader.Comma = ';' // SEB uses semicolon as delimiter
 	csvReader.TrimLeadingSpace = true
 
@@ -68,7 +79,7 @@ func (p *SEBProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([
 	p.logger.Info("header validated successfully", "header", header)
 
 	var transactions []Transaction
-	lineNum := 1 // Start after header
+	lineNum := 1 // The header is on line 1
 
 	for {
 		record, err := csvReader.Read()
@@ -76,12 +87,17 @@ func (p *SEBProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([
 			break
 		}
 		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				lineNum = parseErr.Line
+			}
 			return nil, &ProcessingError{
 				Operation: "read_record",
 				Err:       err,
 				Line:      lineNum,
 			}
 		}
+		lineNum, _ = csvReader.FieldPos(0)
 
 		p.logger.Debug("processing record", "line", lineNum, "record", record)
 		trans, err := p.parseTransaction(record, lineNum)
@@ -91,7 +107,7 @@ func (p *SEBProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([
 				"line", lineNum,
 				"error", err,
 				"raw_data", record)
-			lineNum++
+			p.reject(lineNum, err, strings.Join(record, ";"))
 			continue
 		}
 
@@ -101,7 +117,6 @@ func (p *SEBProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([
 			"amount", trans.Amount,
 			"description", trans.Description)
 		transactions = append(transactions, trans)
-		lineNum++
 	}
 
 	if len(transactions) == 0 {
@@ -117,6 +132,15 @@ func (p *SEBProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([
 	return transactions, nil
 }
 
+// Detect implements the Parser interface by looking for the SEB header columns
+func (p *SEBProcessor) Detect(sample []byte) bool {
+	text, err := decodeBytes(sample, EncodingAuto)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(firstLine([]byte(text)), "Bokföringsdatum;Valutadatum;Verifikationsnummer;Text;Belopp")
+}
+
 func (p *SEBProcessor) validateHeader(header []string) error {
 	expectedHeaders := []string{
 		"Bokföringsdatum",
@@ -163,38 +187,61 @@ func (p *SEBProcessor) parseTransaction(record []string, lineNum int) (Transacti
 	// Parse booking date
 	dateStr := record[p.format.BookingDate]
 	p.logger.Debug("parsing date", "raw_date", dateStr)
-	bookingDate, err := time.Parse("2006-01-02", dateStr)
+	bookingDate, err := ParseDate(dateStr)
 	if err != nil {
 		return Transaction{}, &ProcessingError{
 			Operation: "parse_date",
-			Err:       fmt.Errorf("invalid date format '%s': %v", dateStr, err),
+			Err:       err,
 			Line:      lineNum,
 		}
 	}
 
-	// Parse amount (replace comma with dot for decimal parsing)
-	amountStr := strings.Replace(record[p.format.Amount], ",", ".", 1)
+	// SEB writes amounts both as "-1000.00" and as "-1 000,00" depending on
+	// where the export was made, so the separators are inferred per amount
+	amountStr := record[p.format.Amount]
 	p.logger.Debug("parsing amount", "raw_amount", amountStr)
-	amount, err := decimal.NewFromString(amountStr)
+	amount, err := ParseAmount(amountStr, NumberFormat{})
 	if err != nil {
 		return Transaction{}, &ProcessingError{
 			Operation: "parse_amount",
-			Err:       fmt.Errorf("invalid amount format '%s': %v", amountStr, err),
+			Err:       err,
 			Line:      lineNum,
 		}
 	}
 
-	return Transaction{
+	trans := Transaction{
 		Date:        bookingDate,
 		Amount:      amount,
 		Description: strings.TrimSpace(record[p.format.Description]),
 		Reference:   record[p.format.Reference],
-		RawData: map[string]any{
-			"ValueDate": record[p.format.ValueDate],
-			"Balance":   record[p.format.Balance],
-		},
-		Source: "SEB",
-	}, nil
+		RawData:     map[string]any{},
+		Source:      "SEB",
+	}
+
+	// The value date and balance are left out of some exports
+	if valueDateStr := strings.TrimSpace(record[p.format.ValueDate]); valueDateStr != "" {
+		trans.ValueDate, err = ParseDate(valueDateStr)
+		if err != nil {
+			return Transaction{}, &ProcessingError{
+				Operation: "parse_value_date",
+				Err:       err,
+				Line:      lineNum,
+			}
+		}
+	}
+	if balanceStr := strings.TrimSpace(record[p.format.Balance]); balanceStr != "" {
+		balance, err := ParseAmount(balanceStr, NumberFormat{})
+		if err != nil {
+			return Transaction{}, &ProcessingError{
+				Operation: "parse_balance",
+				Err:       err,
+				Line:      lineNum,
+			}
+		}
+		trans.Balance = decimal.NewNullDecimal(balance)
+	}
+
+	return trans, nil
 }
 
 // ProcessingError represents an error during CSV processing
@@ -31,10 +31,8 @@ func TestSEBProcessor_Successfully_process_valid_transactions(t *testing.T) {
 					Description: "56130086210",
 					Reference:   "5490990004",
 					Source:      "SEB",
-					RawData: map[string]any{
-						"ValueDate": "2025-02-22",
-						"Balance":   "2814.160",
-					},
+					ValueDate:   time.Date(2025, 2, 22, 0, 0, 0, 0, time.UTC),
+					Balance:     decimal.NewNullDecimal(decimal.RequireFromString("2814.160")),
 				},
 				{
 					Date:        time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC),
@@ -42,10 +40,8 @@ func TestSEBProcessor_Successfully_process_valid_transactions(t *testing.T) {
 					Description: "56130086210",
 					Reference:   "5490990004",
 					Source:      "SEB",
-					RawData: map[string]any{
-						"ValueDate": "2025-02-10",
-						"Balance":   "3814.160",
-					},
+					ValueDate:   time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC),
+					Balance:     decimal.NewNullDecimal(decimal.RequireFromString("3814.160")),
 				},
 			},
 		},
@@ -60,10 +56,8 @@ func TestSEBProcessor_Successfully_process_valid_transactions(t *testing.T) {
 					Description: "56130086210",
 					Reference:   "5490990004",
 					Source:      "SEB",
-					RawData: map[string]any{
-						"ValueDate": "2025-02-22",
-						"Balance":   "2814.160",
-					},
+					ValueDate:   time.Date(2025, 2, 22, 0, 0, 0, 0, time.UTC),
+					Balance:     decimal.NewNullDecimal(decimal.RequireFromString("2814.160")),
 				},
 			},
 		},
@@ -78,10 +72,31 @@ func TestSEBProcessor_Successfully_process_valid_transactions(t *testing.T) {
 					Description: "56130086210",
 					Reference:   "5490990004",
 					Source:      "SEB",
-					RawData: map[string]any{
-						"ValueDate": "2025-02-22",
-						"Balance":   "2814.160",
-					},
+					ValueDate:   time.Date(2025, 2, 22, 0, 0, 0, 0, time.UTC),
+					Balance:     decimal.NewNullDecimal(decimal.RequireFromString("2814.160")),
+				},
+			},
+		},
+		{
+			name: "Successfully_process_latin1_file_with_swedish_amounts",
+			input: latin1("Bokföringsdatum;Valutadatum;Verifikationsnummer;Text;Belopp;Saldo\n" +
+				"2025-02-24;2025-02-22;5490990004;Återbetalning Åhléns ;1 234,50;12 814,16\n" +
+				"2025-02-25;2025-02-25;5490990005;Kortköp ICA;-89,90;12 724,26"),
+			want: []Transaction{
+				{
+					Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+					Amount:      decimal.RequireFromString("1234.50"),
+					Description: "Återbetalning Åhléns",
+					Reference:   "5490990004",
+					Source:      "SEB",
+					Balance:     decimal.NewNullDecimal(decimal.RequireFromString("12814.16")),
+				},
+				{
+					Date:        time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC),
+					Amount:      decimal.RequireFromString("-89.90"),
+					Description: "Kortköp ICA",
+					Reference:   "5490990005",
+					Source:      "SEB",
 				},
 			},
 		},
@@ -94,6 +109,10 @@ func TestSEBProcessor_Successfully_process_valid_transactions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			if !processor.Detect([]byte(tt.input)) {
+				t.Error("Detect() = false, want true")
+			}
+
 			// Process the test data
 			got, err := processor.ProcessDocument(ctx, strings.NewReader(tt.input))
 			if err != nil {
@@ -137,12 +156,16 @@ func TestSEBProcessor_Successfully_process_valid_transactions(t *testing.T) {
 					return
 				}
 
-				// Validate RawData
-				for k, v := range want.RawData {
-					if got[i].RawData[k] != v {
-						t.Errorf("transaction[%d].RawData[%q] = %v, want %v", i, k, got[i].RawData[k], v)
-						return
-					}
+				// Validate ValueDate
+				if !want.ValueDate.IsZero() && !want.ValueDate.Equal(got[i].ValueDate) {
+					t.Errorf("transaction[%d].ValueDate = %v, want %v", i, got[i].ValueDate, want.ValueDate)
+					return
+				}
+
+				// Validate Balance
+				if want.Balance.Valid && (!got[i].Balance.Valid || !want.Balance.Decimal.Equal(got[i].Balance.Decimal)) {
+					t.Errorf("transaction[%d].Balance = %v, want %v", i, got[i].Balance, want.Balance)
+					return
 				}
 			}
 		})
@@ -206,6 +229,47 @@ invalid-date;2025-02-22;5490990004;56130086210;-1000.000;2814.160`,
 	}
 }
 
+func TestSEBProcessor_Successfully_report_rejected_rows(t *testing.T) {
+	input := `Bokföringsdatum;Valutadatum;Verifikationsnummer;Text;Belopp;Saldo
+2025-02-24;2025-02-22;5490990004;ICA;-100,00;900,00
+2025-02-31;2025-02-22;5490990005;Coop;-50,00;850,00
+2025-02-25;2025-02-25;5490990006;Lön;abc;850,00
+2025-02-26;2025-02-26;5490990007;Hyra;-500,00;350,00
+2025-02-27;2025-02-27;5490990008;Swish;-50,00;xyz`
+
+	processor := NewSEBProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	got, err := processor.ProcessDocument(context.Background(), strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 transactions, got %d", len(got))
+	}
+
+	want := []RowError{
+		{Line: 3, Reason: "parse_date: invalid date format '2025-02-31'", Raw: "2025-02-31;2025-02-22;5490990005;Coop;-50,00;850,00"},
+		{Line: 4, Reason: "parse_amount: invalid amount format 'abc'", Raw: "2025-02-25;2025-02-25;5490990006;Lön;abc;850,00"},
+		{Line: 6, Reason: "parse_balance: invalid amount format 'xyz'", Raw: "2025-02-27;2025-02-27;5490990008;Swish;-50,00;xyz"},
+	}
+	rejected := processor.RejectedRows()
+	if len(rejected) != len(want) {
+		t.Fatalf("expected %d rejected rows, got %d: %v", len(want), len(rejected), rejected)
+	}
+	for i, w := range want {
+		if rejected[i] != w {
+			t.Errorf("rejected[%d] = %+v, want %+v", i, rejected[i], w)
+		}
+	}
+
+	// A following document starts without the previous rejections
+	if _, err := processor.ProcessDocument(context.Background(), strings.NewReader(strings.Join(strings.Split(input, "\n")[:2], "\n"))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows := processor.RejectedRows(); len(rows) != 0 {
+		t.Errorf("RejectedRows() = %v, want none", rows)
+	}
+}
+
 // Helper function to create a reader that fails
 type failingReader struct {
 	err error
@@ -0,0 +1,108 @@
+package processor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// swedbankLayout is Swedbank's "Transaktionsrapport" export, a comma separated
+// file with a report title line above the header
+var swedbankLayout = bankCSVLayout{
+	delimiter:   ',',
+	required:    []string{"Bokföringsdag", "Transaktionsdag", "Valutadag", "Beskrivning", "Belopp"},
+	maxPreamble: 3,
+}
+
+// SwedbankProcessor implements the Parser interface for Swedbank CSV exports
+type SwedbankProcessor struct {
+	rowRejections
+	logger       *slog.Logger
+	numberFormat NumberFormat
+}
+
+// NewSwedbankProcessor creates a new Swedbank CSV processor
+func NewSwedbankProcessor(logger *slog.Logger) *SwedbankProcessor {
+	return &SwedbankProcessor{
+		logger:       logger,
+		numberFormat: NumberFormat{DecimalSeparator: "."},
+	}
+}
+
+// Detect implements the Parser interface by looking for the Swedbank header below the report title
+func (p *SwedbankProcessor) Detect(sample []byte) bool {
+	return swedbankLayout.detect(sample)
+}
+
+// ProcessDocument implements the DocumentProcessor interface for Swedbank CSV files
+func (p *SwedbankProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([]Transaction, error) {
+	p.resetRejections()
+	table, err := swedbankLayout.read(reader)
+	if err != nil {
+		return nil, err
+	}
+	return processBankCSV(p.logger, "swedbank", table, &p.rowRejections, func(record bankCSVRecord) (Transaction, bool, error) {
+		trans, err := p.parseTransaction(table, record)
+		return trans, true, err
+	})
+}
+
+func (p *SwedbankProcessor) parseTransaction(table *bankCSVTable, record bankCSVRecord) (Transaction, error) {
+	date, err := ParseDate(table.get(record, "Bokföringsdag"), "2006-01-02")
+	if err != nil {
+		return Transaction{}, &ProcessingError{
+			Operation: "parse_date",
+			Err:       err,
+			Line:      record.line,
+		}
+	}
+
+	amount, err := ParseAmount(table.get(record, "Belopp"), p.numberFormat)
+	if err != nil {
+		return Transaction{}, &ProcessingError{
+			Operation: "parse_amount",
+			Err:       err,
+			Line:      record.line,
+		}
+	}
+
+	var valueDate time.Time
+	if value := table.get(record, "Valutadag"); value != "" {
+		valueDate, err = ParseDate(value, "2006-01-02")
+		if err != nil {
+			return Transaction{}, &ProcessingError{
+				Operation: "parse_value_date",
+				Err:       err,
+				Line:      record.line,
+			}
+		}
+	}
+	balance, err := table.balance(record, "Bokfört saldo", p.numberFormat)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	rawData := map[string]any{}
+	addRawString(rawData, "TransactionDate", table.get(record, "Transaktionsdag"))
+	addRawString(rawData, "Product", table.get(record, "Produkt"))
+	if clearing, account := table.get(record, "Clearingnummer"), table.get(record, "Kontonummer"); account != "" {
+		if clearing != "" {
+			account = clearing + "-" + account
+		}
+		rawData["Account"] = account
+	}
+
+	reference := table.get(record, "Referens")
+	return Transaction{
+		Date:        date,
+		Amount:      amount,
+		Description: firstNonEmpty(table.get(record, "Beskrivning"), reference),
+		Reference:   reference,
+		RawData:     rawData,
+		Source:      "Swedbank",
+		Currency:    table.get(record, "Valuta"),
+		ValueDate:   valueDate,
+		Balance:     balance,
+	}, nil
+}
@@ -0,0 +1,123 @@
+package processor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const testSwedbankCSV = `* Transaktionsrapport Period 2025-02-01 – 2025-02-28 Skapad 2025-03-01 08:15 CET
+Radnummer,Clearingnummer,Kontonummer,Produkt,Valuta,Bokföringsdag,Transaktionsdag,Valutadag,Referens,Beskrivning,Belopp,Bokfört saldo
+1,8327-9,9141234567,Privatkonto,SEK,2025-02-24,2025-02-23,2025-02-24,"ICA MAXI","ICA MAXI LINKÖPING",-249.50,10750.50
+2,8327-9,9141234567,Privatkonto,SEK,2025-02-25,2025-02-25,2025-02-25,"LÖN","",25000.00,35750.50
+`
+
+func TestSwedbankProcessor_Successfully_process_valid_transactions(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Transaction
+	}{
+		{
+			name:  "Successfully_process_utf8_export",
+			input: testSwedbankCSV,
+			want: []Transaction{
+				{
+					Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+					Amount:      decimal.RequireFromString("-249.50"),
+					Description: "ICA MAXI LINKÖPING",
+					Reference:   "ICA MAXI",
+					Source:      "Swedbank",
+					Currency:    "SEK",
+					ValueDate:   time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+					Balance:     decimal.NewNullDecimal(decimal.RequireFromString("10750.50")),
+					RawData: map[string]any{
+						"TransactionDate": "2025-02-23",
+						"Account":         "8327-9-9141234567",
+					},
+				},
+				{
+					Date:        time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC),
+					Amount:      decimal.RequireFromString("25000"),
+					Description: "LÖN",
+					Reference:   "LÖN",
+					Source:      "Swedbank",
+					Currency:    "SEK",
+				},
+			},
+		},
+		{
+			name:  "Successfully_process_latin1_export",
+			input: latin1(strings.ReplaceAll(testSwedbankCSV, "–", "-")),
+			want: []Transaction{
+				{
+					Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+					Amount:      decimal.RequireFromString("-249.50"),
+					Description: "ICA MAXI LINKÖPING",
+					Reference:   "ICA MAXI",
+					Source:      "Swedbank",
+					Currency:    "SEK",
+				},
+				{
+					Date:        time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC),
+					Amount:      decimal.RequireFromString("25000"),
+					Description: "LÖN",
+					Reference:   "LÖN",
+					Source:      "Swedbank",
+					Currency:    "SEK",
+				},
+			},
+		},
+	}
+
+	processor := NewSwedbankProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertDetectedBank(t, tt.input, "swedbank")
+
+			got, err := processor.ProcessDocument(context.Background(), strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertTransactions(t, got, tt.want)
+		})
+	}
+}
+
+func TestSwedbankProcessor_error_validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "Process_error_missing_header",
+			input:   "* Transaktionsrapport\nRadnummer,Belopp\n1,-1.00\n",
+			wantErr: "validate_header failed",
+		},
+		{
+			name: "Process_error_invalid_amount_format",
+			input: "Radnummer,Bokföringsdag,Transaktionsdag,Valutadag,Beskrivning,Belopp\n" +
+				"1,2025-02-24,2025-02-24,2025-02-24,ICA,invalid\n",
+			wantErr: "no valid transactions found",
+		},
+	}
+
+	processor := NewSwedbankProcessor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := processor.ProcessDocument(context.Background(), strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
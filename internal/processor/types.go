@@ -10,14 +10,17 @@ import (
 
 // Transaction represents a financial transaction from any source
 type Transaction struct {
-	RawData     map[string]any  // 8-byte pointer
-	Amount      decimal.Decimal // 8-byte pointer
-	Date        time.Time       // 24 bytes
-	Description string          // 16 bytes
-	Reference   string          // 16 bytes
-	Category    string          // 16 bytes
-	SubCategory string          // 16 bytes
-	Source      string          // 16 bytes
+	RawData     map[string]any      // 8-byte pointer
+	Amount      decimal.Decimal     // 8-byte pointer
+	Date        time.Time           // 24 bytes, booking date
+	Description string              // 16 bytes
+	Reference   string              // 16 bytes
+	Category    string              // 16 bytes
+	SubCategory string              // 16 bytes
+	Source      string              // 16 bytes
+	Currency    string              // 16 bytes, ISO 4217 code when the document states it
+	ValueDate   time.Time           // 24 bytes, zero when the document has no value date
+	Balance     decimal.NullDecimal // 24 bytes, account balance after the transaction when the document states it
 }
 
 // DocumentProcessor defines the interface for processing different types of financial documents
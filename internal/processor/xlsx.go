@@ -0,0 +1,225 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxHeaderSearchRows is the number of rows searched for the header row,
+// since bank exports often start with account details above the table
+const xlsxHeaderSearchRows = 30
+
+// zipMagic is the signature of zip containers such as XLSX workbooks
+var zipMagic = []byte("PK\x03\x04")
+
+// XLSXProcessor implements the Parser interface for Excel workbooks described by a CSVProfile.
+// The sheet and header row are located automatically unless the profile names the sheet.
+type XLSXProcessor struct {
+	rowRejections
+	logger  *slog.Logger
+	mapping *ProfileProcessor
+}
+
+// NewXLSXProcessor creates an XLSX processor for the given profile
+func NewXLSXProcessor(profile CSVProfile, logger *slog.Logger) (*XLSXProcessor, error) {
+	profile.Format = ProfileFormatXLSX
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	return newXLSXProcessor(profile, logger), nil
+}
+
+func newXLSXProcessor(profile CSVProfile, logger *slog.Logger) *XLSXProcessor {
+	return &XLSXProcessor{
+		logger:  logger,
+		mapping: &ProfileProcessor{logger: logger, profile: profile},
+	}
+}
+
+// Profile returns the validated profile used by the processor
+func (p *XLSXProcessor) Profile() CSVProfile {
+	return p.mapping.profile
+}
+
+// Detect implements the Parser interface. Workbooks are compressed, so the
+// sample must hold the complete file; Sniff takes care of that for zip files.
+func (p *XLSXProcessor) Detect(sample []byte) bool {
+	if !bytes.HasPrefix(sample, zipMagic) {
+		return false
+	}
+	f, err := excelize.OpenReader(bytes.NewReader(sample))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	_, _, _, err = p.locateTable(f)
+	return err == nil
+}
+
+// ProcessDocument implements the DocumentProcessor interface for XLSX workbooks
+func (p *XLSXProcessor) ProcessDocument(ctx context.Context, reader io.Reader) ([]Transaction, error) {
+	p.resetRejections()
+	f, err := excelize.OpenReader(reader)
+	if err != nil {
+		return nil, &ProcessingError{
+			Operation: "open_workbook",
+			Err:       err,
+		}
+	}
+	defer f.Close()
+
+	sheet, headerRow, indices, err := p.locateTable(f)
+	if err != nil {
+		return nil, &ProcessingError{
+			Operation: "validate_header",
+			Err:       err,
+		}
+	}
+
+	rows, err := f.GetRows(sheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, &ProcessingError{
+			Operation: "read_sheet",
+			Err:       err,
+		}
+	}
+
+	date1904 := false
+	if props, err := f.GetWorkbookProps(); err == nil && props.Date1904 != nil {
+		date1904 = *props.Date1904
+	}
+
+	profile := p.mapping.profile
+	var transactions []Transaction
+	for i := headerRow + 1; i < len(rows); i++ {
+		lineNum := i + 1
+		record := rows[i]
+		if isBlankRecord(record) {
+			continue
+		}
+
+		// Numeric cells hold date serials and plain decimals, which are
+		// rendered in the profile's formats before the shared column mapping
+		for _, col := range []int{indices.date, indices.valueDate} {
+			if cellIsNumber(f, sheet, col, i, record) {
+				serial, _ := strconv.ParseFloat(record[col], 64)
+				if date, err := excelize.ExcelDateToTime(serial, date1904); err == nil {
+					record[col] = date.Format(profile.DateLayout)
+				}
+			}
+		}
+		for _, col := range []int{indices.amount, indices.balance} {
+			if cellIsNumber(f, sheet, col, i, record) {
+				if value, err := decimal.NewFromString(record[col]); err == nil {
+					record[col] = strings.Replace(value.String(), ".", profile.DecimalSeparator, 1)
+				}
+			}
+		}
+
+		trans, err := p.mapping.parseTransaction(record, indices, lineNum)
+		if err != nil {
+			p.logger.Error("failed to parse transaction",
+				"profile", profile.ID,
+				"sheet", sheet,
+				"line", lineNum,
+				"error", err,
+				"raw_data", record)
+			p.reject(lineNum, err, strings.Join(record, ";"))
+			continue
+		}
+		trans.RawData["Sheet"] = sheet
+		transactions = append(transactions, trans)
+	}
+
+	if len(transactions) == 0 {
+		return nil, &ProcessingError{
+			Operation: "process_document",
+			Err:       fmt.Errorf("no valid transactions found in document"),
+		}
+	}
+
+	p.logger.Info("successfully processed document",
+		"profile", profile.ID,
+		"format", "xlsx",
+		"sheet", sheet,
+		"total_transactions", len(transactions))
+	return transactions, nil
+}
+
+// locateTable finds the sheet and the zero based header row containing all mapped columns.
+// The profile's sheet is used when set, otherwise the first sheet with a matching header.
+func (p *XLSXProcessor) locateTable(f *excelize.File) (string, int, profileColumns, error) {
+	profile := p.mapping.profile
+	sheets := f.GetSheetList()
+	if profile.Sheet != "" {
+		found := false
+		for _, name := range sheets {
+			if strings.EqualFold(name, profile.Sheet) {
+				sheets, found = []string{name}, true
+				break
+			}
+		}
+		if !found {
+			return "", 0, profileColumns{}, fmt.Errorf("sheet %q not found", profile.Sheet)
+		}
+	}
+
+	// When no row matches, report the row that came closest to the mapping
+	bestErr, bestScore := fmt.Errorf("no header row found"), -1
+	for _, sheet := range sheets {
+		rows, err := f.GetRows(sheet)
+		if err != nil {
+			return "", 0, profileColumns{}, fmt.Errorf("sheet %q: %w", sheet, err)
+		}
+		for i := profile.SkipLines; i < len(rows) && i < profile.SkipLines+xlsxHeaderSearchRows; i++ {
+			indices, err := p.mapping.columnIndices(rows[i])
+			if err == nil {
+				return sheet, i, indices, nil
+			}
+			if score := p.headerScore(rows[i]); score > bestScore {
+				bestErr, bestScore = fmt.Errorf("sheet %q: no header row found: %w", sheet, err), score
+			}
+		}
+	}
+	return "", 0, profileColumns{}, bestErr
+}
+
+// headerScore counts the mapped column names present in the row
+func (p *XLSXProcessor) headerScore(row []string) int {
+	columns := p.mapping.profile.Columns
+	score := 0
+	for _, cell := range row {
+		cell = strings.TrimSpace(cell)
+		for _, name := range []string{columns.Date, columns.ValueDate, columns.Description, columns.Amount, columns.Reference, columns.Balance} {
+			if name != "" && strings.EqualFold(cell, strings.TrimSpace(name)) {
+				score++
+			}
+		}
+	}
+	return score
+}
+
+// cellIsNumber reports whether the mapped cell holds a numeric value
+func cellIsNumber(f *excelize.File, sheet string, col, row int, record []string) bool {
+	if col < 0 || col >= len(record) || record[col] == "" {
+		return false
+	}
+	cell, err := excelize.CoordinatesToCellName(col+1, row+1)
+	if err != nil {
+		return false
+	}
+	cellType, err := f.GetCellType(sheet, cell)
+	if err != nil {
+		return false
+	}
+	return cellType == excelize.CellTypeNumber || cellType == excelize.CellTypeUnset
+}
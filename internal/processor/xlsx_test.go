@@ -0,0 +1,165 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+func createTestXLSXProfile() CSVProfile {
+	return CSVProfile{
+		ID:         "xlsxbank",
+		Name:       "XLSX Bank",
+		Format:     ProfileFormatXLSX,
+		DateLayout: "2006-01-02",
+		Columns: CSVColumns{
+			Date:        "Transaktionsdatum",
+			Description: "Text",
+			Amount:      "Belopp",
+			Balance:     "Saldo",
+		},
+	}
+}
+
+// createTestWorkbook builds a workbook with a summary sheet and a transaction
+// sheet whose table starts below a few rows of account details
+func createTestWorkbook(t *testing.T) []byte {
+	t.Helper()
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", "Översikt"); err != nil {
+		t.Fatalf("SetSheetName() unexpected error: %v", err)
+	}
+	if err := f.SetSheetRow("Översikt", "A1", &[]any{"Konto", "Saldo"}); err != nil {
+		t.Fatalf("SetSheetRow() unexpected error: %v", err)
+	}
+	if _, err := f.NewSheet("Transaktioner"); err != nil {
+		t.Fatalf("NewSheet() unexpected error: %v", err)
+	}
+
+	rows := [][]any{
+		{"Kontoutdrag", "Allkonto 6000-123 456 789"},
+		{"Period", "2025-02-01 - 2025-02-28"},
+		{},
+		{"Reskontradatum", "Transaktionsdatum", "Text", "Belopp", "Saldo"},
+		{time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC), "Kafé Åre", -249.5, 750.5},
+		{},
+		{"2025-02-25", "2025-02-25", "Lön", "25000,00", "25750,50"},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow("Transaktioner", cell, &row); err != nil {
+			t.Fatalf("SetSheetRow() unexpected error: %v", err)
+		}
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("WriteToBuffer() unexpected error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestXLSXProcessor_Successfully_process_mapped_workbook(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registry := NewDefaultRegistry()
+	if err := RegisterProfile(registry, createTestXLSXProfile()); err != nil {
+		t.Fatalf("RegisterProfile() unexpected error: %v", err)
+	}
+
+	sample, reader, err := Sniff(bytes.NewReader(createTestWorkbook(t)))
+	if err != nil {
+		t.Fatalf("Sniff() unexpected error: %v", err)
+	}
+	parser, info, err := registry.Detect(sample, logger)
+	if err != nil {
+		t.Fatalf("Detect() unexpected error: %v", err)
+	}
+	if info.ID != "xlsxbank" || info.Format != ProfileFormatXLSX {
+		t.Errorf("Detect() = %q (%s), want xlsxbank (xlsx)", info.ID, info.Format)
+	}
+
+	got, err := parser.ProcessDocument(context.Background(), reader)
+	if err != nil {
+		t.Fatalf("ProcessDocument() unexpected error: %v", err)
+	}
+
+	want := []Transaction{
+		{
+			Date:        time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("-249.5"),
+			Description: "Kafé Åre",
+			Source:      "XLSX Bank",
+			Balance:     decimal.NewNullDecimal(decimal.RequireFromString("750.50")),
+			RawData:     map[string]any{"Sheet": "Transaktioner"},
+		},
+		{
+			Date:        time.Date(2025, 2, 25, 0, 0, 0, 0, time.UTC),
+			Amount:      decimal.RequireFromString("25000"),
+			Description: "Lön",
+			Source:      "XLSX Bank",
+			Balance:     decimal.NewNullDecimal(decimal.RequireFromString("25750.50")),
+			RawData:     map[string]any{"Sheet": "Transaktioner"},
+		},
+	}
+
+	assertTransactions(t, got, want)
+}
+
+func TestXLSXProcessor_error_validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(p *CSVProfile)
+		input   func(t *testing.T) []byte
+		wantErr string
+	}{
+		{
+			name:    "Process_error_not_a_workbook",
+			input:   func(t *testing.T) []byte { return []byte("Datum;Text;Belopp\n") },
+			wantErr: "open_workbook failed",
+		},
+		{
+			name:    "Process_error_missing_sheet",
+			modify:  func(p *CSVProfile) { p.Sheet = "Saknas" },
+			input:   createTestWorkbook,
+			wantErr: `sheet "Saknas" not found`,
+		},
+		{
+			name:    "Process_error_missing_column",
+			modify:  func(p *CSVProfile) { p.Columns.Reference = "Referens" },
+			input:   createTestWorkbook,
+			wantErr: `missing column "Referens"`,
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := createTestXLSXProfile()
+			if tt.modify != nil {
+				tt.modify(&profile)
+			}
+			processor, err := NewXLSXProcessor(profile, logger)
+			if err != nil {
+				t.Fatalf("NewXLSXProcessor() unexpected error: %v", err)
+			}
+
+			_, err = processor.ProcessDocument(context.Background(), bytes.NewReader(tt.input(t)))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}